@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+)
+
+// Accumulator collects errors from processing many items, i.e. rows of a large scan, using bounded memory. It
+// keeps the first errors in full. After that, it only counts errors, grouped by Fingerprint(). Collecting every
+// error, to Join() them at the end, may exhaust memory when there are very many items.
+//
+//	acc := errors.NewAccumulator(10)
+//	for rows.Next() {
+//	  acc.Add(process(rows))
+//	}
+//	return acc.Err()
+//
+// An Accumulator is safe for concurrent use.
+type Accumulator struct {
+	// MaxGroups bounds how many fingerprints are counted. Errors which would add a group beyond this are counted
+	// only in the total.
+	MaxGroups int
+
+	keep int
+
+	mu     sync.Mutex
+	kept   []error
+	total  int
+	groups map[string]*Overflow
+}
+
+// Overflow counts errors with the same fingerprint which were not kept by an Accumulator.
+type Overflow struct {
+	Fingerprint string
+
+	// Message is the message of the first such error.
+	Message string
+
+	Count int
+}
+
+// NewAccumulator produces an Accumulator which keeps the first errors, up to keep.
+func NewAccumulator(keep int) *Accumulator {
+	return &Accumulator{
+		MaxGroups: 1000,
+		keep:      keep,
+		groups:    map[string]*Overflow{},
+	}
+}
+
+// Add records an error. Nil is ignored, so Add may be passed the result of each item.
+func (a *Accumulator) Add(exception error) {
+	if isNil(exception, "Accumulator.Add") {
+		return
+	}
+
+	a.mu.Lock()
+	a.total++
+	if len(a.kept) < a.keep {
+		a.kept = append(a.kept, exception)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+
+	fingerprint := Fingerprint(exception) // relatively expensive, so computed without holding the lock
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	group := a.groups[fingerprint]
+	if group == nil {
+		if len(a.groups) >= a.MaxGroups {
+			return
+		}
+		group = &Overflow{Fingerprint: fingerprint, Message: exception.Error()}
+		a.groups[fingerprint] = group
+	}
+	group.Count++
+}
+
+// Len returns how many errors have been added.
+func (a *Accumulator) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total
+}
+
+// Overflow counts the errors which were added but not kept, by fingerprint, largest count first.
+func (a *Accumulator) Overflow() []Overflow {
+	a.mu.Lock()
+	result := make([]Overflow, 0, len(a.groups))
+	for _, group := range a.groups {
+		result = append(result, *group)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Fingerprint < result[j].Fingerprint
+	})
+	return result
+}
+
+// Err returns nil if no errors were added. If every error was kept, it returns them joined, like Join().
+// Otherwise it returns an error summarizing how many errors there were, which wraps those kept and is annotated
+// with the Overflow counts (see Annotation).
+func (a *Accumulator) Err() error {
+	a.mu.Lock()
+	kept := append([]error(nil), a.kept...)
+	total := a.total
+	a.mu.Unlock()
+
+	if total == 0 {
+		return nil
+	}
+	if total == len(kept) {
+		return Join(kept...)
+	}
+	return Annotate(Errorf("(%d) errors, first (%d) of which are: %w", total, len(kept), Join(kept...)), a.Overflow())
+}
@@ -0,0 +1,65 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rowFailed(row int) error {
+	return errors.Errorf("row (%d) failed", row)
+}
+
+func rowInvalid(row int) error {
+	return errors.Errorf("row (%d) invalid", row)
+}
+
+func TestAccumulator(t *testing.T) {
+	acc := errors.NewAccumulator(2)
+	assert.NoError(t, acc.Err())
+
+	acc.Add(nil)
+	acc.Add(rowFailed(1))
+	assert.Equal(t, "row (1) failed", acc.Err().Error())
+
+	for row := 2; row <= 100; row++ {
+		if row%10 == 0 {
+			acc.Add(rowInvalid(row))
+		} else {
+			acc.Add(rowFailed(row))
+		}
+	}
+	assert.Equal(t, 100, acc.Len())
+
+	overflow := acc.Overflow()
+	if assert.Len(t, overflow, 2) {
+		assert.Equal(t, 88, overflow[0].Count)
+		assert.Equal(t, "row (3) failed", overflow[0].Message)
+		assert.Equal(t, 10, overflow[1].Count)
+		assert.Equal(t, "row (10) invalid", overflow[1].Message)
+	}
+
+	err := acc.Err()
+	assert.Equal(t, "(100) errors, first (2) of which are: row (1) failed\nrow (2) failed", err.Error())
+	summary, ok := errors.Annotation[[]errors.Overflow](err)
+	assert.True(t, ok)
+	assert.Equal(t, overflow, summary)
+
+	limited := errors.NewAccumulator(0)
+	limited.MaxGroups = 1
+	limited.Add(rowFailed(1))
+	limited.Add(rowInvalid(2))
+	assert.Equal(t, 2, limited.Len())
+	assert.Len(t, limited.Overflow(), 1)
+}
+
+func BenchmarkAccumulator(b *testing.B) {
+	acc := errors.NewAccumulator(10)
+	err := rowFailed(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		acc.Add(err)
+	}
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"sync"
+)
+
+// AckState is the state of a captured error, as known by the mechanism which captured it. For example, an
+// incident management service may know that an alert has been acknowledged by whoever is on call.
+type AckState int
+
+const (
+	AckUnknown AckState = iota
+	AckOpen
+	AckAcknowledged
+	AckResolved
+)
+
+func (s AckState) String() string {
+	switch s {
+	case AckOpen:
+		return "open"
+	case AckAcknowledged:
+		return "acknowledged"
+	case AckResolved:
+		return "resolved"
+	default:
+		return "unknown"
+	}
+}
+
+// AckReporter is implemented by capture mechanisms which can report whether a captured error has been
+// acknowledged or resolved.
+type AckReporter interface {
+	// AckState returns the state of a captured error, and false if the capture ID is not known to the reporter.
+	AckState(id CaptureID) (AckState, bool)
+}
+
+// AckReporterFunc allows a func to be used as an AckReporter.
+type AckReporterFunc func(id CaptureID) (AckState, bool)
+
+func (f AckReporterFunc) AckState(id CaptureID) (AckState, bool) { return f(id) }
+
+var (
+	ackMu     sync.RWMutex
+	reporters = map[CaptureProvider]AckReporter{}
+)
+
+// RegisterAckReporter adds a reporter, typically provided along with the capture handler of the same name.
+func RegisterAckReporter(name CaptureProvider, reporter AckReporter) {
+	ackMu.Lock()
+	defer ackMu.Unlock()
+	reporters[name] = reporter
+}
+
+func UnregisterAckReporter(name CaptureProvider) {
+	ackMu.Lock()
+	defer ackMu.Unlock()
+	delete(reporters, name)
+}
+
+// CaptureState returns the state of a captured error, according to registered reporters. It returns AckUnknown
+// when no reporter knows the capture ID.
+//
+// Long-running jobs may use this to stop alerting an error that has already been acknowledged.
+func CaptureState(id CaptureID) AckState {
+	if id == "" {
+		return AckUnknown
+	}
+
+	// reporters may be slow, i.e. query a provider, so invoke them without holding the lock
+	ackMu.RLock()
+	current := make([]AckReporter, 0, len(reporters))
+	for _, reporter := range reporters {
+		current = append(current, reporter)
+	}
+	ackMu.RUnlock()
+
+	result := AckUnknown
+	for _, reporter := range current {
+		if state, ok := reporter.AckState(id); ok && state > result {
+			result = state
+		}
+	}
+	return result
+}
+
+// State returns the most advanced state of any of the captures of an error. For example, if the error was
+// captured by two mechanisms, and acknowledged in one, the state is AckAcknowledged.
+func (e *Captured) State() AckState {
+	result := AckUnknown
+	for _, id := range e.id {
+		if state := CaptureState(id); state > result {
+			result = state
+		}
+	}
+	return result
+}
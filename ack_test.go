@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureState(t *testing.T) {
+	states := map[errors.CaptureID]errors.AckState{}
+	errors.RegisterCapture("TestCaptureState", func(err error, _ ...any) errors.CaptureID {
+		id := errors.CaptureID("TestCaptureState " + err.Error())
+		states[id] = errors.AckOpen
+		return id
+	})
+	defer errors.UnregisterCapture("TestCaptureState")
+	errors.RegisterAckReporter("TestCaptureState", errors.AckReporterFunc(func(id errors.CaptureID) (errors.AckState, bool) {
+		state, ok := states[id]
+		return state, ok
+	}))
+	defer errors.UnregisterAckReporter("TestCaptureState")
+
+	var captured *errors.Captured
+	if !errors.As(errors.Alert(errors.New("first")), &captured) {
+		t.Fatal("not captured")
+	}
+	id := captured.ID("TestCaptureState")
+	assert.Equal(t, errors.AckOpen, errors.CaptureState(id))
+	assert.Equal(t, errors.AckOpen, captured.State())
+
+	states[id] = errors.AckAcknowledged
+	assert.Equal(t, errors.AckAcknowledged, captured.State())
+	assert.Equal(t, "acknowledged", captured.State().String())
+
+	assert.Equal(t, errors.AckUnknown, errors.CaptureState("no such id"))
+
+	// a reporter may register reporters, without deadlock
+	errors.RegisterAckReporter("TestCaptureState again", errors.AckReporterFunc(func(id errors.CaptureID) (errors.AckState, bool) {
+		errors.RegisterAckReporter("TestCaptureState later", errors.AckReporterFunc(func(errors.CaptureID) (errors.AckState, bool) {
+			return errors.AckUnknown, false
+		}))
+		return errors.AckUnknown, false
+	}))
+	defer errors.UnregisterAckReporter("TestCaptureState again")
+	defer errors.UnregisterAckReporter("TestCaptureState later")
+	assert.Equal(t, errors.AckAcknowledged, errors.CaptureState(id))
+}
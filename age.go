@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"time"
+)
+
+// CreatedAt returns when the error was produced by Errorf(), or by a function like Wrap() which calls it. It is
+// zero for errors which were not, i.e. errors decoded from JSON.
+func (e *Error) CreatedAt() time.Time { return e.created }
+
+// CreatedAt returns when the earliest error in an error's chain was produced, see Error.CreatedAt(). It returns
+// false if no error in the chain records when it was produced.
+func CreatedAt(exception error) (time.Time, bool) {
+	var created time.Time
+	Walk(exception, func(ex error) bool {
+		if e, ok := ex.(*Error); ok && !e.created.IsZero() {
+			if created.IsZero() || e.created.Before(created) {
+				created = e.created
+			}
+		}
+		return true
+	})
+	return created, !created.IsZero()
+}
+
+// Age returns how long ago an error was produced, see CreatedAt(). An error which is old when it is alerted, or
+// handled, has likely waited in a queue or a buffer. Age returns zero when it is not known.
+//
+//	errors.RegisterPolicy(errors.Policy{
+//		Name:    "stale",
+//		MinAge:  time.Minute,
+//		Runbook: "https://runbooks.example.com/queue",
+//	})
+func Age(exception error) time.Duration {
+	created, ok := CreatedAt(exception)
+	if !ok {
+		return 0
+	}
+	return time.Since(created)
+}
@@ -0,0 +1,52 @@
+package errors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAge(t *testing.T) {
+	before := time.Now()
+	inner := errors.Errorf("inner")
+	time.Sleep(10 * time.Millisecond)
+	outer := errors.Wrap(inner, "outer")
+
+	created, ok := errors.CreatedAt(outer)
+	assert.True(t, ok)
+	assert.Equal(t, inner.CreatedAt(), created, "earliest error should have priority")
+	assert.False(t, created.Before(before))
+	assert.GreaterOrEqual(t, errors.Age(outer), 10*time.Millisecond)
+
+	_, ok = errors.CreatedAt(errors.String("sentinel"))
+	assert.False(t, ok)
+	assert.Zero(t, errors.Age(errors.String("sentinel")))
+
+	_, ok = errors.CreatedAt(errors.Decode(errors.Encode(outer)))
+	assert.False(t, ok, "decoded errors do not know when they were produced")
+}
+
+func TestPolicyMinAge(t *testing.T) {
+	var captured []errors.Event
+	errors.RegisterCapture("TestPolicyMinAge", func(err error, arg ...any) errors.CaptureID {
+		captured = append(captured, errors.NewEvent(err, arg...))
+		return "TestPolicyMinAge"
+	})
+	defer errors.UnregisterCapture("TestPolicyMinAge")
+
+	errors.RegisterPolicy(errors.Policy{Name: "stale", MinAge: 20 * time.Millisecond, Runbook: "https://runbooks.example.com/queue"})
+	defer errors.UnregisterPolicy("stale")
+
+	queued := errors.Errorf("queued")
+	_ = errors.Alert(errors.Errorf("fresh"))
+	time.Sleep(20 * time.Millisecond)
+	_ = errors.Alert(queued)
+
+	if assert.Len(t, captured, 2) {
+		assert.Empty(t, captured[0].Runbook)
+		assert.Equal(t, errors.Runbook("https://runbooks.example.com/queue"), captured[1].Runbook)
+		assert.GreaterOrEqual(t, captured[1].Age, 20*time.Millisecond)
+	}
+}
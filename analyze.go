@@ -0,0 +1,218 @@
+package errors
+
+import (
+	"time"
+)
+
+// Analysis describes a tree of errors, as found by a single walk of the tree. See Analyze().
+type Analysis struct {
+	// Indexed holds the annotations of the error, so that IndexedAnnotation() and the methods of Indexed apply.
+	*Indexed
+
+	tags      []string
+	keyTags   map[string]string
+	fields    []Fields // outermost first
+	stacks    []StackTrace
+	owners    []error // of stacks
+	sentinels []String
+	layers    []ArgLayer
+	captured  []*Captured
+	created   time.Time
+}
+
+// Analyze walks the tree of wrapped errors once, and finds what functions such as CodeOf(), TagsOf(),
+// Annotations(), WalkStacks() and ArgLayers() would each find by walking it again. Where an error is inspected
+// for several purposes, i.e. at the boundary of a service, or by a capture handler, analyze it once and consult
+// the analysis. NewEvent() does so.
+//
+//	a := errors.Analyze(err)
+//	log.Printf("code=%s kind=%s tags=%v %v", a.Code(), a.Kind(), a.Tags(), a.Annotations())
+//
+// The analysis reflects the error as it was when analyzed. Returns nil when the exception passed in is nil; the
+// methods of a nil analysis return zero values.
+func Analyze(exception error) *Analysis {
+	if isNil(exception, "Analyze") {
+		return nil
+	}
+	a := &Analysis{Indexed: newIndexed(exception)}
+	seenTag := map[string]bool{}
+	depth := 0
+	Walk(exception, func(ex error) bool {
+		a.Indexed.add(ex)
+
+		switch e := ex.(type) {
+		case *annotated:
+			for _, v := range e.value {
+				switch v := v.(type) {
+				case tags:
+					for _, tag := range v {
+						if !seenTag[tag] {
+							seenTag[tag] = true
+							a.tags = append(a.tags, tag)
+						}
+					}
+				case Fields:
+					a.fields = append(a.fields, v)
+				}
+			}
+			a.keyTags = addKeyTags(a.keyTags, e.value)
+		case *Error:
+			for _, v := range e.arg {
+				if fields, ok := v.(Fields); ok {
+					a.fields = append(a.fields, fields)
+				}
+			}
+			if len(e.arg) > 0 {
+				a.layers = append(a.layers, ArgLayer{Depth: depth, Message: ownMessage(e), Arg: e.arg})
+			}
+			depth++
+			if !e.created.IsZero() && (a.created.IsZero() || e.created.Before(a.created)) {
+				a.created = e.created
+			}
+		case *Captured:
+			a.captured = append(a.captured, e)
+		case String:
+			a.sentinels = append(a.sentinels, e)
+		case errorString:
+			a.sentinels = append(a.sentinels, e.s)
+		}
+
+		if tracer, ok := ex.(StackTracer); ok {
+			if stack := tracer.StackTrace(); len(stack) > 0 {
+				a.stacks = append(a.stacks, stack)
+				a.owners = append(a.owners, ex)
+			}
+		}
+		return true
+	})
+	return a
+}
+
+// Tags is like TagsOf().
+func (a *Analysis) Tags() []string {
+	if a == nil {
+		return nil
+	}
+	return a.tags
+}
+
+// TagValues is like TagValuesOf().
+func (a *Analysis) TagValues() map[string]string {
+	if a == nil {
+		return nil
+	}
+	return a.keyTags
+}
+
+// HasTag is like HasTag().
+func (a *Analysis) HasTag(tag string) bool {
+	for _, t := range a.Tags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Annotations is like Annotations().
+func (a *Analysis) Annotations() map[string]any {
+	result := map[string]any{}
+	if a == nil {
+		return result
+	}
+	for _, fields := range a.fields {
+		for key, value := range fields {
+			if _, ok := result[key]; !ok {
+				result[key] = value
+			}
+		}
+	}
+	return result
+}
+
+// Runbook is like RunbookOf().
+func (a *Analysis) Runbook() Runbook {
+	runbook, _ := IndexedAnnotation[Runbook](a.index())
+	return runbook
+}
+
+// Resources is like ResourceTags().
+func (a *Analysis) Resources() map[string]string {
+	return resourceTags(a.index())
+}
+
+// WalkStacks is like WalkStacks().
+func (a *Analysis) WalkStacks(f func(stack StackTrace, owner error) bool) {
+	if a == nil {
+		return
+	}
+	for i := range a.stacks {
+		if !f(a.stacks[i], a.owners[i]) {
+			return
+		}
+	}
+}
+
+// Sentinels returns the String errors in the tree (see String), outermost first, including those which a
+// String.Errorf() error stands for.
+func (a *Analysis) Sentinels() []String {
+	if a == nil {
+		return nil
+	}
+	return a.sentinels
+}
+
+// ArgLayers is like ArgLayers().
+func (a *Analysis) ArgLayers() []ArgLayer {
+	if a == nil {
+		return nil
+	}
+	return a.layers
+}
+
+// CreatedAt is like CreatedAt().
+func (a *Analysis) CreatedAt() (time.Time, bool) {
+	if a == nil {
+		return time.Time{}, false
+	}
+	return a.created, !a.created.IsZero()
+}
+
+// captureIDs returns the identifiers of earlier captures found in the tree, outer captures having priority.
+func (a *Analysis) captureIDs() map[CaptureProvider]CaptureID {
+	var result map[CaptureProvider]CaptureID
+	for _, captured := range a.captured {
+		for provider, id := range captured.id {
+			if result == nil {
+				result = map[CaptureProvider]CaptureID{}
+			}
+			if _, exists := result[provider]; !exists && id != "" {
+				result[provider] = id
+			}
+		}
+	}
+	return result
+}
+
+// index returns the index of the analysis, nil if the analysis is nil.
+func (a *Analysis) index() *Indexed {
+	if a == nil {
+		return nil
+	}
+	return a.Indexed
+}
+
+// Err returns the error which was analyzed, nil if the analysis is nil.
+func (a *Analysis) Err() error { return a.index().Err() }
+
+// Code is like CodeOf().
+func (a *Analysis) Code() Code { return a.index().Code() }
+
+// Kind is like KindOf().
+func (a *Analysis) Kind() Kind { return a.index().Kind() }
+
+// Owner is like OwnerOf().
+func (a *Analysis) Owner() string { return a.index().Owner() }
+
+// Severity is like SeverityOf().
+func (a *Analysis) Severity() Severity { return a.index().Severity() }
@@ -0,0 +1,93 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const errAnalyze = errors.String("TestAnalyze")
+
+func analyzed() error {
+	inner := errors.WithTags(errors.Errorkv("inner", "user", "alice"), "billing")
+	joined := errors.Join(inner, errAnalyze.Errorf("sentinel (%d)", 2), errors.WithTags(errors.New("other"), "billing", "sync"))
+	err := errors.WithCode(errors.Wrapf(joined, "layer (%s)", "a"), "ANL-1")
+	err = errors.AnnotateKV(errors.WithCluster(err, "c-1"), "user", "bob")
+	return errors.WithRunbook(errors.WithCaptureIDs(errors.Wrap(err, "outer"), map[errors.CaptureProvider]errors.CaptureID{"p": "1"}), "https://runbooks.example.com/anl")
+}
+
+func TestAnalyze(t *testing.T) {
+	err := analyzed()
+	a := errors.Analyze(err)
+	assert.Equal(t, err, a.Err())
+	assert.Equal(t, errors.CodeOf(err), a.Code())
+	assert.Equal(t, errors.RunbookOf(err), a.Runbook())
+	assert.Equal(t, errors.TagsOf(err), a.Tags())
+	assert.True(t, a.HasTag("sync"))
+	assert.Equal(t, errors.Annotations(err), a.Annotations())
+	assert.Equal(t, "bob", a.Annotations()["user"])
+	assert.Equal(t, errors.ResourceTags(err), a.Resources())
+	assert.Equal(t, errors.ArgLayers(err), a.ArgLayers())
+	assert.Equal(t, []errors.String{errAnalyze}, a.Sentinels())
+
+	var stacks, analyzedStacks []errors.StackTrace
+	errors.WalkStacks(err, func(stack errors.StackTrace, _ error) bool {
+		stacks = append(stacks, stack)
+		return true
+	})
+	a.WalkStacks(func(stack errors.StackTrace, _ error) bool {
+		analyzedStacks = append(analyzedStacks, stack)
+		return true
+	})
+	assert.Equal(t, stacks, analyzedStacks)
+
+	created, _ := errors.CreatedAt(err)
+	analyzedCreated, _ := a.CreatedAt()
+	assert.Equal(t, created, analyzedCreated)
+
+	var nothing *errors.Analysis
+	assert.Empty(t, nothing.Tags())
+	assert.Empty(t, nothing.Annotations())
+	assert.Nil(t, nothing.Err())
+	assert.Empty(t, nothing.Code())
+	assert.Empty(t, nothing.Kind())
+	assert.Empty(t, nothing.Owner())
+	assert.Equal(t, errors.SeverityError, nothing.Severity())
+	assert.Nil(t, errors.Analyze(nil))
+	assert.Empty(t, errors.Analyze(nil).Code())
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	err := analyzed()
+	for i := 0; i < 20; i++ {
+		err = errors.AnnotateKV(errors.Wrap(err, "layer"), "depth", i)
+	}
+	b.Run("Separate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = errors.CodeOf(err)
+			_ = errors.KindOf(err)
+			_ = errors.SeverityOf(err)
+			_ = errors.OwnerOf(err)
+			_ = errors.RunbookOf(err)
+			_ = errors.TagsOf(err)
+			_ = errors.ResourceTags(err)
+			_ = errors.Annotations(err)
+			_ = errors.ArgLayers(err)
+		}
+	})
+	b.Run("Analyze", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			a := errors.Analyze(err)
+			_ = a.Code()
+			_ = a.Kind()
+			_ = a.Severity()
+			_ = a.Owner()
+			_ = a.Runbook()
+			_ = a.Tags()
+			_ = a.Resources()
+			_ = a.Annotations()
+			_ = a.ArgLayers()
+		}
+	})
+}
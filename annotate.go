@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// annotated wraps an error with values that describe it. The values do not change the error message; they are
+// metadata which capture handlers and other code may look up by type, see Annotation().
+type annotated struct {
+	// error is the wrapped error
+	error
+
+	// value is a list of arbitrary annotations
+	value []any
+}
+
+// Unwrap allows errors.Unwrap to return the parent error.
+func (e *annotated) Unwrap() error { return e.error }
+
+// Format defers to the wrapped error, as annotations do not change the error message. Verbose output ("%+v") is
+// written by writeVerbose(), which includes details from some annotations.
+func (e *annotated) Format(f fmt.State, c rune) {
+	if c == 'v' && verbose(f) {
+		writeVerbose(f, e.Error(), e)
+		return
+	}
+	_, _ = fmt.Fprintf(f, fmt.FormatString(f, c), e.error)
+}
+
+// Annotate returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and carries the values passed in. The values are not part of the error message. Use Annotation() to find them.
+//
+//	type UserID string
+//
+//	err = errors.Annotate(err, UserID(id))
+//	...
+//	if id, ok := errors.Annotation[UserID](err); ok { ... }
+func Annotate(exception error, value ...any) error {
+	if isNil(exception, "Annotate") {
+		return nil
+	}
+	if len(value) == 0 {
+		return exception
+	}
+	return &annotated{
+		error: exception,
+		value: value,
+	}
+}
+
+// Annotation finds the first value of type T annotating an error. It walks the tree of wrapped errors, so the
+// outermost annotation has priority over annotations on wrapped errors. Values bound to a sentinel (see
+// BindSentinel) are found as though the sentinel were annotated with them.
+func Annotation[T any](exception error) (T, bool) {
+	var (
+		result T
+		found  bool
+	)
+	Walk(exception, func(ex error) bool {
+		var value []any
+		if a, ok := ex.(*annotated); ok {
+			value = a.value
+		} else {
+			value = boundValues(ex)
+		}
+		for _, v := range value {
+			if result, found = v.(T); found {
+				return false
+			}
+		}
+		return true
+	})
+	return result, found
+}
+
+// AnnotateKV returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and carries a named value. Unlike Annotate(), values are found by name rather than type, so an error may carry
+// several values of the same type. Use Value() or Annotations() to find them.
+//
+//	err = errors.AnnotateKV(err, "user_id", id)
+//	err = errors.AnnotateKV(err, "cluster", name)
+func AnnotateKV(exception error, key string, value any) error {
+	return Annotate(exception, Fields{key: value})
+}
+
+// Value finds a value named by AnnotateKV(), or by Errorkv(). The outermost value has priority over values of the
+// same name on wrapped errors.
+func Value(exception error, key string) (any, bool) {
+	var (
+		result any
+		found  bool
+	)
+	walkFields(exception, func(fields Fields) bool {
+		result, found = fields[key]
+		return !found
+	})
+	return result, found
+}
+
+// Annotations returns the values named by AnnotateKV(), or by Errorkv(), throughout the tree of wrapped errors.
+// Where a name appears more than once, the outermost value has priority. Capture handlers may use it to send
+// structured fields to providers.
+func Annotations(exception error) map[string]any {
+	result := map[string]any{}
+	walkFields(exception, func(fields Fields) bool {
+		for key, value := range fields {
+			if _, ok := result[key]; !ok {
+				result[key] = value
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// walkFields visits the named values of each error in a tree, outermost first. The walk continues while f returns
+// true.
+func walkFields(exception error, f func(Fields) bool) {
+	Walk(exception, func(ex error) bool {
+		var value []any
+		switch e := ex.(type) {
+		case *annotated:
+			value = e.value
+		case *Error:
+			value = e.arg
+		}
+		for _, v := range value {
+			if fields, ok := v.(Fields); ok && !f(fields) {
+				return false
+			}
+		}
+		return true
+	})
+}
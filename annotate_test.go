@@ -0,0 +1,67 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type userID int
+
+func TestAnnotation(t *testing.T) {
+	assert.NoError(t, errors.Annotate(nil, userID(1)))
+
+	inner := errors.Annotate(errors.New("inner"), userID(1), "inner string")
+	outer := errors.Annotate(errors.Wrap(inner, "outer"), userID(2))
+	assert.Equal(t, "outer: inner", outer.Error())
+
+	id, ok := errors.Annotation[userID](outer)
+	assert.True(t, ok)
+	assert.Equal(t, userID(2), id, "outermost annotation should have priority")
+
+	s, ok := errors.Annotation[string](outer)
+	assert.True(t, ok)
+	assert.Equal(t, "inner string", s)
+
+	_, ok = errors.Annotation[float64](outer)
+	assert.False(t, ok)
+
+	joined := errors.Join(errors.New("first"), inner)
+	id, ok = errors.Annotation[userID](joined)
+	assert.True(t, ok)
+	assert.Equal(t, userID(1), id)
+
+	// annotations do not alter formatting
+	assert.Equal(t, fmt.Sprintf("%+v", inner), fmt.Sprintf("%+v", errors.Annotate(inner, userID(3))))
+}
+
+func TestCodeAndOwner(t *testing.T) {
+	err := errors.WithOwner(errors.WithCode(errors.New("not found"), "NF-001"), "team-storage")
+	assert.Equal(t, errors.Code("NF-001"), errors.CodeOf(err))
+	assert.Equal(t, "team-storage", errors.OwnerOf(err))
+	assert.Equal(t, errors.Code(""), errors.CodeOf(errors.New("no code")))
+	assert.Equal(t, "", errors.OwnerOf(errors.New("no owner")))
+}
+
+func TestAnnotateKV(t *testing.T) {
+	assert.NoError(t, errors.AnnotateKV(nil, "user_id", 1))
+
+	inner := errors.AnnotateKV(errors.Errorkv("inner", "region", "us-east-1"), "cluster", "inner")
+	outer := errors.AnnotateKV(errors.AnnotateKV(errors.Wrap(inner, "outer"), "user_id", "42"), "cluster", "outer")
+
+	cluster, ok := errors.Value(outer, "cluster")
+	assert.True(t, ok)
+	assert.Equal(t, "outer", cluster, "outermost value should have priority")
+
+	region, ok := errors.Value(outer, "region")
+	assert.True(t, ok)
+	assert.Equal(t, "us-east-1", region)
+
+	_, ok = errors.Value(outer, "missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]any{"cluster": "outer", "user_id": "42", "region": "us-east-1"}, errors.Annotations(outer))
+	assert.Empty(t, errors.Annotations(errors.New("TestAnnotateKV")))
+}
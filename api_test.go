@@ -0,0 +1,116 @@
+package errors_test
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateAPI = flag.Bool("update", false, "update testdata/api.txt with the current API")
+
+// TestAPI guards the exported API of the package, so that an identifier is not exported, nor its signature
+// changed, by accident. When a change is intended, update the golden file:
+//
+//	go test -run TestAPI -update .
+func TestAPI(t *testing.T) {
+	api := exportedAPI(t)
+	if *updateAPI {
+		assert.NoError(t, os.WriteFile("testdata/api.txt", []byte(api), 0o644))
+		return
+	}
+	golden, err := os.ReadFile("testdata/api.txt")
+	assert.NoError(t, err)
+
+	// report only what changed, rather than the whole API
+	want, have := map[string]bool{}, map[string]bool{}
+	for _, line := range strings.Split(string(golden), "\n") {
+		want[line] = true
+	}
+	for _, line := range strings.Split(api, "\n") {
+		have[line] = true
+		if !want[line] {
+			t.Errorf("exported API added, see TestAPI: %s", line)
+		}
+	}
+	for line := range want {
+		if !have[line] {
+			t.Errorf("exported API removed, see TestAPI: %s", line)
+		}
+	}
+}
+
+// exportedAPI describes each exported declaration of the package, one per line, sorted. Files with build
+// constraints are included, whatever the version of Go, so the description does not depend on who runs the test.
+func exportedAPI(t *testing.T) string {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if !assert.NoError(t, err) || !assert.Contains(t, pkgs, "errors") {
+		return ""
+	}
+	files := make([]*ast.File, 0, len(pkgs["errors"].Files))
+	for _, file := range pkgs["errors"].Files {
+		files = append(files, file)
+	}
+	pkg, err := doc.NewFromFiles(fset, files, "github.com/memsql/errors", doc.PreserveAST)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+
+	lines := map[string]bool{}
+	node := func(n any) string {
+		var b bytes.Buffer
+		assert.NoError(t, (&printer.Config{Mode: printer.RawFormat}).Fprint(&b, fset, n))
+		return strings.Join(strings.Fields(b.String()), " ")
+	}
+	values := func(values []*doc.Value) {
+		for _, v := range values {
+			for _, name := range v.Names {
+				if ast.IsExported(name) {
+					lines[v.Decl.Tok.String()+" "+name] = true
+				}
+			}
+		}
+	}
+	funcs := func(funcs []*doc.Func) {
+		for _, f := range funcs {
+			f.Decl.Doc, f.Decl.Body = nil, nil
+			lines[node(f.Decl)] = true
+		}
+	}
+
+	values(pkg.Consts)
+	values(pkg.Vars)
+	funcs(pkg.Funcs)
+	for _, typ := range pkg.Types {
+		for _, spec := range typ.Decl.Specs {
+			spec := spec.(*ast.TypeSpec)
+			if spec.Name.Name == typ.Name {
+				spec.Doc, spec.Comment = nil, nil
+				lines["type "+node(spec)] = true
+			}
+		}
+		values(typ.Consts)
+		values(typ.Vars)
+		funcs(typ.Funcs)
+		funcs(typ.Methods)
+	}
+
+	sorted := make([]string, 0, len(lines))
+	for line := range lines {
+		sorted = append(sorted, line)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, "\n") + "\n"
+}
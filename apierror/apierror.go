@@ -0,0 +1,207 @@
+// Package apierror renders errors as a versioned JSON envelope, the one shape of error response that web
+// frontends need to understand, whichever API produced it.
+//
+//	{
+//	  "version": 1,
+//	  "code": "W-1",
+//	  "message": "widget not found",
+//	  "details": [{"code": "V-2", "message": "name is too long", "field": "name"}],
+//	  "captureId": "b6f0e1c2",
+//	  "retryable": true,
+//	  "retryAfter": 1.5
+//	}
+//
+// Like package problem, the error is redacted (see errors.Redact), so that the envelope is safe to send to an
+// unprivileged client. Fields are only added to a version of the envelope; a change which would break clients
+// of a version produces a new version.
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memsql/errors"
+)
+
+// Version is the version of the envelope produced by this package.
+const Version = 1
+
+// ContentType is the media type of an Envelope.
+const ContentType = "application/json"
+
+// ErrInvalid is wrapped by the errors of Validate() and Parse().
+const ErrInvalid = errors.String("invalid error envelope")
+
+// Envelope describes an error to a client.
+type Envelope struct {
+	// Version is the version of the schema, see Version.
+	Version int
+
+	// Code is the code of the error, see errors.CodeOf.
+	Code errors.Code
+
+	// Message is the redacted message of the error, see errors.Public.Summary.
+	Message string
+
+	// Details describe the errors joined by the error, if any, i.e. one per invalid field of a request.
+	Details []Detail
+
+	// CaptureID identifies where the error was captured, if it was alerted.
+	CaptureID string
+
+	// Retryable and RetryAfter tell the client whether, and when, it may retry, see errors.IsRetryable and
+	// errors.RetryAfter.
+	Retryable  bool
+	RetryAfter time.Duration
+}
+
+// Detail describes one of the errors joined by an error.
+type Detail struct {
+	Code    errors.Code `json:"code,omitempty"`
+	Message string      `json:"message"`
+
+	// Field is the input the error concerns, if known, see errors.FieldPath and errors.ConfigError.
+	Field string `json:"field,omitempty"`
+}
+
+// wireEnvelope is the JSON encoding of Envelope. RetryAfter is in seconds, as in the Retry-After header.
+type wireEnvelope struct {
+	Version    int         `json:"version"`
+	Code       errors.Code `json:"code,omitempty"`
+	Message    string      `json:"message"`
+	Details    []Detail    `json:"details,omitempty"`
+	CaptureID  string      `json:"captureId,omitempty"`
+	Retryable  bool        `json:"retryable"`
+	RetryAfter float64     `json:"retryAfter,omitempty"`
+}
+
+// New describes an error as an envelope of the current Version.
+func New(err error) Envelope {
+	e := Envelope{
+		Version:   Version,
+		Code:      errors.CodeOf(err),
+		Message:   errors.Redact(err).Summary(),
+		Retryable: errors.IsRetryable(err),
+	}
+	e.RetryAfter, _ = errors.RetryAfter(err)
+
+	var captured *errors.Captured
+	if errors.As(err, &captured) {
+		var id []string
+		for _, i := range captured.IDs() {
+			if i != "" {
+				id = append(id, string(i))
+			}
+		}
+		sort.Strings(id)
+		e.CaptureID = strings.Join(id, ", ")
+	}
+
+	errors.Walk(err, func(ex error) bool {
+		joined, ok := ex.(interface{ Unwrap() []error })
+		if !ok {
+			return true
+		}
+		for _, branch := range joined.Unwrap() {
+			if branch != nil {
+				e.Details = append(e.Details, Detail{
+					Code:    errors.CodeOf(branch),
+					Message: errors.Redact(branch).Summary(),
+					Field:   field(branch),
+				})
+			}
+		}
+		return false
+	})
+	return e
+}
+
+// field returns the input an error concerns, if known.
+func field(err error) string {
+	if path, ok := errors.Annotation[errors.FieldPath](err); ok {
+		return path.String()
+	}
+	if config, ok := errors.Annotation[errors.ConfigError](err); ok {
+		return config.Key
+	}
+	return ""
+}
+
+// Validate returns an error wrapping ErrInvalid, if the envelope does not conform to the schema of its version.
+func (e Envelope) Validate() error {
+	switch {
+	case e.Version < 1 || e.Version > Version:
+		return errors.Errorf("version (%d) not supported: %w", e.Version, ErrInvalid)
+	case e.Message == "":
+		return errors.Errorf("message is empty: %w", ErrInvalid)
+	case e.RetryAfter < 0:
+		return errors.Errorf("retryAfter (%s) is negative: %w", e.RetryAfter, ErrInvalid)
+	case e.RetryAfter > 0 && !e.Retryable:
+		return errors.Errorf("retryAfter (%s) of error which is not retryable: %w", e.RetryAfter, ErrInvalid)
+	}
+	for i, d := range e.Details {
+		if d.Message == "" {
+			return errors.Errorf("message of details[%d] is empty: %w", i, ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the envelope, with RetryAfter in seconds.
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	return json.Marshal(wireEnvelope{
+		Version:    e.Version,
+		Code:       e.Code,
+		Message:    e.Message,
+		Details:    e.Details,
+		CaptureID:  e.CaptureID,
+		Retryable:  e.Retryable,
+		RetryAfter: e.RetryAfter.Seconds(),
+	})
+}
+
+// UnmarshalJSON decodes an envelope encoded by MarshalJSON. It does not validate it, see Parse.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	var wire wireEnvelope
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*e = Envelope{
+		Version:    wire.Version,
+		Code:       wire.Code,
+		Message:    wire.Message,
+		Details:    wire.Details,
+		CaptureID:  wire.CaptureID,
+		Retryable:  wire.Retryable,
+		RetryAfter: time.Duration(wire.RetryAfter * float64(time.Second)),
+	}
+	return nil
+}
+
+// Parse decodes and validates an envelope, i.e. one received by a client of an API.
+func Parse(data []byte) (Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Envelope{}, errors.Errorf("failed to decode error envelope (%w): %w", err, ErrInvalid)
+	}
+	if err := e.Validate(); err != nil {
+		return Envelope{}, err
+	}
+	return e, nil
+}
+
+// Write responds with the envelope describing an error. The status of the response is errors.HTTPStatus(err), and
+// the Retry-After header is set when the error may be retried after a delay.
+func Write(w http.ResponseWriter, err error) {
+	e := New(err)
+	w.Header().Set("Content-Type", ContentType)
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((e.RetryAfter+time.Second-1)/time.Second)))
+	}
+	w.WriteHeader(errors.HTTPStatus(err))
+	_ = json.NewEncoder(w).Encode(e) // if this fails, the client has gone away
+}
@@ -0,0 +1,69 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	errors.RegisterCapture("TestWrite", func(error, ...any) errors.CaptureID { return "TestWrite 1" })
+	defer errors.UnregisterCapture("TestWrite")
+
+	invalid := errors.Join(
+		errors.WithCode(errors.InvalidConfig("name", "a very long name", "at most 8 characters"), "V-2"),
+		errors.New("quota (secret) exceeded"),
+	)
+	err := errors.WithRetryAfter(errors.MarkRetryable(errors.WithHTTPStatus(
+		errors.WithCode(errors.Errorf("widget (%s) rejected: %w", "secret", invalid), "W-1"), http.StatusTooManyRequests)),
+		1500*time.Millisecond)
+	recorder := httptest.NewRecorder()
+	apierror.Write(recorder, errors.Alert(err))
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, apierror.ContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, float64(1), body["version"])
+	assert.Equal(t, "W-1", body["code"])
+	assert.Equal(t, "widget rejected", body["message"])
+	assert.Equal(t, "TestWrite 1", body["captureId"])
+	assert.Equal(t, true, body["retryable"])
+	assert.Equal(t, 1.5, body["retryAfter"])
+	assert.Equal(t, []any{
+		map[string]any{"code": "V-2", "message": "invalid configuration at name", "field": "name"},
+		map[string]any{"message": "quota exceeded"},
+	}, body["details"])
+
+	envelope, parseErr := apierror.Parse(recorder.Body.Bytes())
+	assert.NoError(t, parseErr)
+	assert.Equal(t, apierror.New(err).Details, envelope.Details)
+	assert.Equal(t, 1500*time.Millisecond, envelope.RetryAfter)
+}
+
+func TestValidate(t *testing.T) {
+	valid := apierror.Envelope{Version: apierror.Version, Message: "widget not found"}
+	assert.NoError(t, valid.Validate())
+
+	for name, e := range map[string]apierror.Envelope{
+		"version":     {Version: apierror.Version + 1, Message: "widget not found"},
+		"message":     {Version: apierror.Version},
+		"retry after": {Version: apierror.Version, Message: "widget not found", RetryAfter: time.Second},
+		"details":     {Version: apierror.Version, Message: "widget not found", Details: []apierror.Detail{{Code: "V-2"}}},
+	} {
+		assert.ErrorIs(t, e.Validate(), apierror.ErrInvalid, name)
+	}
+
+	_, err := apierror.Parse([]byte(`{"version": 1}`))
+	assert.ErrorIs(t, err, apierror.ErrInvalid)
+	_, err = apierror.Parse([]byte(`[]`))
+	assert.ErrorIs(t, err, apierror.ErrInvalid)
+}
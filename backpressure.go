@@ -0,0 +1,319 @@
+package errors
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCaptureSaturated is the cause of capture handlers not being invoked, or not being waited for, when more
+// alerts are in flight than permitted by SetCaptureLimit().
+const ErrCaptureSaturated = String("capture saturated")
+
+// Backpressure determines what happens to an alert when the capture subsystem is saturated.
+type Backpressure int
+
+const (
+	// BlockWithTimeout makes an alert wait for another to finish, up to CaptureLimit.Timeout. If the wait times
+	// out, the new alert is dropped. This favors alert delivery over the latency of the caller.
+	BlockWithTimeout Backpressure = iota
+
+	// DropNewest drops the new alert. This favors latency, and alerts which are already in flight.
+	DropNewest
+
+	// DropOldest stops waiting for the oldest alert in flight, so that the new alert can proceed. Handlers of the
+	// oldest alert continue to run, but are no longer counted.
+	DropOldest
+
+	// Prioritize orders alerts by severity (see SeverityOf), then by novelty: an alert of a fingerprint seen fewer
+	// times before has priority over a repeated one. A new alert which has priority over an alert in flight takes
+	// the place of the lowest alert in flight, as DropOldest does. Otherwise it waits, up to CaptureLimit.Timeout,
+	// and waiting alerts proceed highest priority first. So when capture is saturated, the most important and novel
+	// errors are delivered, and chatty, repeated errors are shed.
+	Prioritize
+)
+
+func (b Backpressure) String() string {
+	switch b {
+	case BlockWithTimeout:
+		return "block with timeout"
+	case DropNewest:
+		return "drop newest"
+	case DropOldest:
+		return "drop oldest"
+	case Prioritize:
+		return "prioritize"
+	default:
+		return "unknown"
+	}
+}
+
+// CaptureLimit bounds the number of alerts in flight. An alert is in flight until all of its capture handlers
+// have returned, which may be after Alert() has returned, when handlers are slow.
+type CaptureLimit struct {
+	// Max alerts in flight. Zero means no limit.
+	Max int
+
+	// Policy applies when Max alerts are in flight.
+	Policy Backpressure
+
+	// Timeout limits how long an alert waits, when Policy is BlockWithTimeout or Prioritize.
+	Timeout time.Duration
+
+	// Saturated, if not nil, is invoked each time an alert is dropped, including when the queue of capture
+	// handlers is full, whatever the limit. It must not block, or alert.
+	Saturated func(Saturation)
+}
+
+// Saturation describes an alert dropped because the capture subsystem is saturated.
+type Saturation struct {
+	Policy   Backpressure
+	InFlight int
+
+	// Dropped is the error which was not captured, or not fully captured.
+	Dropped error
+}
+
+// SetCaptureLimit determines how many alerts may be in flight, and what happens when more are alerted. By
+// default there is no limit. Under extreme error volume, slow capture handlers may then fill the queue of handlers,
+// which drops alerts regardless of policy, and Alert() adds up to CaptureTimeout of latency to every caller.
+func SetCaptureLimit(limit CaptureLimit) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.limit = limit
+}
+
+// CapturesInFlight returns the number of alerts in flight, that is alerts with capture handlers which have not
+// returned. It may be used by health checks, to detect capture handlers which are slow or deadlocked.
+func CapturesInFlight() int {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return len(limiter.inFlight)
+}
+
+// limiter tracks alerts in flight.
+var limiter = &flight{changed: make(chan struct{})}
+
+type flight struct {
+	mu       sync.Mutex
+	limit    CaptureLimit
+	inFlight []*slot // oldest first
+	waiting  []*slot // alerts waiting for a slot when prioritizing, oldest first
+
+	// seen counts alerts by fingerprint, when prioritizing
+	seen map[string]int
+
+	// changed is closed, and replaced, whenever a slot is released
+	changed chan struct{}
+}
+
+// maxSeen limits the fingerprints counted for prioritizing. When there are more, counting starts over.
+const maxSeen = 10_000
+
+// slot is held by an alert in flight.
+type slot struct {
+	exception error
+	cancel    context.CancelCauseFunc
+	priority  priority
+}
+
+// priority orders alerts, see Prioritize.
+type priority struct {
+	severity Severity
+	seen     int // alerts of the same fingerprint before this one
+}
+
+// above returns whether p has priority over q.
+func (p priority) above(q priority) bool {
+	if p.severity != q.severity {
+		return p.severity > q.severity
+	}
+	return p.seen < q.seen
+}
+
+// acquire returns a slot for an alert, or nil if the alert is dropped. The alert must wait for its handlers using
+// the context returned, as it is canceled if the alert is dropped after acquiring the slot.
+func (l *flight) acquire(ctx context.Context, exception error) (*slot, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	s := &slot{exception: exception, cancel: cancel}
+
+	// computing the fingerprint may be slow, so do it without holding the lock
+	l.mu.Lock()
+	prioritize := l.limit.Policy == Prioritize
+	l.mu.Unlock()
+	var fingerprint string
+	var severity Severity
+	if prioritize {
+		fingerprint, severity = Fingerprint(exception), SeverityOf(exception)
+	}
+
+	var deadline <-chan time.Time
+	l.mu.Lock()
+	if prioritize {
+		s.priority = l.rank(fingerprint, severity)
+	}
+	for {
+		limit := l.limit
+		if limit.Max <= 0 || len(l.inFlight) < limit.Max && !l.waiterAbove(s) {
+			l.inFlight = append(l.inFlight, s)
+			l.stopWaiting(s)
+			l.mu.Unlock()
+			return s, ctx
+		}
+
+		switch limit.Policy {
+		case Prioritize:
+			// displace the lowest alert in flight, unless a slot is free but another alert precedes this one
+			if len(l.inFlight) >= limit.Max && !l.waiterAbove(s) {
+				if i := l.lowest(); s.priority.above(l.inFlight[i].priority) {
+					dropped := l.inFlight[i]
+					l.inFlight = append(append(l.inFlight[:i:i], l.inFlight[i+1:]...), s)
+					l.stopWaiting(s)
+					l.mu.Unlock()
+					dropped.cancel(ErrCaptureSaturated)
+					l.saturated(limit, dropped.exception)
+					return s, ctx
+				}
+			}
+			if deadline == nil {
+				timer := time.NewTimer(limit.Timeout)
+				defer timer.Stop()
+				deadline = timer.C
+				l.waiting = append(l.waiting, s)
+			}
+			changed := l.changed
+			l.mu.Unlock()
+			select {
+			case <-changed:
+				l.mu.Lock()
+				continue // try again
+			case <-deadline:
+			}
+			l.mu.Lock()
+			l.stopWaiting(s)
+			l.mu.Unlock()
+
+		case DropOldest:
+			oldest := l.inFlight[0]
+			l.inFlight = append(l.inFlight[1:], s)
+			l.mu.Unlock()
+			oldest.cancel(ErrCaptureSaturated)
+			l.saturated(limit, oldest.exception)
+			return s, ctx
+
+		case BlockWithTimeout:
+			if deadline == nil {
+				timer := time.NewTimer(limit.Timeout)
+				defer timer.Stop()
+				deadline = timer.C
+			}
+			changed := l.changed
+			l.mu.Unlock()
+			select {
+			case <-changed:
+				l.mu.Lock()
+				continue // try again
+			case <-deadline:
+			}
+
+		default: // DropNewest
+			l.mu.Unlock()
+		}
+
+		cancel(ErrCaptureSaturated)
+		l.saturated(limit, exception)
+		return nil, ctx
+	}
+}
+
+// rank returns the priority of a new alert, and counts its fingerprint. Caller must hold the lock.
+func (l *flight) rank(fingerprint string, severity Severity) priority {
+	if l.seen == nil || len(l.seen) >= maxSeen {
+		l.seen = map[string]int{}
+	}
+	p := priority{severity: severity, seen: l.seen[fingerprint]}
+	l.seen[fingerprint]++
+	return p
+}
+
+// lowest returns the index of the alert in flight with the lowest priority, the newest of those with the same
+// priority. Caller must hold the lock, and there must be alerts in flight.
+func (l *flight) lowest() int {
+	lowest := 0
+	for i := 1; i < len(l.inFlight); i++ {
+		if !l.inFlight[i].priority.above(l.inFlight[lowest].priority) {
+			lowest = i
+		}
+	}
+	return lowest
+}
+
+// waiterAbove returns whether another alert waiting for a slot precedes s, as it has higher priority, or the
+// same priority and has waited longer. Caller must hold the lock.
+func (l *flight) waiterAbove(s *slot) bool {
+	waiting := false // whether s is waiting; alerts after it precede it only if they have higher priority
+	for _, w := range l.waiting {
+		switch {
+		case w == s:
+			waiting = true
+		case !waiting && !s.priority.above(w.priority):
+			return true
+		case waiting && w.priority.above(s.priority):
+			return true
+		}
+	}
+	return false
+}
+
+// stopWaiting removes s from the alerts waiting for a slot, if it is waiting, and wakes the others, which may
+// now be first. Caller must hold the lock.
+func (l *flight) stopWaiting(s *slot) {
+	for i := range l.waiting {
+		if l.waiting[i] == s {
+			l.waiting = append(l.waiting[:i:i], l.waiting[i+1:]...)
+			l.broadcast()
+			return
+		}
+	}
+}
+
+// broadcast wakes alerts waiting for a slot. Caller must hold the lock.
+func (l *flight) broadcast() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// release frees a slot, when all handlers of the alert which held it have returned.
+func (l *flight) release(s *slot) {
+	s.cancel(nil)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.inFlight {
+		if l.inFlight[i] == s {
+			l.inFlight = append(l.inFlight[:i:i], l.inFlight[i+1:]...)
+			l.broadcast()
+			return
+		}
+	}
+}
+
+// queueFull reports an alert with handlers which were not invoked, because the queue of capture handlers is full.
+func (l *flight) queueFull(dropped error) {
+	l.mu.Lock()
+	limit := l.limit
+	l.mu.Unlock()
+	l.saturated(limit, dropped)
+}
+
+func (l *flight) saturated(limit CaptureLimit, dropped error) {
+	l.mu.Lock()
+	inFlight := len(l.inFlight)
+	l.mu.Unlock()
+
+	log.Printf("capture saturated, %s (%d in flight): %s", limit.Policy, inFlight, dropped)
+	if limit.Saturated != nil {
+		limit.Saturated(Saturation{Policy: limit.Policy, InFlight: inFlight, Dropped: dropped})
+	}
+}
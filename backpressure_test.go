@@ -0,0 +1,184 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureLimit(t *testing.T) {
+	unblock := make(chan struct{})
+	errors.RegisterCapture("TestCaptureLimit", func(err error, _ ...any) errors.CaptureID {
+		if err.Error() == "blocked" {
+			<-unblock
+		}
+		return "TestCaptureLimit"
+	})
+	defer errors.UnregisterCapture("TestCaptureLimit")
+
+	var saturations []errors.Saturation
+	limit := errors.CaptureLimit{
+		Max:       1,
+		Saturated: func(s errors.Saturation) { saturations = append(saturations, s) },
+	}
+	defer errors.SetCaptureLimit(errors.CaptureLimit{})
+
+	// settle waits for alerts in flight, including alerts of other tests, to finish
+	settle := func() {
+		for i := 0; errors.CapturesInFlight() > 0; i++ {
+			if i > 100 {
+				t.Fatalf("alerts (%d) still in flight", errors.CapturesInFlight())
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// release unblocks the handler, and waits for it to return
+	release := func() {
+		select {
+		case unblock <- struct{}{}:
+		case <-time.After(time.Second):
+			t.Error("handler is not blocked")
+		}
+		settle()
+	}
+
+	// alertBlocked leaves an alert in flight, until unblocked
+	alertBlocked := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, failed := errors.AlertSync(ctx, errors.String("blocked"))
+		assert.Len(t, failed, 1)
+	}
+
+	t.Run("drop newest", func(t *testing.T) {
+		saturations = nil
+		limit.Policy = errors.DropNewest
+		errors.SetCaptureLimit(limit)
+		settle()
+		alertBlocked()
+
+		assert.Equal(t, 1, errors.CapturesInFlight())
+
+		_, failed := errors.AlertSync(context.Background(), errors.String("dropped"))
+		assert.ErrorIs(t, failed["TestCaptureLimit"], errors.ErrCaptureSaturated)
+		if assert.Len(t, saturations, 1) {
+			assert.Equal(t, "dropped", saturations[0].Dropped.Error())
+			assert.Equal(t, 1, saturations[0].InFlight)
+		}
+		release()
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		saturations = nil
+		limit.Policy = errors.DropOldest
+		errors.SetCaptureLimit(limit)
+		alertBlocked()
+
+		_, failed := errors.AlertSync(context.Background(), errors.String("kept"))
+		assert.Empty(t, failed)
+		if assert.Len(t, saturations, 1) {
+			assert.Equal(t, "blocked", saturations[0].Dropped.Error())
+		}
+		release()
+	})
+
+	t.Run("block with timeout", func(t *testing.T) {
+		saturations = nil
+		limit.Policy = errors.BlockWithTimeout
+		limit.Timeout = time.Second
+		errors.SetCaptureLimit(limit)
+		alertBlocked()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			unblock <- struct{}{}
+		}()
+		_, failed := errors.AlertSync(context.Background(), errors.String("waited"))
+		assert.Empty(t, failed)
+		assert.Empty(t, saturations)
+		settle()
+
+		limit.Timeout = 10 * time.Millisecond
+		errors.SetCaptureLimit(limit)
+		alertBlocked()
+		_, failed = errors.AlertSync(context.Background(), errors.String("timed out"))
+		assert.ErrorIs(t, failed["TestCaptureLimit"], errors.ErrCaptureSaturated)
+		assert.Len(t, saturations, 1)
+		release()
+	})
+
+	t.Run("prioritize", func(t *testing.T) {
+		saturations = nil
+		limit.Policy = errors.Prioritize
+		limit.Timeout = 10 * time.Millisecond
+		errors.SetCaptureLimit(limit)
+
+		// alerts of the same fingerprint, which remain in flight until unblocked
+		repeated := func() map[errors.CaptureProvider]error {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, failed := errors.AlertSync(ctx, errors.String("blocked"))
+			return failed
+		}
+		repeated()
+		release()
+
+		// a repeated alert does not displace the alert in flight, while a novel one does
+		repeated()
+		failed := repeated()
+		if assert.ErrorIs(t, failed["TestCaptureLimit"], errors.ErrCaptureSaturated) {
+			assert.Contains(t, failed["TestCaptureLimit"].Error(), "not invoked")
+		}
+		_, failed = errors.AlertSync(context.Background(), errors.String(fmt.Sprint("novel ", time.Now().UnixNano())))
+		assert.Empty(t, failed)
+		if assert.Len(t, saturations, 2) {
+			assert.Equal(t, "blocked", saturations[0].Dropped.Error())
+			assert.Equal(t, "blocked", saturations[1].Dropped.Error())
+		}
+		release()
+
+		// waiting alerts proceed highest priority first
+		var (
+			mu    sync.Mutex
+			order []string
+		)
+		errors.RegisterCapture("TestCaptureLimit order", func(err error, _ ...any) errors.CaptureID {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, err.Error())
+			return ""
+		})
+		defer errors.UnregisterCapture("TestCaptureLimit order")
+		limit.Timeout = time.Second
+		errors.SetCaptureLimit(limit)
+
+		// a critical alert in flight, which nothing displaces
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		errors.AlertSync(ctx, errors.WithSeverity(errors.String("blocked"), errors.SeverityCritical)) //nolint:errcheck
+
+		var wg sync.WaitGroup
+		for _, err := range []error{
+			errors.WithSeverity(errors.String("warning"), errors.SeverityWarning),
+			errors.WithSeverity(errors.String("error"), errors.SeverityError),
+		} {
+			err := err
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errors.AlertSync(context.Background(), err) //nolint:errcheck
+			}()
+			time.Sleep(20 * time.Millisecond) // so that the warning waits first
+		}
+		release()
+		wg.Wait()
+		assert.Equal(t, []string{"blocked", "error", "warning"}, order)
+	})
+}
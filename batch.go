@@ -0,0 +1,73 @@
+package errors
+
+import "sync"
+
+// alertAllConcurrency limits how many groups AlertAll() alerts at once, so that a large batch does not fill the
+// queue of capture handlers.
+const alertAllConcurrency = 16
+
+// Occurrences annotates an error which was alerted on behalf of a group of similar errors, counting the errors in
+// the group. See AlertAll and SetDedupWindow.
+type Occurrences int
+
+// OccurrencesOf returns how many errors an alerted error stands for, see Occurrences, or one if it stands only for
+// itself.
+func OccurrencesOf(exception error) int {
+	if n, ok := Annotation[Occurrences](exception); ok {
+		return int(n)
+	}
+	return 1
+}
+
+// AlertAll alerts a batch of errors, for example the failures of a batch job. Rather than alerting each error,
+// errors are grouped by Fingerprint() and one error from each group is alerted. The alerted error is annotated
+// with the size of its group (see Occurrences), and with the shared annotations passed in.
+//
+// Groups are alerted concurrently, up to 16 at once, so AlertAll takes about CaptureTimeout when handlers are slow,
+// unless there are more groups.
+//
+// The error returned summarizes the batch, wrapping the alerted errors. It is nil if there are no non-nil errors
+// in the batch.
+func AlertAll(errs []error, shared ...any) error {
+	type group struct {
+		first error
+		count int
+	}
+	var groups []*group
+	byPrint := map[string]*group{}
+	total := 0
+
+	for _, err := range errs {
+		if isNil(err, "AlertAll") {
+			continue
+		}
+		total++
+		fingerprint := Fingerprint(err)
+		g := byPrint[fingerprint]
+		if g == nil {
+			g = &group{first: err}
+			byPrint[fingerprint] = g
+			groups = append(groups, g)
+		}
+		g.count++
+	}
+	if total == 0 {
+		return nil
+	}
+
+	alerted := make([]error, len(groups))
+	limit := make(chan struct{}, alertAllConcurrency)
+	var wg sync.WaitGroup
+	for i, g := range groups {
+		i, g := i, g
+		limit <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			alerted[i] = Alert(Annotate(g.first, append(concat(shared), Occurrences(g.count))...))
+		}()
+	}
+	wg.Wait()
+	return Errorf("alerted batch of (%d) errors, (%d) distinct: %w", total, len(groups), Join(alerted...))
+}
@@ -0,0 +1,69 @@
+package errors_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type batchID string
+
+func batchFailure(row int) error {
+	return errors.Errorf("row (%d) failed", row)
+}
+
+func TestAlertAll(t *testing.T) {
+	assert.NoError(t, errors.AlertAll(nil))
+	assert.NoError(t, errors.AlertAll([]error{nil, nil}))
+
+	// groups are alerted concurrently, in no particular order
+	var mu sync.Mutex
+	captured := map[string]error{}
+	errors.RegisterCapture("TestAlertAll", func(err error, _ ...any) errors.CaptureID {
+		mu.Lock()
+		defer mu.Unlock()
+		captured[errors.Fingerprint(err)] = err
+		return "TestAlertAll"
+	})
+	defer errors.UnregisterCapture("TestAlertAll")
+
+	var batch []error
+	for row := 0; row < 100; row++ {
+		batch = append(batch, batchFailure(row))
+	}
+	distinct := errors.New("something else")
+	batch = append(batch, nil, distinct)
+
+	err := errors.AlertAll(batch, batchID("TestAlertAll"))
+	assert.Contains(t, err.Error(), "alerted batch of (101) errors, (2) distinct: ")
+	assert.ErrorIs(t, err, distinct)
+
+	if assert.Len(t, captured, 2) {
+		rows := captured[errors.Fingerprint(batchFailure(0))]
+		n, _ := errors.Annotation[errors.Occurrences](rows)
+		assert.Equal(t, errors.Occurrences(100), n)
+		assert.Contains(t, rows.Error(), "row (0) failed")
+		id, _ := errors.Annotation[batchID](captured[errors.Fingerprint(distinct)])
+		assert.Equal(t, batchID("TestAlertAll"), id)
+	}
+}
+
+func TestAlertAllConcurrently(t *testing.T) {
+	errors.RegisterCapture("TestAlertAllConcurrently", func(error, ...any) errors.CaptureID {
+		time.Sleep(100 * time.Millisecond)
+		return "TestAlertAllConcurrently"
+	})
+	defer errors.UnregisterCapture("TestAlertAllConcurrently")
+
+	var errs []error
+	for i := 0; i < 8; i++ {
+		errs = append(errs, errors.New("TestAlertAllConcurrently "+strconv.Itoa(i))) // a group each
+	}
+	start := time.Now()
+	assert.Error(t, errors.AlertAll(errs))
+	assert.Less(t, time.Since(start), 400*time.Millisecond, "groups should be alerted concurrently")
+}
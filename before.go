@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// BeforeCapture is invoked with the event describing an alert, before the event reaches capture handlers. It
+// returns the event to capture, which may be redacted, and whether to capture it at all.
+type BeforeCapture func(event Event) (Event, bool)
+
+// beforeCapture is set by SetBeforeCapture().
+var beforeCapture atomic.Pointer[BeforeCapture]
+
+// SetBeforeCapture sets a function which may redact or drop each alert, after alert hooks (see
+// RegisterAlertHook) and before capture handlers. It is the one place to strip personal or secret data from
+// alerts, rather than relying on each provider's handler to do so.
+//
+// Handlers registered by RegisterCaptureV2() are passed the event returned. Handlers registered by
+// RegisterCapture() are passed its Error and Arg; so a function which redacts the message or annotations should
+// also replace the error, i.e. with Redact(event.Error), and the arguments. An event with a nil Error is dropped.
+//
+//	errors.SetBeforeCapture(func(e errors.Event) (errors.Event, bool) {
+//	  delete(e.Annotations, "email")
+//	  e.Message = emailReg.ReplaceAllString(e.Message, "<email>")
+//	  return e, true
+//	})
+//
+// Pass nil to capture events as they are.
+func SetBeforeCapture(f BeforeCapture) {
+	if f == nil {
+		beforeCapture.Store(nil)
+		return
+	}
+	beforeCapture.Store(&f)
+}
+
+// applyBeforeCapture passes an alert through the function set by SetBeforeCapture(). It returns nil if no function
+// is set, and false if the alert is dropped.
+func applyBeforeCapture(exception error, arg []any) (*Event, bool) {
+	f := beforeCapture.Load()
+	if f == nil {
+		return nil, true
+	}
+	event, ok := (*f)(NewEvent(exception, arg...))
+	if !ok || event.Error == nil {
+		log.Printf("alert not captured, dropped before capture: %+v", exception)
+		return nil, false
+	}
+	return &event, true
+}
@@ -0,0 +1,58 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBeforeCapture(t *testing.T) {
+	var (
+		captured []error
+		events   []errors.Event
+	)
+	errors.RegisterCapture("TestSetBeforeCapture", func(err error, _ ...any) errors.CaptureID {
+		captured = append(captured, err)
+		return "TestSetBeforeCapture"
+	})
+	defer errors.UnregisterCapture("TestSetBeforeCapture")
+	errors.RegisterCaptureV2("TestSetBeforeCapture v2", func(_ context.Context, e errors.Event) (errors.CaptureID, error) {
+		events = append(events, e)
+		return "TestSetBeforeCapture v2", nil
+	})
+	defer errors.UnregisterCapture("TestSetBeforeCapture v2")
+
+	errors.SetBeforeCapture(func(e errors.Event) (errors.Event, bool) {
+		if strings.Contains(e.Message, "drop") {
+			return e, false
+		}
+		delete(e.Annotations, "email")
+		e.Message = strings.ReplaceAll(e.Message, "alice@example.com", "<email>")
+		e.Error = errors.Redact(e.Error)
+		return e, true
+	})
+	defer errors.SetBeforeCapture(nil)
+
+	err := errors.Errorkv("user not found", "email", "alice@example.com")
+	var c *errors.Captured
+	assert.True(t, errors.As(errors.Alert(err), &c))
+	assert.False(t, errors.As(errors.Alert(errors.New("drop me")), &c), "dropped event should not be captured")
+
+	if assert.Len(t, captured, 1) {
+		assert.Equal(t, fmt.Sprintf("user not found [ref %s]", errors.CorrelationIDOf(captured[0])), captured[0].Error())
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "user not found (email=<email>)", events[0].Message)
+		assert.NotContains(t, events[0].Annotations, "email")
+	}
+
+	errors.SetBeforeCapture(nil)
+	errors.Alert(err)
+	if assert.Len(t, captured, 2) {
+		assert.Contains(t, captured[1].Error(), "alice@example.com")
+	}
+}
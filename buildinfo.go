@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"runtime/debug"
+)
+
+// Release identifies the build of the running program, so that errors can be attributed to a release. By default
+// it is the version of the main module or, when the version is unknown, the VCS revision stamped by the Go
+// toolchain. Programs may set it explicitly, at build time:
+//
+//	go build -ldflags "-X github.com/memsql/errors.Release=v1.2.3"
+var Release string
+
+// init fills in Release, unless it was set at build time. The linker can only set a variable which is not
+// initialized by a function call, so Release is not.
+func init() {
+	if Release == "" {
+		Release = buildRelease()
+	}
+}
+
+func buildRelease() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,23 @@
+package errors_test
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+	out, err := exec.Command(gotool, "run", "-ldflags", "-X github.com/memsql/errors.Release=v1.2.3",
+		"./testdata/release").CombinedOutput()
+	if assert.NoError(t, err, string(out)) {
+		assert.Equal(t, "v1.2.3", string(out), "release set at build time should not be replaced")
+	}
+}
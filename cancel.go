@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// CancelWithError cancels a context made by context.WithCancelCause(), recording why. The cause has a stack trace
+// of the call to CancelWithError, even if it already has a stack, so that CauseOfContext() reports where the
+// context was canceled as well as why. A nil cause records context.Canceled, with the stack.
+//
+//	ctx, cancel := context.WithCancelCause(ctx)
+//	go func() {
+//	  if err := watch(ctx); err != nil {
+//	    errors.CancelWithError(cancel, errors.Wrap(err, "watch failed"))
+//	  }
+//	}()
+func CancelWithError(cancel context.CancelCauseFunc, cause error) {
+	if cause == nil {
+		cause = context.Canceled
+	}
+	cancel(pkgerrors.WithStack(cause))
+}
+
+// CauseOfContext explains why a context is done, or returns nil if it is not. The error wraps both ctx.Err() and
+// context.Cause(ctx), so that
+//
+//	errors.Is(err, context.Canceled)
+//
+// and errors.Is(err, cause) both hold. Its stack is where the context was canceled, when canceled by
+// CancelWithError(); otherwise, where CauseOfContext is called.
+func CauseOfContext(ctx context.Context) error {
+	done := ctx.Err()
+	if done == nil {
+		return nil
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || cause == done {
+		return WithStack(done)
+	}
+	if Is(cause, done) {
+		return cause // i.e. canceled by CancelWithError(cancel, nil)
+	}
+	return Errorf("%w: %w", done, cause)
+}
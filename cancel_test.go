@@ -0,0 +1,42 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const errWatch = errors.String("watch failed")
+
+func cancelWatch(cancel context.CancelCauseFunc) {
+	errors.CancelWithError(cancel, errWatch)
+}
+
+func TestCauseOfContext(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	assert.NoError(t, errors.CauseOfContext(ctx))
+
+	cancelWatch(cancel)
+	err := errors.CauseOfContext(ctx)
+	assert.Equal(t, "context canceled: watch failed", err.Error())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, errWatch)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "cancelWatch", "stack is where the context was canceled")
+
+	ctx, cancel = context.WithCancelCause(context.Background())
+	errors.CancelWithError(cancel, nil)
+	err = errors.CauseOfContext(ctx)
+	assert.Equal(t, "context canceled", err.Error())
+	assert.ErrorIs(t, err, context.Canceled)
+
+	timeout, stop := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer stop()
+	<-timeout.Done()
+	err = errors.CauseOfContext(timeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "TestCauseOfContext")
+}
@@ -1,17 +1,22 @@
 package errors
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	pkgerrors "github.com/pkg/errors"
 )
 
-// CaptureTimeout limits how long to wait for a capture ID to be returned from a capture handler.
+// CaptureTimeout limits how long to wait for a capture ID to be returned from a capture handler. Handlers which take
+// longer keep running in the background, see Flush.
 var CaptureTimeout = 500 * time.Millisecond
 
 type CaptureProvider string // i.e. "sentry"
@@ -22,22 +27,100 @@ type CaptureID string // may be a URL or any string that allows a captured error
 //
 // Any mechanism that can save an error may provide a capture handler.  For example sentry, a log, etc... The
 // CaptureID returned should be a way to find the error among other errors captured by the mechanism.
+//
+// Annotations of the error are passed through to the handler, as part of the error. For example, a handler may
+// use SeverityOf(err) to set the level of an event, or CodeOf(err) to group events.
 type CaptureFunc func(err error, arg ...interface{}) CaptureID
 
-// capture tracks registered capture handlers.
-var capture = map[CaptureProvider]CaptureFunc{}
+// capture tracks registered capture handlers. Handlers may be registered while errors are alerted, so the map is
+// guarded by captureMu, as is captureV2.
+var (
+	captureMu sync.RWMutex
+	capture   = map[CaptureProvider]CaptureFunc{}
+)
 
-// RegisterCapture adds a handler to the set that will be invoked each time an error is captured.
+// RegisterCapture adds a handler to the set that will be invoked each time an error is captured. It panics if a
+// handler is already registered as name, see ReplaceCapture().
 func RegisterCapture(name CaptureProvider, handler CaptureFunc) {
-	if capture[name] != nil {
+	registerCapture(name, handler, nil, false)
+}
+
+// ReplaceCapture registers a handler as RegisterCapture() does, replacing the handler registered as name, if any.
+// Alerts are passed either the handler replaced or the new one: unlike UnregisterCapture() followed by
+// RegisterCapture(), none is missed in between. So a service may reconfigure a handler while it is running, for
+// example to rotate the credentials of an error tracker.
+func ReplaceCapture(name CaptureProvider, handler CaptureFunc) {
+	registerCapture(name, handler, nil, true)
+}
+
+// registerCapture registers handler, and v2 if not nil, see RegisterCaptureV2().
+func registerCapture(name CaptureProvider, handler CaptureFunc, v2 CaptureHandler, replace bool) {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	if !replace && capture[name] != nil {
 		log.Panicf("capture provider (%q) already registered", name)
 	}
 
 	capture[name] = handler
+	if v2 != nil {
+		captureV2[name] = v2
+	} else {
+		delete(captureV2, name)
+	}
 }
 
 func UnregisterCapture(name CaptureProvider) {
+	captureMu.Lock()
+	defer captureMu.Unlock()
 	delete(capture, name)
+	delete(captureV2, name)
+}
+
+// captureHandlers returns a copy of the registered capture handlers.
+func captureHandlers() (map[CaptureProvider]CaptureFunc, map[CaptureProvider]CaptureHandler) {
+	captureMu.RLock()
+	defer captureMu.RUnlock()
+	handlers := make(map[CaptureProvider]CaptureFunc, len(capture))
+	for provider, handler := range capture {
+		handlers[provider] = handler
+	}
+	handlersV2 := make(map[CaptureProvider]CaptureHandler, len(captureV2))
+	for provider, handler := range captureV2 {
+		handlersV2[provider] = handler
+	}
+	return handlers, handlersV2
+}
+
+// captureRegistered returns how many capture handlers are registered.
+func captureRegistered() int {
+	captureMu.RLock()
+	defer captureMu.RUnlock()
+	return len(capture)
+}
+
+// CaptureProviders returns the names of the registered capture handlers, sorted. A service may check at startup
+// that the handlers it expects are registered.
+//
+//	if !errors.CaptureRegistered("sentry") {
+//	  log.Fatal("sentry capture not configured")
+//	}
+func CaptureProviders() []CaptureProvider {
+	captureMu.RLock()
+	providers := make([]CaptureProvider, 0, len(capture))
+	for provider := range capture {
+		providers = append(providers, provider)
+	}
+	captureMu.RUnlock()
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// CaptureRegistered returns whether a capture handler is registered as provider.
+func CaptureRegistered(provider CaptureProvider) bool {
+	captureMu.RLock()
+	defer captureMu.RUnlock()
+	_, ok := capture[provider]
+	return ok
 }
 
 // Captured marks and wraps an error that has been "captured", meaning it has been logged verbosely or stored in
@@ -48,6 +131,9 @@ type Captured struct {
 
 	// id is a list of capture IDs, by provider
 	id map[CaptureProvider]CaptureID
+
+	// result is the outcome of each capture handler, by provider
+	result map[CaptureProvider]CaptureResult
 }
 
 // Unwrap allows errors.Unwrap to return the parent error.
@@ -58,8 +144,8 @@ func (e *Captured) Unwrap() error { return e.error }
 func (e *Captured) Format(f fmt.State, c rune) {
 	switch c {
 	case 'v':
-		if f.Flag('+') {
-			fmt.Fprintf(f, "%+v [%s]", e.error, e.allID())
+		if verbose(f) {
+			writeVerbose(f, fmt.Sprintf("%s [%s]", e.error, e.allID()), e.error)
 			return
 		}
 		fallthrough
@@ -73,6 +159,9 @@ func (e *Captured) Format(f fmt.State, c rune) {
 func (e *Captured) allID() string {
 	id := make([]string, 0, len(e.id))
 	for i := range e.id {
+		if e.id[i] == "" {
+			continue // handler did not produce an identifier
+		}
 		id = append(id, string(e.id[i]))
 	}
 	return strings.Join(id, ", ")
@@ -84,20 +173,66 @@ func (e *Captured) ID(provider CaptureProvider) CaptureID {
 	return id
 }
 
+// IDs returns the identifiers created by all capture handlers which recorded the error.
+func (e *Captured) IDs() map[CaptureProvider]CaptureID {
+	id := make(map[CaptureProvider]CaptureID, len(e.id))
+	for provider := range e.id {
+		id[provider] = e.id[provider]
+	}
+	return id
+}
+
+// WithCaptureIDs returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and records that it has been captured, with the IDs passed in. It is intended for errors received
+// from another process, which were captured there. The IDs are reported as though the error had been captured
+// locally, i.e. by Redact() and NewEvent().
+func WithCaptureIDs(exception error, id map[CaptureProvider]CaptureID) error {
+	if isNil(exception, "WithCaptureIDs") {
+		return nil
+	}
+	if len(id) == 0 {
+		return exception
+	}
+	e := &Captured{error: exception, id: make(map[CaptureProvider]CaptureID, len(id))}
+	for provider := range id {
+		e.id[provider] = id[provider]
+	}
+	return e
+}
+
 // Alert sends an error to all registered capture handlers. Capture handlers produce verbose logs and alerts.
 // This should be called only for errors that require human attention to address (our developers or SREs). It
 // should not be called for run-of-the-mill errors that are handled in code or returned to portal users.
 func Alert(err error) error {
-	if err == nil {
+	if isNil(err, "Alert") {
 		return nil
 	}
 
-	if len(capture) == 0 { // no capture handlers
-		log.Printf("alert not captured: %+v", err)
-		return WithStack(err)
+	if alerted, ok := uncaptured(err); ok {
+		return alerted
 	}
 
-	return alert(err)
+	return alert(context.Background(), err)
+}
+
+// AlertContext is like Alert(), except that capture handlers registered by RegisterCaptureV2() are passed a context
+// derived from ctx, with its deadline and values, i.e. the span of a trace. It waits at most CaptureTimeout, or
+// until ctx is done, so that an alert does not delay a request past its deadline. Handlers which did not finish
+// keep running, see Flush().
+//
+//	if err := store.Save(ctx, order); err != nil {
+//	  return errors.AlertContext(ctx, err)
+//	}
+func AlertContext(ctx context.Context, err error) error {
+	if isNil(err, "AlertContext") {
+		return nil
+	}
+
+	if alerted, ok := uncaptured(err); ok {
+		return alerted
+	}
+
+	return alert(ctx, err)
 }
 
 // Alertf produces an error and alerts. It is equivalent to calling Errorf() and then Alert().
@@ -106,16 +241,62 @@ func Alertf(format string, a ...interface{}) error {
 		// use fmt.Errorf here, to avoid a stack that is redundant with stack produced in alert()
 		error: fmt.Errorf(format, a...),
 		// don't lose track of arguments, as capture handlers may use them
-		arg: a,
+		arg:    a,
+		format: format,
+	}
+
+	return alert(context.Background(), exception)
+}
+
+// uncaptured logs an alert when no capture handlers are registered, returning the error alerted and true; or, if
+// handlers are registered, it returns false.
+func uncaptured(err error) (error, bool) {
+	if captureRegistered() > 0 {
+		return nil, false
+	}
+	alertStats.alerts.Add(1)
+	log.Printf("alert not captured: %+v", err)
+	return WithStack(err), true
+}
+
+// AlertSync is like Alert(), except that it waits for all capture handlers to finish, or for ctx to be done,
+// rather than waiting at most CaptureTimeout. This is intended for programs that must be sure an alert is
+// delivered before they exit.
+//
+// Handlers which did not finish, or which panicked, are reported in failed, by provider.
+//
+//	captured, failed := errors.AlertSync(ctx, err)
+//	for provider, problem := range failed {
+//	  fmt.Fprintf(os.Stderr, "alert not captured by %s: %s\n", provider, problem)
+//	}
+func AlertSync(ctx context.Context, err error) (captured error, failed map[CaptureProvider]error) {
+	if isNil(err, "AlertSync") {
+		return nil, nil
+	}
+
+	if alerted, ok := uncaptured(err); ok {
+		return alerted, nil
 	}
 
-	return alert(exception)
+	return alertContext(ctx, err)
 }
 
-func alert(exception error) error {
+func alert(ctx context.Context, exception error) error {
+	ctx, cancel := context.WithTimeout(ctx, CaptureTimeout)
+	defer cancel()
+
+	captured, _ := alertContext(ctx, exception)
+	return captured
+}
+
+// alertContext invokes capture handlers, waiting until they finish or ctx is done.
+func alertContext(ctx context.Context, exception error) (error, map[CaptureProvider]error) {
 	if exception == nil {
-		return nil
+		return nil, nil
 	}
+	Handled(exception)
+	alertStats.alerts.Add(1)
+	trace("Alert", exception, false)
 
 	// When alerting, we invoke registered handlers.  If those handlers in turn call (Force)Alert, we could get an
 	// infinite recursion. Here, we try to prevent that. This is relatively expensive, but we're alerting, which
@@ -125,87 +306,213 @@ func alert(exception error) error {
 	cf := runtime.CallersFrames(pc)
 	us, _ := cf.Next()
 	for them, ok := cf.Next(); ok; them, ok = cf.Next() {
-		// use HasPrefix here, not simple equality, because handlers are called from goroutine (below)
+		// use HasPrefix here, not simple equality, because handlers are called from a closure (below), by a worker
 		if strings.HasPrefix(them.Func.Name(), us.Func.Name()) {
 			log.Printf("cannot alert, recursion detected (%s): %+v", us.Func.Name(), exception)
-			return exception // don't recurse again
+			alertStats.suppressed.Add(1)
+			return exception, nil // don't recurse again
 		}
 	}
 
+	if m, ok := isMuted(exception); ok {
+		log.Printf("alert muted until %s (%s): %+v", m.Until.Format(time.RFC3339), m.Reason, exception)
+		alertStats.suppressed.Add(1)
+		return WithStack(exception), nil
+	}
+
+	if demoteKnown(exception) {
+		return WithStack(exception), nil
+	}
+
+	if deduplicate(exception) {
+		alertStats.suppressed.Add(1)
+		return WithStack(exception), nil
+	}
+
+	// global annotations, policies and hooks may annotate the error; policies and hooks may prevent it from being
+	// captured
+	hooked := runHooks(applyPolicies(applyGlobalAnnotations(exception)))
+	if hooked == nil {
+		alertStats.suppressed.Add(1)
+		return WithStack(exception), nil
+	}
+	// a reference to the alert, whether or not handlers capture it
+	exception = correlate(hooked)
+
 	// pkgerrors.WithStack provides a stack trace to this alert call,
 	// even if the wrapped error already has a stack.
 	exception = pkgerrors.WithStack(exception)
 
 	e := &Captured{
-		error: exception,
-		id:    map[CaptureProvider]CaptureID{},
+		error:  exception,
+		id:     map[CaptureProvider]CaptureID{},
+		result: map[CaptureProvider]CaptureResult{},
 	}
 
 	// pass args to hander, if any
 	var arg []any
-	Walk(exception, func(ex error) bool {
-		// we don't use As() here, because it could skip over joined errors, instead we walk the entire error tree.
-		withArg, ok := ex.(*Error)
-		if !ok {
-			return true
-		}
+	for _, layer := range ArgLayers(exception) {
+		arg = append(arg, layer.Arg...)
+	}
 
-		arg = append(arg, withArg.arg...)
-		return true
-	})
+	// the event may be redacted, or dropped, before it reaches handlers, see SetBeforeCapture()
+	event, ok := applyBeforeCapture(exception, arg)
+	if !ok {
+		alertStats.suppressed.Add(1)
+		return WithStack(exception), nil
+	}
 
-	// Run handlers in goroutines, so that if one handler is deadlocked
-	// it does not prevent others from running, or us from returning.
-	
-	timer := time.NewTimer(CaptureTimeout)
-	defer timer.Stop()
+	// Run handlers on workers (see queue.go), which stop waiting for a handler after CaptureTimeout, so that if
+	// one handler is deadlocked it does not prevent others from running, or us from returning.
 
 	done := make(chan struct{})
 	finish := func() {close(done)}
 	var once sync.Once
 	var mu sync.Mutex
-	
-	// start a goroutine for each handler
-	for provider, handler := range capture {
+	handlers, handlersV2 := captureHandlers()
+
+	// handlers may be passed only some alerts, see RouteCapture()
+	routeOut(handlers, exception, arg, event)
+
+	// hold a slot until all handlers return, see SetCaptureLimit()
+	slot, ctx := limiter.acquire(ctx, exception)
+	if slot == nil {
+		alertStats.suppressed.Add(1)
+		failed := make(map[CaptureProvider]error, len(handlers))
+		for provider := range handlers {
+			failed[provider] = Errorf("capture handler (%q) not invoked: %w", provider, ErrCaptureSaturated)
+		}
+		return exception, failed
+	}
+	remaining := int32(len(handlers))
+	if remaining == 0 {
+		limiter.release(slot)
+	}
+
+	// count handlers until they return, see Flush()
+	if !pending.start(len(handlers)) {
+		log.Printf("alert not captured, capture closed: %+v", exception)
+		if remaining > 0 {
+			limiter.release(slot)
+		}
+		alertStats.suppressed.Add(1)
+		failed := make(map[CaptureProvider]error, len(handlers))
+		for provider := range handlers {
+			failed[provider] = Errorf("capture handler (%q) not invoked: %w", provider, ErrCaptureClosed)
+		}
+		return exception, failed
+	}
+
+	// keep the alert should the process crash, see SetCrashFile()
+	recordCrash(e, len(handlers))
+
+	// report records the outcome of a handler. Caller must hold the lock.
+	report := func(provider CaptureProvider, result CaptureResult) {
+		select {
+		case <-done:
+			return // we are too late
+		default:
+		}
+		e.result[provider] = result
+		if !result.Failed() {
+			e.id[provider] = result.ID
+		}
+		if len(e.result) == len(handlers) {
+			once.Do(finish)
+		}
+	}
+
+	// providers which sample alerts may not be passed this one, see SetCaptureSampling()
+	sampled := sampledOut(exception, handlers)
+
+	// finished counts a handler which has returned, or was not invoked
+	finished := func() {
+		pending.done()
+		if atomic.AddInt32(&remaining, -1) == 0 {
+			limiter.release(slot)
+		}
+	}
+
+	// queue each handler, to be invoked by a worker
+	queue := captureWork()
+	saturated := false
+	for provider, handler := range handlers {
 		provider := provider
 		handler := handler
-		go func() {
+		queued := queue.submit(func() {
+			defer finished()
 			defer func() {
 				if r := recover(); r != nil {
 					log.Printf("failed to capture exception (%q): %+v", provider, r)
+					mu.Lock()
+					defer mu.Unlock()
+					report(provider, CaptureResult{
+						Status: CapturePanicked,
+						Err:    Errorf("capture handler (%q) panicked: %v", provider, r),
+					})
 				}
 			}()
 
-			id := handler(exception, arg...)
+			result := CaptureResult{Status: CaptureSampled}
+			if !sampled[provider] {
+				result = invokeCapture(ctx, provider, handler, handlersV2[provider], exception, arg, event)
+			}
 
 			mu.Lock()
 			defer mu.Unlock()
-			select {
-			case <-done:
-				// we are too late
-			default:
-				e.id[provider] = id
-				if len(e.id) == len(capture) {
-					once.Do(finish)
+			report(provider, result)
+		})
+		if !queued {
+			saturated = true
+			mu.Lock()
+			report(provider, CaptureResult{
+				Status: CaptureFailed,
+				Err:    Errorf("capture handler (%q) not invoked, queue full: %w", provider, ErrCaptureSaturated),
+			})
+			mu.Unlock()
+			finished()
+		}
+	}
+	if saturated {
+		limiter.queueFull(exception)
+	}
+
+	// wait until done or timed out
+	select {
+	case <-ctx.Done():
+		mu.Lock()
+		once.Do(finish)
+		for provider := range handlers {
+			if _, ok := e.result[provider]; !ok {
+				e.result[provider] = CaptureResult{
+					Status: CaptureTimedOut,
+					Err:    Errorf("capture handler (%q) did not finish: %w", provider, context.Cause(ctx)),
 				}
 			}
-		}()
+		}
+		mu.Unlock()
+	case <-done:
 	}
 
-	// wait until done or timed out
-waitLoop:
-	for {
-		select {
-		case <- timer.C:
-			mu.Lock()
-			once.Do(finish)
-			mu.Unlock()
-		case <- done:
-			break waitLoop
+	countResults(e.result)
+	settleCrash(e)
+	var failed map[CaptureProvider]error
+	for provider, result := range e.result {
+		if result.Failed() {
+			if failed == nil {
+				failed = map[CaptureProvider]error{}
+			}
+			failed[provider] = result.Err
 		}
 	}
-	
-	return e
+
+	// failed alerts may be captured later, see SetCaptureSpool()
+	if event != nil {
+		spoolFailed(event.Error, event.Arg, failed)
+	} else {
+		spoolFailed(exception, arg, failed)
+	}
+	return e, failed
 }
 
 // Walk visits each error in a tree of errors wrapping other errors.
@@ -213,24 +520,47 @@ waitLoop:
 // The handler func, f, takes in the error being visited.  The walk
 // continues if the handler returns true, and does not continue if the
 // handler returns false.
+//
+// Each error is visited once, even when it appears more than once in
+// the tree (i.e. when the same error is joined with an error that
+// wraps it).
 func Walk(exception error, f func(error) bool) {
-	walk(exception, f)
+	w := walker{f: f}
+	w.walk(exception)
+}
+
+// walker tracks the state of Walk.
+type walker struct {
+	f func(error) bool
+
+	// seen records errors visited, once a join is encountered. Before then, no error can be visited twice.
+	seen map[error]bool
 }
 
-func walk(exception error, f func(error) bool) bool {
+func (w *walker) walk(exception error) bool {
 	type join interface {
 		Unwrap() []error
 	}
 	for exception != nil {
-		ok := f(exception)
+		if w.seen != nil && identifiable(exception) {
+			if w.seen[exception] {
+				return true // already visited, along with the errors it wraps
+			}
+			w.seen[exception] = true
+		}
+
+		ok := w.f(exception)
 		if !ok {
 			return false
 		}
 
 		if j, isJoin := exception.(join); isJoin {
+			if w.seen == nil {
+				w.seen = map[error]bool{}
+			}
 			// if exception is a join, walk each
 			for _, ex := range j.Unwrap() {
-				ok := walk(ex, f)
+				ok := w.walk(ex)
 				if !ok {
 					return false
 				}
@@ -244,8 +574,19 @@ func walk(exception error, f func(error) bool) bool {
 	return true
 }
 
+// identifiable returns whether an error can be safely compared with others, to find the same error in more than
+// one place. Errors which are pointers can be compared, while other types might panic.
+func identifiable(exception error) bool {
+	return reflect.TypeOf(exception).Kind() == reflect.Pointer
+}
+
 // LogCapture is a simple capture handler that writes exception to log.
 func LogCapture(exception error, arg ...interface{}) CaptureID {
-	log.Printf("%+v", exception)
+	Handled(exception)
+	if code := CodeOf(exception); code != "" {
+		log.Printf("[%s] %+v", code, exception)
+	} else {
+		log.Printf("%+v", exception)
+	}
 	return CaptureID(time.Now().Format("2006/01/02 15:04:05")) // use time as identifier, to help find the message in the log
 }
@@ -1,8 +1,10 @@
 package errors_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -150,3 +152,199 @@ func TestCaptureTimeout(t *testing.T) {
 
 	t.Log(err) // should show capture IDs returned from faster handlers, but not slower handlers
 }
+
+func TestAlertSync(t *testing.T) {
+	captured, failed := errors.AlertSync(context.Background(), nil)
+	assert.Nil(t, captured)
+	assert.Nil(t, failed)
+
+	// slower than CaptureTimeout, so that Alert() would not wait for it
+	errors.RegisterCapture("TestAlertSync slow", func(error, ...any) errors.CaptureID {
+		time.Sleep(errors.CaptureTimeout + 100*time.Millisecond)
+		return "slow"
+	})
+	defer errors.UnregisterCapture("TestAlertSync slow")
+	errors.RegisterCapture("TestAlertSync panic", func(error, ...any) errors.CaptureID {
+		panic("TestAlertSync")
+	})
+	defer errors.UnregisterCapture("TestAlertSync panic")
+
+	captured, failed = errors.AlertSync(context.Background(), errors.New("TestAlertSync"))
+	var c *errors.Captured
+	if assert.True(t, errors.As(captured, &c)) {
+		assert.Equal(t, errors.CaptureID("slow"), c.ID("TestAlertSync slow"))
+	}
+	if assert.Len(t, failed, 1) {
+		assert.Contains(t, failed["TestAlertSync panic"].Error(), "panicked: TestAlertSync")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, failed = errors.AlertSync(ctx, errors.New("TestAlertSync"))
+	if assert.Len(t, failed, 2) {
+		assert.ErrorIs(t, failed["TestAlertSync slow"], context.DeadlineExceeded)
+	}
+}
+
+func TestAlertContext(t *testing.T) {
+	assert.NoError(t, errors.AlertContext(context.Background(), nil))
+
+	type traceKey struct{}
+	var trace any
+	release := make(chan struct{})
+	errors.RegisterCaptureV2("TestAlertContext", func(ctx context.Context, _ errors.Event) (errors.CaptureID, error) {
+		trace = ctx.Value(traceKey{})
+		<-release
+		return "late", nil
+	})
+	defer errors.UnregisterCapture("TestAlertContext")
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), traceKey{}, "span"), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var c *errors.Captured
+	if assert.True(t, errors.As(errors.AlertContext(ctx, errors.New("TestAlertContext")), &c)) {
+		assert.Equal(t, errors.CaptureTimedOut, c.Result("TestAlertContext").Status)
+	}
+	assert.Less(t, time.Since(start), errors.CaptureTimeout, "should not wait after ctx is done")
+	close(release)
+	assert.NoError(t, errors.Flush(context.Background()))
+	assert.Equal(t, "span", trace, "handler should be passed the values of ctx")
+}
+
+func TestCaptureResult(t *testing.T) {
+	errors.RegisterCapture("TestCaptureResult ok", func(error, ...any) errors.CaptureID { return "ok" })
+	defer errors.UnregisterCapture("TestCaptureResult ok")
+	errors.RegisterCapture("TestCaptureResult skip", func(error, ...any) errors.CaptureID { return "" })
+	defer errors.UnregisterCapture("TestCaptureResult skip")
+	errors.RegisterCapture("TestCaptureResult panic", func(error, ...any) errors.CaptureID { panic("TestCaptureResult") })
+	defer errors.UnregisterCapture("TestCaptureResult panic")
+	errors.RegisterCapture("TestCaptureResult slow", func(error, ...any) errors.CaptureID {
+		time.Sleep(errors.CaptureTimeout + 100*time.Millisecond)
+		return "slow"
+	})
+	defer errors.UnregisterCapture("TestCaptureResult slow")
+
+	var captured *errors.Captured
+	if !errors.As(errors.Alert(errors.New("TestCaptureResult")), &captured) {
+		t.Fatal("alert did not capture")
+	}
+
+	assert.Equal(t, errors.CaptureResult{Status: errors.CaptureOK, ID: "ok"}, captured.Result("TestCaptureResult ok"))
+	assert.Equal(t, errors.CaptureSkipped, captured.Result("TestCaptureResult skip").Status)
+	assert.Equal(t, errors.CapturePanicked, captured.Result("TestCaptureResult panic").Status)
+	assert.Equal(t, errors.CaptureTimedOut, captured.Result("TestCaptureResult slow").Status)
+	assert.True(t, captured.Result("TestCaptureResult slow").Failed())
+	assert.Equal(t, "unknown", captured.Result("not registered").Status.String())
+	assert.Len(t, captured.Results(), 4)
+	assert.Equal(t, "TestCaptureResult [ok]", fmt.Sprint(captured), "only IDs of successful handlers appear in message")
+}
+
+func TestAlertResult(t *testing.T) {
+	captured, results := errors.AlertResult(nil)
+	assert.NoError(t, captured)
+	assert.False(t, results.Captured())
+
+	captured, results = errors.AlertResult(errors.New("TestAlertResult"))
+	assert.EqualError(t, captured, "TestAlertResult")
+	assert.False(t, results.Captured(), "no handler is registered")
+
+	errors.RegisterCapture("TestAlertResult panic", func(error, ...any) errors.CaptureID { panic("TestAlertResult") })
+	defer errors.UnregisterCapture("TestAlertResult panic")
+	captured, results = errors.AlertResult(errors.New("TestAlertResult"))
+	assert.False(t, results.Captured())
+	assert.ErrorContains(t, results.Failed()["TestAlertResult panic"], "panicked")
+
+	errors.RegisterCapture("TestAlertResult ok", func(error, ...any) errors.CaptureID { return "ok" })
+	defer errors.UnregisterCapture("TestAlertResult ok")
+	captured, results = errors.AlertResult(errors.New("TestAlertResult again"))
+	assert.True(t, results.Captured())
+	assert.Equal(t, errors.CaptureResult{Status: errors.CaptureOK, ID: "ok"}, results["TestAlertResult ok"])
+	assert.Len(t, results.Failed(), 1)
+	assert.Equal(t, "TestAlertResult again [ok]", fmt.Sprint(captured))
+
+	// handlers which are not invoked fail
+	assert.NoError(t, errors.Close())
+	defer errors.Reopen()
+	_, results = errors.AlertResult(errors.New("TestAlertResult closed"))
+	assert.False(t, results.Captured())
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, errors.CaptureFailed, results["TestAlertResult ok"].Status)
+		assert.ErrorIs(t, results["TestAlertResult ok"].Err, errors.ErrCaptureClosed)
+	}
+}
+
+func TestWithCaptureIDs(t *testing.T) {
+	assert.Nil(t, errors.WithCaptureIDs(nil, nil))
+	err := errors.New("TestWithCaptureIDs")
+	assert.Same(t, err, errors.WithCaptureIDs(err, nil))
+
+	remote := errors.WithCaptureIDs(err, map[errors.CaptureProvider]errors.CaptureID{"sentry": "abc123"})
+	assert.Equal(t, "TestWithCaptureIDs [abc123]", errors.Redact(remote).Error())
+	assert.Equal(t, map[errors.CaptureProvider]errors.CaptureID{"sentry": "abc123"}, errors.NewEvent(remote).ID)
+}
+
+func TestCaptureProviders(t *testing.T) {
+	assert.False(t, errors.CaptureRegistered("TestCaptureProviders b"))
+	errors.RegisterCapture("TestCaptureProviders b", func(error, ...any) errors.CaptureID { return "" })
+	defer errors.UnregisterCapture("TestCaptureProviders b")
+	errors.RegisterCaptureV2("TestCaptureProviders a", func(context.Context, errors.Event) (errors.CaptureID, error) { return "", nil })
+	defer errors.UnregisterCapture("TestCaptureProviders a")
+
+	assert.True(t, errors.CaptureRegistered("TestCaptureProviders a"))
+	assert.True(t, errors.CaptureRegistered("TestCaptureProviders b"))
+	providers := errors.CaptureProviders()
+	assert.Subset(t, providers, []errors.CaptureProvider{"TestCaptureProviders a", "TestCaptureProviders b"})
+	assert.IsNonDecreasing(t, providers)
+
+	errors.UnregisterCapture("TestCaptureProviders a")
+	assert.False(t, errors.CaptureRegistered("TestCaptureProviders a"))
+	assert.NotContains(t, errors.CaptureProviders(), errors.CaptureProvider("TestCaptureProviders a"))
+}
+
+func TestReplaceCapture(t *testing.T) {
+	errors.RegisterCapture("TestReplaceCapture", func(error, ...any) errors.CaptureID { return "old" })
+	defer errors.UnregisterCapture("TestReplaceCapture")
+	assert.Panics(t, func() {
+		errors.RegisterCapture("TestReplaceCapture", func(error, ...any) errors.CaptureID { return "new" })
+	})
+
+	// alerts are captured by one handler or the other, while handlers are replaced
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				errors.ReplaceCapture("TestReplaceCapture", func(error, ...any) errors.CaptureID { return "old" })
+			} else {
+				errors.ReplaceCaptureV2("TestReplaceCapture", func(context.Context, errors.Event) (errors.CaptureID, error) {
+					return "new", nil
+				})
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		captured, failed := errors.AlertSync(context.Background(), errors.New("TestReplaceCapture"))
+		assert.Empty(t, failed)
+		var c *errors.Captured
+		if assert.True(t, errors.As(captured, &c)) {
+			assert.Contains(t, []errors.CaptureID{"old", "new"}, c.Result("TestReplaceCapture").ID)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	errors.ReplaceCapture("TestReplaceCapture", func(error, ...any) errors.CaptureID { return "last" })
+	captured, _ := errors.AlertSync(context.Background(), errors.New("TestReplaceCapture"))
+	var c *errors.Captured
+	if assert.True(t, errors.As(captured, &c)) {
+		assert.Equal(t, errors.CaptureID("last"), c.Result("TestReplaceCapture").ID, "a V2 handler is replaced by a V1 handler")
+	}
+}
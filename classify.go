@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"context"
+)
+
+// IsTimeout returns whether an error, or any error it wraps, reports a timeout. That includes
+// context.DeadlineExceeded, os.ErrDeadlineExceeded, any error with a Timeout() method that returns true (i.e.
+// net.Error), and errors of KindTimeout.
+func IsTimeout(exception error) bool {
+	if exception == nil {
+		return false
+	}
+	if KindOf(exception) == KindTimeout {
+		return true
+	}
+	found := false
+	Walk(exception, func(ex error) bool {
+		if t, ok := ex.(interface{ Timeout() bool }); ok && t.Timeout() {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// IsCanceled returns whether an error, or any error it wraps, is context.Canceled.
+func IsCanceled(exception error) bool {
+	return Is(exception, context.Canceled)
+}
+
+// IsTemporary returns whether the condition which caused an error is likely to pass. Errors marked by
+// MarkRetryable() or MarkPermanent() are temporary, or not, as marked. Otherwise, timeouts, errors of
+// KindUnavailable, and errors with a Temporary() method that returns true are temporary.
+func IsTemporary(exception error) bool {
+	if exception == nil {
+		return false
+	}
+	if r, ok := Annotation[retryable](exception); ok {
+		return bool(r)
+	}
+	if KindOf(exception) == KindUnavailable || IsTimeout(exception) {
+		return true
+	}
+	found := false
+	Walk(exception, func(ex error) bool {
+		if t, ok := ex.(interface{ Temporary() bool }); ok && t.Temporary() {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,49 @@
+package errors_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// temporaryError is like errors of some drivers, which report whether a condition is temporary.
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "temporary" }
+func (temporaryError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	timeout := errors.Wrap(ctx.Err(), "query failed")
+	assert.True(t, errors.IsTimeout(timeout))
+	assert.True(t, errors.IsTemporary(timeout))
+	assert.False(t, errors.IsCanceled(timeout))
+	assert.True(t, errors.IsTimeout(errors.Errorf("read failed: %w", os.ErrDeadlineExceeded)))
+	assert.True(t, errors.IsTimeout(errors.WithKind(errors.New("slow"), errors.KindTimeout)))
+	assert.False(t, errors.IsTimeout(nil))
+
+	_, err := net.DialTimeout("tcp", "127.0.0.1:1", time.Nanosecond)
+	if assert.Error(t, err) {
+		assert.True(t, errors.IsTimeout(errors.Join(errors.New("other"), errors.Wrap(err, "dial failed"))))
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	canceled := errors.Wrap(ctx.Err(), "query failed")
+	assert.True(t, errors.IsCanceled(canceled))
+	assert.False(t, errors.IsTimeout(canceled))
+	assert.False(t, errors.IsTemporary(canceled))
+
+	assert.True(t, errors.IsTemporary(errors.Wrap(temporaryError{}, "wrapped")))
+	assert.True(t, errors.IsTemporary(errors.WithKind(errors.New("down"), errors.KindUnavailable)))
+	assert.False(t, errors.IsTemporary(errors.MarkPermanent(timeout)), "markers decide")
+	assert.True(t, errors.IsTemporary(errors.MarkRetryable(errors.New("TestClassify"))))
+}
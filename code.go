@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// Code is a short, stable identifier for a class of error, i.e. "CONN-042". Unlike error message text, a code
+// does not change when a message is reworded, so support staff may use it to map a user report to an internal
+// error.
+type Code string
+
+// WithCode returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and carries the code.
+func WithCode(exception error, code Code) error {
+	return Annotate(exception, code)
+}
+
+// CodeOf returns the code of an error, or the empty string if the error has no code.
+func CodeOf(exception error) Code {
+	code, _ := Annotation[Code](exception)
+	return code
+}
+
+// Errorf produces an error, like errors.Errorf(), which carries the code.
+//
+//	const ErrConnect errors.Code = "CONN-042"
+//
+//	return ErrConnect.Errorf("failed to connect to (%s)", host)
+func (c Code) Errorf(format string, a ...any) error {
+	if devMode.Load() && !codeRegistered(c) {
+		DevModeReport(Errorf("code (%q) is not registered, see RegisterCode()", c))
+	}
+	return WithCode(Errorf(format, a...), c)
+}
+
+// CodeInfo describes a Code registered with RegisterCode().
+type CodeInfo struct {
+	Code Code
+
+	// Package is the import path of the package which registered the code.
+	Package string
+
+	// Location is the file and line where the code was registered.
+	Location string
+}
+
+var (
+	codeMu sync.Mutex
+	codes  = map[Code]CodeInfo{}
+)
+
+// RegisterCode records codes, typically in an init() function of the package that defines them.
+//
+//	const ErrConnect errors.Code = "CONN-042"
+//
+//	func init() {
+//	  errors.RegisterCode(ErrConnect)
+//	}
+//
+// A code is useful only if it identifies one class of error. RegisterCode panics when the same code is registered
+// more than once, so the conflict is found when the program starts.
+func RegisterCode(code ...Code) {
+	where := CodeInfo{}
+	if pc, file, line, ok := runtime.Caller(1); ok {
+		where.Location = fmt.Sprintf("%s:%d", file, line)
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			where.Package = symbol.Package(fn.Name())
+		}
+	}
+
+	codeMu.Lock()
+	defer codeMu.Unlock()
+	for _, c := range code {
+		if existing, ok := codes[c]; ok {
+			log.Panicf("code (%q) registered at %s is already registered at %s", c, where.Location, existing.Location)
+		}
+		where.Code = c
+		codes[c] = where
+	}
+}
+
+// Codes lists the registered codes, sorted. It is intended for tools, for example to document the codes support
+// staff may encounter.
+func Codes() []CodeInfo {
+	codeMu.Lock()
+	result := make([]CodeInfo, 0, len(codes))
+	for _, c := range codes {
+		result = append(result, c)
+	}
+	codeMu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	return result
+}
+
+func codeRegistered(code Code) bool {
+	codeMu.Lock()
+	defer codeMu.Unlock()
+	_, ok := codes[code]
+	return ok
+}
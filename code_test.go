@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const errCodeConnect errors.Code = "TEST-CONN-042"
+
+func init() {
+	errors.RegisterCode(errCodeConnect)
+}
+
+func TestCode(t *testing.T) {
+	assert.Panics(t, func() { errors.RegisterCode(errCodeConnect) }, "duplicate code")
+
+	var registered bool
+	for _, info := range errors.Codes() {
+		if info.Code == errCodeConnect {
+			registered = true
+			assert.Equal(t, "github.com/memsql/errors_test", info.Package)
+			assert.Contains(t, info.Location, "code_test.go")
+		}
+	}
+	assert.True(t, registered)
+
+	err := errors.Wrap(errCodeConnect.Errorf("failed to connect to (%s)", "db.internal"), "failed to load user")
+	assert.Equal(t, "failed to load user: failed to connect to (db.internal)", err.Error())
+	assert.Equal(t, errCodeConnect, errors.CodeOf(err))
+	assert.Equal(t, "failed to load user [code TEST-CONN-042]", errors.Redact(err).Error())
+	assert.Equal(t, errors.Code(""), errors.CodeOf(errors.New("TestCode")))
+}
+
+func TestDevModeCode(t *testing.T) {
+	var problems []error
+	defer func(report func(error)) { errors.DevModeReport = report }(errors.DevModeReport)
+	errors.DevModeReport = func(problem error) { problems = append(problems, problem) }
+	errors.SetDevMode(true)
+	defer errors.SetDevMode(false)
+
+	_ = errors.Code("TEST-UNREGISTERED").Errorf("TestDevModeCode")
+	if assert.Len(t, problems, 1) {
+		assert.Contains(t, problems[0].Error(), "is not registered")
+	}
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ConfigError details an invalid configuration value. It annotates errors produced by InvalidConfig() and
+// WrapConfig().
+type ConfigError struct {
+	// Key is the path of the configuration key, i.e. "server.port".
+	Key string
+
+	// Value describes the value provided, redacted so that secrets are not revealed. Numbers, booleans and
+	// durations appear as-is, while only the length of strings is shown.
+	Value string
+
+	// Expected describes the type or range of valid values, i.e. "integer between 1 and 65535".
+	Expected string
+}
+
+func (c ConfigError) String() string {
+	return fmt.Sprintf("%s (%s, expected %s)", c.Key, c.Value, c.Expected)
+}
+
+// InvalidConfig produces an error describing an invalid configuration value. The message follows the
+// conventions of this package, so that Redact() produces a message like "invalid configuration at server.port".
+//
+//	if port < 1 || port > 65535 {
+//	  return errors.InvalidConfig("server.port", port, "integer between 1 and 65535")
+//	}
+func InvalidConfig(key string, value any, expected string) error {
+	detail := ConfigError{Key: key, Value: redactValue(value), Expected: expected}
+	err := Errorf("invalid configuration at %s (%s, expected %s)", detail.Key, detail.Value, detail.Expected)
+	return WithKind(Annotate(err, detail), KindInvalid)
+}
+
+// WrapConfig returns nil when the exception passed in is nil; otherwise, it returns an error describing an
+// invalid configuration value, which wraps exception. It is intended for errors from parsing a value.
+//
+//	port, err := strconv.Atoi(s)
+//	if err != nil {
+//	  return errors.WrapConfig(err, "server.port", s, "integer")
+//	}
+func WrapConfig(exception error, key string, value any, expected string) error {
+	if isNil(exception, "WrapConfig") {
+		return nil
+	}
+	detail := ConfigError{Key: key, Value: redactValue(value), Expected: expected}
+	err := Errorf("invalid configuration at %s (%s, expected %s): %w", detail.Key, detail.Value, detail.Expected,
+		exception)
+	return WithKind(Annotate(err, detail), KindInvalid)
+}
+
+// redactValue describes a configuration value, without revealing potentially secret text.
+func redactValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "no value"
+	case bool, time.Duration:
+		return fmt.Sprintf("%v", v)
+	case string:
+		return fmt.Sprintf("string of length %d", len(v))
+	case []byte:
+		return fmt.Sprintf("bytes of length %d", len(v))
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%v", value)
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
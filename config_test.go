@@ -0,0 +1,46 @@
+package errors_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidConfig(t *testing.T) {
+	err := errors.InvalidConfig("server.port", 70000, "integer between 1 and 65535")
+	assert.Equal(t, "invalid configuration at server.port (70000, expected integer between 1 and 65535)", err.Error())
+	assert.Equal(t, "invalid configuration at server.port", errors.Redact(err).Error())
+	assert.Equal(t, errors.KindInvalid, errors.KindOf(err))
+
+	detail, ok := errors.Annotation[errors.ConfigError](err)
+	assert.True(t, ok)
+	assert.Equal(t, errors.ConfigError{Key: "server.port", Value: "70000", Expected: "integer between 1 and 65535"}, detail)
+}
+
+func TestWrapConfig(t *testing.T) {
+	assert.NoError(t, errors.WrapConfig(nil, "db.password", "secret", "string"))
+
+	_, parseErr := strconv.Atoi("hunter2")
+	err := errors.WrapConfig(parseErr, "db.pool", "hunter2", "integer")
+	assert.Contains(t, err.Error(), "invalid configuration at db.pool (string of length 7, expected integer): ")
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.Equal(t, "invalid configuration at db.pool", errors.Redact(err).Error())
+
+	for _, test := range []struct {
+		value any
+		want  string
+	}{
+		{nil, "no value"},
+		{true, "true"},
+		{time.Second, "1s"},
+		{3.5, "3.5"},
+		{[]int{1}, "[]int"},
+		{[]byte("secret"), "bytes of length 6"},
+	} {
+		detail, _ := errors.Annotation[errors.ConfigError](errors.InvalidConfig("key", test.value, "something"))
+		assert.Equal(t, test.want, detail.Value)
+	}
+}
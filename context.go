@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// contextErrorsKey is the key of the errors carried by a context, see ContextWithError().
+type contextErrorsKey struct{}
+
+// contextErrors are the errors carried by a context, shared by the contexts derived from it.
+type contextErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// ContextWithError carries an error in a context, so that layers which cannot return errors, i.e. hooks of a
+// router, can pass them to the layers below, or above, which read them with ErrorFromContext().
+//
+// Errors are carried by a slot, shared by ctx and the contexts derived from it. If ctx already has a slot, err is
+// added to it and ctx is returned; otherwise a context with a new slot is returned. So that a layer may see the
+// errors of the layers below it, it makes the slot before passing the context down:
+//
+//	ctx := errors.ContextWithError(r.Context(), nil)
+//	next.ServeHTTP(w, r.WithContext(ctx))
+//	if err := errors.ErrorFromContext(ctx); err != nil {
+//	  ...
+//	}
+func ContextWithError(ctx context.Context, err error) context.Context {
+	slot, ok := ctx.Value(contextErrorsKey{}).(*contextErrors)
+	if !ok {
+		slot = &contextErrors{}
+		ctx = context.WithValue(ctx, contextErrorsKey{}, slot)
+	}
+	if err != nil {
+		slot.mu.Lock()
+		slot.errs = append(slot.errs, err)
+		slot.mu.Unlock()
+	}
+	return ctx
+}
+
+// ErrorFromContext returns the errors carried by ctx, or nil if there are none, see ContextWithError(). Errors
+// added by more than one layer are merged by Join(), so an error which wraps another added earlier, i.e. because
+// a layer annotated an error it found in the context, replaces it.
+func ErrorFromContext(ctx context.Context) error {
+	slot, ok := ctx.Value(contextErrorsKey{}).(*contextErrors)
+	if !ok {
+		return nil
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	switch len(slot.errs) {
+	case 0:
+		return nil
+	case 1:
+		return slot.errs[0]
+	}
+	return Join(slot.errs...)
+}
@@ -0,0 +1,37 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWithError(t *testing.T) {
+	assert.NoError(t, errors.ErrorFromContext(context.Background()))
+
+	// passed down
+	notFound := errors.New("not found")
+	ctx := errors.ContextWithError(context.Background(), notFound)
+	assert.Same(t, notFound, errors.ErrorFromContext(ctx))
+
+	// passed up, by a layer which makes the slot before calling the layer below
+	ctx = errors.ContextWithError(context.Background(), nil)
+	assert.NoError(t, errors.ErrorFromContext(ctx))
+	below := func(ctx context.Context) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		errors.ContextWithError(ctx, notFound)
+	}
+	below(ctx)
+	assert.Same(t, notFound, errors.ErrorFromContext(ctx))
+
+	// merged
+	errors.ContextWithError(ctx, errors.New("timeout"))
+	assert.Equal(t, "not found\ntimeout", errors.ErrorFromContext(ctx).Error())
+	errors.ContextWithError(ctx, errors.Wrap(notFound, "lookup failed"))
+	err := errors.ErrorFromContext(ctx)
+	assert.Equal(t, "timeout\nlookup failed: not found", err.Error(), "an error wrapped by another should be merged")
+	assert.ErrorIs(t, err, notFound)
+}
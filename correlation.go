@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+)
+
+// CorrelationID identifies an alerted error. Unlike a CaptureID, it is assigned by this package, before any
+// capture handler is invoked, so an error has one even when every provider failed to capture it. It is passed to
+// handlers (see Event.CorrelationID and CorrelationIDOf()), and appears in the message of Redact(), so it may be
+// given to a user, who may give it to support.
+type CorrelationID string
+
+// CorrelationIDGenerator produces correlation IDs, see SetCorrelationIDGenerator().
+type CorrelationIDGenerator func() CorrelationID
+
+// generator is set by SetCorrelationIDGenerator().
+var generator atomic.Pointer[CorrelationIDGenerator]
+
+// SetCorrelationIDGenerator replaces how correlation IDs are produced, i.e. to use the format of IDs of a tracing
+// system. IDs should be unique, and short enough to read over the phone. Pass nil to restore the default, which
+// produces 16 random hexadecimal digits.
+func SetCorrelationIDGenerator(f CorrelationIDGenerator) {
+	if f == nil {
+		generator.Store(nil)
+		return
+	}
+	generator.Store(&f)
+}
+
+// CorrelationIDOf returns the correlation ID of an alerted error, or the empty string if it has not been alerted.
+func CorrelationIDOf(exception error) CorrelationID {
+	id, _ := Annotation[CorrelationID](exception)
+	return id
+}
+
+// correlate annotates an error being alerted with a correlation ID. An error which was alerted before keeps its
+// ID, so that one reference leads to every alert of it.
+func correlate(exception error) error {
+	if _, ok := Annotation[CorrelationID](exception); ok {
+		return exception
+	}
+	var id CorrelationID
+	if f := generator.Load(); f != nil {
+		id = (*f)()
+	} else {
+		id = randomCorrelationID()
+	}
+	if id == "" {
+		return exception
+	}
+	return Annotate(exception, id)
+}
+
+func randomCorrelationID() CorrelationID {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // does not fail, see crypto/rand
+	return CorrelationID(hex.EncodeToString(b))
+}
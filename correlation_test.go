@@ -0,0 +1,38 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationID(t *testing.T) {
+	var events []errors.Event
+	errors.RegisterCaptureV2("TestCorrelationID", func(_ context.Context, e errors.Event) (errors.CaptureID, error) {
+		events = append(events, e)
+		return "", errors.New("provider down")
+	})
+	defer errors.UnregisterCapture("TestCorrelationID")
+
+	assert.Empty(t, errors.CorrelationIDOf(errors.New("not alerted")))
+
+	alerted, failed := errors.AlertSync(context.Background(), errors.WithCode(errors.Errorf("user (%s) not found", "alice"), "U-1"))
+	assert.Len(t, failed, 1)
+	id := errors.CorrelationIDOf(alerted)
+	assert.Len(t, id, 16)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, id, events[0].CorrelationID)
+	}
+	assert.Equal(t, "user not found [code U-1] [ref "+string(id)+"] []", errors.Redact(alerted).Error())
+	assert.Equal(t, id, errors.CorrelationIDOf(errors.Decode(errors.Encode(alerted))))
+
+	// alerting again keeps the reference
+	assert.Equal(t, id, errors.CorrelationIDOf(errors.Alert(errors.Wrap(alerted, "retried"))))
+	assert.NotEqual(t, id, errors.CorrelationIDOf(errors.Alert(errors.New("another"))))
+
+	errors.SetCorrelationIDGenerator(func() errors.CorrelationID { return "TestCorrelationID" })
+	defer errors.SetCorrelationIDGenerator(nil)
+	assert.Equal(t, errors.CorrelationID("TestCorrelationID"), errors.CorrelationIDOf(errors.Alert(errors.New("generated"))))
+}
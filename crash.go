@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCrashEntry limits the rendering of each alert in the crash file.
+const maxCrashEntry = 16 << 10
+
+// crash is configured by SetCrashFile().
+var crash struct {
+	mu      sync.Mutex
+	file    *os.File
+	last    int
+	entries []*crashEntry // oldest first
+}
+
+// crashEntry is an alert, as written to the crash file.
+type crashEntry struct {
+	alert   *Captured
+	time    time.Time
+	text    string
+	pending bool
+	results string
+}
+
+// SetCrashFile keeps a file describing the last alerts, up to the number passed in, and which of them capture
+// handlers have not finished with. When a process dies of a fatal signal, i.e. SIGSEGV or SIGABRT raised by
+// native code, or of a fatal error of the runtime, alerts which preceded the crash are often lost along with the
+// handlers still sending them; the file preserves them for a post-mortem.
+//
+// A signal handler cannot safely render errors, nor even run Go code, when the process is crashing. So rather
+// than being written when the process crashes, the file is rewritten each time an alert is raised or captured,
+// and is always current. With Go 1.23 or later, the crash report of the runtime is also written to the file,
+// following the alerts (see runtime/debug.SetCrashOutput).
+//
+//	if err := errors.SetCrashFile("/var/run/app/crash.txt", 20); err != nil { ... }
+//
+// The file is created, or truncated, when set. It is opened once, so it can still be written when the process
+// has run out of file descriptors. Pass "" to stop keeping the file, which is left as it is.
+func SetCrashFile(path string, last int) error {
+	crash.mu.Lock()
+	defer crash.mu.Unlock()
+	if crash.file != nil {
+		crashOutput(nil)
+		_ = crash.file.Close()
+		crash.file, crash.entries = nil, nil
+	}
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return Errorf("failed to open crash file: %w", err)
+	}
+	crash.file, crash.last = file, last
+	writeCrashFile()
+	crashOutput(file)
+	return nil
+}
+
+// recordCrash adds an alert to the crash file, before capture handlers are invoked.
+func recordCrash(alert *Captured, handlers int) {
+	crash.mu.Lock()
+	defer crash.mu.Unlock()
+	if crash.file == nil || crash.last <= 0 {
+		return
+	}
+	text := fmt.Sprintf("%+v", alert.error)
+	if len(text) > maxCrashEntry {
+		text = text[:maxCrashEntry] + elision
+	}
+	crash.entries = append(crash.entries, &crashEntry{
+		alert:   alert,
+		time:    time.Now(),
+		text:    text,
+		pending: handlers > 0,
+	})
+	if n := len(crash.entries) - crash.last; n > 0 {
+		crash.entries = append(crash.entries[:0:0], crash.entries[n:]...)
+	}
+	writeCrashFile()
+}
+
+// settleCrash records in the crash file the results of the capture handlers of an alert.
+func settleCrash(alert *Captured) {
+	crash.mu.Lock()
+	defer crash.mu.Unlock()
+	if crash.file == nil {
+		return
+	}
+	for _, entry := range crash.entries {
+		if entry.alert != alert {
+			continue
+		}
+		var results []string
+		for provider, result := range alert.result {
+			results = append(results, fmt.Sprintf("%q %s", provider, result.Status))
+		}
+		sort.Strings(results)
+		entry.pending, entry.results = false, strings.Join(results, ", ")
+		writeCrashFile()
+		return
+	}
+}
+
+// writeCrashFile rewrites the crash file. The offset of the file is left at its end, which is where the runtime
+// writes its crash report. Caller must hold crash.mu.
+func writeCrashFile() {
+	b := &strings.Builder{}
+	pending := 0
+	for _, entry := range crash.entries {
+		if entry.pending {
+			pending++
+		}
+	}
+	fmt.Fprintf(b, "# last (%d) alerts of pid %d, updated %s; (%d) not yet captured\n",
+		len(crash.entries), os.Getpid(), time.Now().Format(time.RFC3339Nano), pending)
+	for _, entry := range crash.entries {
+		fmt.Fprintf(b, "\n=== %s", entry.time.Format(time.RFC3339Nano))
+		if id := CorrelationIDOf(entry.alert); id != "" {
+			fmt.Fprintf(b, " [ref %s]", id)
+		}
+		switch {
+		case entry.pending:
+			b.WriteString(" capture pending")
+		case entry.results != "":
+			fmt.Fprintf(b, " capture %s", entry.results)
+		}
+		fmt.Fprintf(b, "\n%s\n", entry.text)
+	}
+
+	_, err := crash.file.Seek(0, 0)
+	if err == nil {
+		_, err = crash.file.WriteString(b.String())
+	}
+	if err == nil {
+		err = crash.file.Truncate(int64(b.Len()))
+	}
+	if err != nil {
+		log.Printf("failed to write crash file: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build go1.23
+
+package errors
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// crashOutput sends the crash report of the runtime to the crash file, see SetCrashFile().
+func crashOutput(file *os.File) {
+	_ = debug.SetCrashOutput(file, debug.CrashOptions{})
+}
@@ -0,0 +1,10 @@
+//go:build !go1.23
+
+package errors
+
+import (
+	"os"
+)
+
+// crashOutput does nothing, as only Go 1.23 and later can send the crash report of the runtime to a file.
+func crashOutput(*os.File) {}
@@ -0,0 +1,50 @@
+package errors_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.txt")
+	assert.NoError(t, errors.SetCrashFile(path, 2))
+	defer errors.SetCrashFile("", 0) //nolint:errcheck
+
+	release := make(chan struct{})
+	errors.RegisterCapture("TestCrashFile", func(err error, _ ...any) errors.CaptureID {
+		if strings.Contains(err.Error(), "slow") {
+			<-release
+		}
+		return "ok"
+	})
+	defer errors.UnregisterCapture("TestCrashFile")
+
+	read := func() string {
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	errors.AlertSync(context.Background(), errors.New("first"))
+	errors.AlertSync(context.Background(), errors.New("second"))
+	assert.Contains(t, read(), `second`)
+	assert.Contains(t, read(), `capture "TestCrashFile" ok`)
+	assert.Contains(t, read(), "[ref ")
+
+	go errors.Alert(errors.New("slow")) // waits, at most CaptureTimeout
+	assert.Eventually(t, func() bool { return strings.Contains(read(), "slow") }, time.Second, time.Millisecond)
+	crash := read()
+	assert.NotContains(t, crash, "first", "only the last alerts are kept")
+	assert.Contains(t, crash, "(1) not yet captured")
+	assert.Contains(t, crash, "capture pending")
+
+	close(release)
+	assert.Eventually(t, func() bool { return strings.Contains(read(), "(0) not yet captured") }, time.Second, time.Millisecond)
+}
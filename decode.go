@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Decoding locates a failure to decode a document, i.e. a configuration file. It annotates errors produced by
+// WrapJSON() and WrapYAML(), see Annotation().
+type Decoding struct {
+	// Format is "json" or "yaml".
+	Format string
+
+	// Line and Column are 1-based, or zero if not known.
+	Line   int
+	Column int
+
+	// Offset is the number of bytes read before the failure, or -1 if not known.
+	Offset int64
+}
+
+// WrapJSON returns nil when the exception passed in is nil. When it is an error from "encoding/json", WrapJSON
+// returns an error which wraps it, with a message locating the failure by line and column, rather than by offset.
+// The message is suitable for users after redaction, i.e.
+//
+//	invalid JSON at line 3, column 14, field spec.replicas expects int, not string
+//
+// The error is annotated with Decoding, with KindInvalid, and, if a field could not be decoded, with its
+// FieldPath. Pass the data which was decoded, to compute line and column; or nil, if it is not available.
+//
+//	if err := json.Unmarshal(data, &config); err != nil {
+//	  return errors.WrapJSON(err, data)
+//	}
+//
+// Other errors are returned with a stack trace, see WithStack().
+func WrapJSON(exception error, data []byte) error {
+	if isNil(exception, "WrapJSON") {
+		return nil
+	}
+
+	var (
+		syntax   *json.SyntaxError
+		typeErr  *json.UnmarshalTypeError
+		decoding = Decoding{Format: "json", Offset: -1}
+		wrapped  *Error
+	)
+	switch {
+	case As(exception, &syntax):
+		decoding.Offset = syntax.Offset
+		decoding.Line, decoding.Column = lineColumn(data, syntax.Offset)
+		wrapped = Errorf("invalid JSON %s: %w", decoding.where(), exception)
+	case As(exception, &typeErr):
+		decoding.Offset = typeErr.Offset
+		decoding.Line, decoding.Column = lineColumn(data, typeErr.Offset)
+		subject := "value"
+		if typeErr.Field != "" {
+			subject = "field " + typeErr.Field
+		}
+		wrapped = Errorf("invalid JSON %s, %s expects %s, not %s: %w",
+			decoding.where(), subject, typeErr.Type, typeErr.Value, exception)
+		if typeErr.Field != "" {
+			value := []any{decoding, KindInvalid, fieldType(typeErr.Struct)}
+			for _, segment := range strings.Split(typeErr.Field, ".") {
+				value = append(value, fieldSegment(segment))
+			}
+			return Annotate(wrapped, value...)
+		}
+	default:
+		return WithStack(exception)
+	}
+	return Annotate(wrapped, decoding, KindInvalid)
+}
+
+// yamlLine matches the location in errors from "gopkg.in/yaml.v3", i.e. "yaml: line 3: ..." or
+// "yaml: line 3: column 5: ...". Unmarshal errors may report several lines; the first is used.
+var yamlLine = regexp.MustCompile(`line (\d+)(?:: column (\d+))?`)
+
+// WrapYAML returns nil when the exception passed in is nil. When it is an error from "gopkg.in/yaml.v3" (or
+// v2), WrapYAML returns an error which wraps it, with a message locating the failure, i.e.
+//
+//	invalid YAML at line 3
+//
+// The error is annotated with Decoding, and with KindInvalid. YAML errors are recognized by their text, so this
+// package does not depend on a YAML implementation. Other errors are returned with a stack trace, see WithStack().
+func WrapYAML(exception error) error {
+	if isNil(exception, "WrapYAML") {
+		return nil
+	}
+
+	text := exception.Error()
+	if !strings.HasPrefix(text, "yaml: ") {
+		return WithStack(exception)
+	}
+
+	decoding := Decoding{Format: "yaml", Offset: -1}
+	if match := yamlLine.FindStringSubmatch(text); match != nil {
+		decoding.Line, _ = strconv.Atoi(match[1])
+		decoding.Column, _ = strconv.Atoi(match[2]) // empty when no column, leaving zero
+	}
+	return Annotate(Errorf("invalid YAML %s: %w", decoding.where(), exception), decoding, KindInvalid)
+}
+
+// where describes the location of a failure.
+func (d Decoding) where() string {
+	switch {
+	case d.Line > 0 && d.Column > 0:
+		return "at line " + strconv.Itoa(d.Line) + ", column " + strconv.Itoa(d.Column)
+	case d.Line > 0:
+		return "at line " + strconv.Itoa(d.Line)
+	case d.Offset >= 0:
+		return "at offset " + strconv.FormatInt(d.Offset, 10)
+	default:
+		return "document"
+	}
+}
+
+// lineColumn converts an offset, as reported by "encoding/json", to 1-based line and column. It returns zeros if
+// data is not available.
+func lineColumn(data []byte, offset int64) (int, int) {
+	if len(data) == 0 || offset < 0 {
+		return 0, 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	if column == 0 {
+		column = 1 // offset is at the start of a line
+	}
+	return line, column
+}
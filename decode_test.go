@@ -0,0 +1,61 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapJSON(t *testing.T) {
+	assert.Nil(t, errors.WrapJSON(nil, nil))
+
+	var config struct {
+		Spec struct {
+			Replicas int `json:"replicas"`
+		} `json:"spec"`
+	}
+
+	data := []byte("{\n  \"spec\": {\n    \"replicas\": \"three\"\n  }\n}")
+	err := errors.WrapJSON(json.Unmarshal(data, &config), data)
+	assert.Equal(t, "invalid JSON at line 3, column 23, field spec.replicas expects int, not string",
+		errors.Redact(err).Error())
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, errors.KindInvalid, errors.KindOf(err))
+	decoding, _ := errors.Annotation[errors.Decoding](err)
+	assert.Equal(t, errors.Decoding{Format: "json", Line: 3, Column: 23, Offset: typeErr.Offset}, decoding)
+	path, _ := errors.FieldPathOf(err)
+	assert.Equal(t, []string{"spec", "replicas"}, path.Path)
+
+	data = []byte("{\n  \"spec\": {,\n}")
+	err = errors.WrapJSON(json.Unmarshal(data, &config), data)
+	assert.Equal(t, "invalid JSON at line 2, column 12", errors.Redact(err).Error())
+
+	err = errors.WrapJSON(json.Unmarshal(data, &config), nil)
+	assert.Equal(t, "invalid JSON at offset 14", errors.Redact(err).Error())
+
+	other := errors.String("TestWrapJSON")
+	assert.ErrorIs(t, errors.WrapJSON(other, data), other)
+	assert.Equal(t, errors.Kind(""), errors.KindOf(errors.WrapJSON(other, data)))
+}
+
+func TestWrapYAML(t *testing.T) {
+	assert.Nil(t, errors.WrapYAML(nil))
+
+	// text of errors produced by gopkg.in/yaml.v3
+	err := errors.WrapYAML(errors.String("yaml: line 3: mapping values are not allowed in this context"))
+	assert.Equal(t, "invalid YAML at line 3", errors.Redact(err).Error())
+	decoding, _ := errors.Annotation[errors.Decoding](err)
+	assert.Equal(t, errors.Decoding{Format: "yaml", Line: 3, Offset: -1}, decoding)
+
+	err = errors.WrapYAML(errors.String("yaml: unmarshal errors:\n  line 2: cannot unmarshal !!str `three` into int"))
+	assert.Equal(t, "invalid YAML at line 2", errors.Redact(err).Error())
+	assert.Equal(t, errors.KindInvalid, errors.KindOf(err))
+
+	err = errors.WrapYAML(errors.String("yaml: line 4: column 7: did not find expected key"))
+	decoding, _ = errors.Annotation[errors.Decoding](err)
+	assert.Equal(t, 7, decoding.Column)
+}
@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"runtime"
+)
+
+// decoration records where and how an error message was decorated by Expand(), Wrap() or Wrapf(). It is recorded
+// only in dev mode, to detect an error decorated twice in the same way.
+type decoration struct {
+	format string
+	caller string
+}
+
+// decorate records the decoration of exception, which wraps previous. When dev mode is enabled and previous was
+// decorated with the same format, by the same function, the problem is reported. This usually indicates a
+// deferred Expand() pasted twice, or an error wrapped both where it is returned and where it is handled.
+//
+// skip is the number of stack frames between decorate and the function which decorated the error.
+func decorate(exception *Error, previous error, format string, skip int) {
+	if !devMode.Load() {
+		return
+	}
+	caller := "unknown"
+	if pc, _, _, ok := runtime.Caller(skip + 1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			caller = fn.Name()
+		}
+	}
+	exception.decoration = &decoration{format: format, caller: caller}
+
+	var prior *Error
+	if As(previous, &prior) && prior.decoration != nil && *prior.decoration == *exception.decoration {
+		DevModeReport(Errorf("error decorated twice by %s, with format (%q): %w", caller, format, previous))
+	}
+}
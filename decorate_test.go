@@ -0,0 +1,47 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func expandTwice() (err error) {
+	defer errors.Expand(&err, "failed to frob (%d)", 1)
+	defer errors.Expand(&err, "failed to frob (%d)", 2) // pasted by mistake
+	return errors.New("expandTwice")
+}
+
+func expandOnce() (err error) {
+	defer errors.Expand(&err, "failed to frob")
+	return errors.New("expandOnce")
+}
+
+func TestDevModeDecorateTwice(t *testing.T) {
+	var problems []error
+	defer func(report func(error)) { errors.DevModeReport = report }(errors.DevModeReport)
+	errors.DevModeReport = func(problem error) { problems = append(problems, problem) }
+
+	_ = expandTwice()
+	assert.Empty(t, problems, "not in dev mode")
+
+	errors.SetDevMode(true)
+	defer errors.SetDevMode(false)
+
+	err := expandTwice()
+	assert.Equal(t, "failed to frob (1): failed to frob (2): expandTwice", err.Error(), "message is not changed")
+	if assert.Len(t, problems, 1) {
+		assert.Contains(t, problems[0].Error(), `expandTwice, with format ("failed to frob (%d)")`)
+	}
+
+	// the same decoration, by different functions, is not a problem
+	problems = nil
+	err = errors.Wrap(expandOnce(), "failed to frob")
+	assert.Equal(t, "failed to frob: failed to frob: expandOnce", err.Error())
+	assert.Empty(t, problems)
+
+	// nor is a different decoration by the same function
+	_ = errors.Wrapf(errors.Wrap(errors.New("TestDevModeDecorateTwice"), "first"), "second (%d)", 2)
+	assert.Empty(t, problems)
+}
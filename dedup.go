@@ -0,0 +1,91 @@
+package errors
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// dedup is configured by SetDedupWindow().
+var dedup struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]*dedupWindow // by fingerprint
+	swept  time.Time
+}
+
+// dedupWindow tracks alerts of one fingerprint, from the first alert captured until window ends.
+type dedupWindow struct {
+	until  time.Time
+	count  int   // alerts collapsed
+	latest error // latest alert collapsed
+	timer  *time.Timer
+}
+
+// SetDedupWindow collapses alerts with the same fingerprint (see Fingerprint()), so that a storm of identical
+// errors does not produce a capture for each one. The first alert of a fingerprint is captured as usual. Alerts
+// of the same fingerprint which follow it within window are not passed to capture handlers; when window ends,
+// the latest of them is captured once, annotated with how many were collapsed (see Occurrences). So a storm
+// produces at most two captures per window.
+//
+// A window of zero, which is the default, captures every alert. Changing the window forgets alerts seen before,
+// but collapsed alerts are still captured when their window ends.
+func SetDedupWindow(window time.Duration) {
+	dedup.mu.Lock()
+	defer dedup.mu.Unlock()
+	dedup.window = window
+	dedup.seen = nil
+}
+
+// deduplicate returns whether an alert is collapsed into an earlier one, and so must not be captured now.
+func deduplicate(exception error) bool {
+	if _, ok := Annotation[Occurrences](exception); ok {
+		return false // already collapsed
+	}
+
+	dedup.mu.Lock()
+	defer dedup.mu.Unlock()
+	if dedup.window <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	if dedup.seen == nil {
+		dedup.seen = map[string]*dedupWindow{}
+	}
+	if now.Sub(dedup.swept) > dedup.window {
+		for fingerprint, w := range dedup.seen {
+			if w.timer == nil && now.After(w.until) {
+				delete(dedup.seen, fingerprint) // expired without collapsing any alert
+			}
+		}
+		dedup.swept = now
+	}
+
+	fingerprint := Fingerprint(exception)
+	w := dedup.seen[fingerprint]
+	if w == nil || now.After(w.until) {
+		dedup.seen[fingerprint] = &dedupWindow{until: now.Add(dedup.window)}
+		return false
+	}
+
+	w.count++
+	w.latest = exception
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.until.Sub(now), func() { flushDedup(fingerprint, w) })
+	}
+	log.Printf("alert collapsed (%d since %s): %v", w.count, w.until.Add(-dedup.window).Format(time.RFC3339), exception)
+	return true
+}
+
+// flushDedup captures the alerts collapsed in a window, as one alert.
+func flushDedup(fingerprint string, w *dedupWindow) {
+	dedup.mu.Lock()
+	count, latest := w.count, w.latest
+	if dedup.seen[fingerprint] == w {
+		delete(dedup.seen, fingerprint)
+	}
+	dedup.mu.Unlock()
+
+	Alert(Annotate(latest, Occurrences(count))) //nolint:errcheck
+}
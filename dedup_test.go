@@ -0,0 +1,58 @@
+package errors_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func dedupFailure(id int) error {
+	return errors.Errorf("widget (%d) failed", id)
+}
+
+func TestSetDedupWindow(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		captured []error
+	)
+	errors.RegisterCapture("TestSetDedupWindow", func(err error, _ ...any) errors.CaptureID {
+		mu.Lock()
+		defer mu.Unlock()
+		captured = append(captured, err)
+		return "TestSetDedupWindow"
+	})
+	defer errors.UnregisterCapture("TestSetDedupWindow")
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(captured)
+	}
+
+	errors.SetDedupWindow(100 * time.Millisecond)
+	defer errors.SetDedupWindow(0)
+
+	for id := 0; id < 5; id++ {
+		errors.Alert(dedupFailure(id))
+	}
+	errors.Alert(errors.New("something else"))
+	assert.Equal(t, 2, count(), "duplicates should be collapsed")
+
+	assert.Eventually(t, func() bool { return count() == 3 }, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 1, errors.OccurrencesOf(captured[0]))
+	assert.Equal(t, 4, errors.OccurrencesOf(captured[2]))
+	assert.Equal(t, "widget (4) failed", captured[2].Error(), "latest duplicate should be captured")
+	mu.Unlock()
+
+	// after the window, the error is captured again
+	errors.Alert(dedupFailure(5))
+	assert.Equal(t, 4, count())
+
+	errors.SetDedupWindow(0)
+	errors.Alert(dedupFailure(6))
+	errors.Alert(dedupFailure(7))
+	assert.Equal(t, 6, count())
+}
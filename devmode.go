@@ -0,0 +1,21 @@
+package errors
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// devMode enables checks which detect misuse of this package.
+var devMode atomic.Bool
+
+// SetDevMode enables or disables checks which detect misuse of this package. The checks are relatively expensive,
+// so are intended for development builds and tests, not production.
+func SetDevMode(on bool) {
+	devMode.Store(on)
+}
+
+// DevModeReport is invoked when a check enabled by SetDevMode() detects a problem. By default, the problem is
+// logged, verbosely. Tests may replace it, i.e. to fail when a problem is detected.
+var DevModeReport = func(problem error) {
+	log.Printf("errors dev mode: %+v", problem)
+}
@@ -0,0 +1,281 @@
+// Package digestcapture provides a capture handler which accumulates alerts, and periodically sends a digest of
+// them by email. It is intended for low-volume tools, where paging someone for every alert is overkill, but
+// alerts should not go unnoticed.
+//
+//	digest := digestcapture.New(digestcapture.Config{
+//	  Addr:     "smtp.example.com:587",
+//	  From:     "alerts@example.com",
+//	  To:       []string{"team@example.com"},
+//	  Interval: 24 * time.Hour,
+//	})
+//	defer digest.Close()
+//	errors.RegisterCapture("digest", digest.Capture)
+//
+// Alerts are grouped by fingerprint (see errors.Fingerprint). The digest lists how many times each group was
+// alerted, and highlights groups not seen in recent digests.
+package digestcapture
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/smtp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/memsql/errors"
+)
+
+// remember is how many digests a fingerprint is remembered for, after the last digest in which it was seen. So
+// memory is bounded, and an error which recurs after a long while is new again.
+const remember = 7
+
+// DefaultTemplate renders a Digest as the body of an email.
+var DefaultTemplate = template.Must(template.New("digest").Parse(
+	`{{.Total}} alert(s) from {{.Start.Format "2006-01-02 15:04"}} to {{.End.Format "2006-01-02 15:04"}}.
+{{if .New}}
+New errors:
+{{range .New}}
+  {{.Message}}
+    count: {{.Count}}, first: {{.First.Format "15:04:05"}}{{with .Code}}, code: {{.}}{{end}}{{with .Link}}
+    {{.}}{{end}}
+{{end}}{{end}}
+All errors:
+{{range .Groups}}
+  {{printf "%6d" .Count}}  {{.Message}}{{with .Link}}
+          {{.}}{{end}}
+{{- end}}
+{{with .Dropped}}
+{{.}} alert(s) not grouped, because of the limit on the number of groups.
+{{end}}`))
+
+// Config determines where and how often digests are sent.
+type Config struct {
+	// Addr is the address of an SMTP server, including port.
+	Addr string
+
+	// Auth authenticates with the SMTP server, if needed.
+	Auth smtp.Auth
+
+	// From and To are the sender and recipients of digest emails.
+	From string
+	To   []string
+
+	// Subject of digest emails. If empty, a default subject is used.
+	Subject string
+
+	// Interval is how often a digest is sent. If zero, a digest is sent once per hour. A digest is not sent
+	// when there are no alerts to report.
+	Interval time.Duration
+
+	// Top limits how many new errors are highlighted. If zero, 10 are highlighted.
+	Top int
+
+	// MaxGroups limits how many distinct fingerprints are tracked in one digest, so that memory is bounded. If
+	// zero, 1000 are tracked. Alerts which don't fit are counted as dropped.
+	MaxGroups int
+
+	// Link optionally produces a link for a group of errors, i.e. a search in a log viewer.
+	Link func(fingerprint string) string
+
+	// Template renders a Digest as the body of an email. If nil, DefaultTemplate is used.
+	Template *template.Template
+
+	// SendMail sends email. If nil, smtp.SendMail is used.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Digest is the data passed to the template.
+type Digest struct {
+	Start time.Time
+	End   time.Time
+	Total int
+
+	// Groups of errors, by fingerprint, most frequent first.
+	Groups []*Group
+
+	// New are groups not seen in recent digests, at most Config.Top of them.
+	New []*Group
+
+	// Dropped counts alerts not included in groups.
+	Dropped int
+}
+
+// Group summarizes alerts with the same fingerprint.
+type Group struct {
+	Fingerprint string
+	Message     string // message of the first error in the group
+	Code        errors.Code
+	Count       int
+	First       time.Time
+	Last        time.Time
+	Link        string
+}
+
+// Digester accumulates alerts, and sends digests.
+type Digester struct {
+	config Config
+
+	mu      sync.Mutex
+	current *Digest
+	byPrint map[string]*Group
+	seen    map[string]int // fingerprints of recent digests, with the number of the last digest including each
+	digests int            // digests sent
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New produces a Digester, which sends digests periodically until Close is called.
+func New(config Config) *Digester {
+	if config.Subject == "" {
+		config.Subject = "error digest"
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.Top <= 0 {
+		config.Top = 10
+	}
+	if config.MaxGroups <= 0 {
+		config.MaxGroups = 1000
+	}
+	if config.Template == nil {
+		config.Template = DefaultTemplate
+	}
+	if config.SendMail == nil {
+		config.SendMail = smtp.SendMail
+	}
+
+	d := &Digester{
+		config: config,
+		seen:   map[string]int{},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	d.reset(time.Now())
+
+	go d.run()
+	return d
+}
+
+// Capture is a capture handler, which adds an alert to the digest.
+func (d *Digester) Capture(exception error, _ ...any) errors.CaptureID {
+	fingerprint := errors.Fingerprint(exception)
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.current.Total++
+	group := d.byPrint[fingerprint]
+	if group == nil {
+		if len(d.byPrint) >= d.config.MaxGroups {
+			d.current.Dropped++
+			return ""
+		}
+		group = &Group{
+			Fingerprint: fingerprint,
+			Message:     exception.Error(),
+			Code:        errors.CodeOf(exception),
+			First:       now,
+		}
+		if d.config.Link != nil {
+			group.Link = d.config.Link(fingerprint)
+		}
+		d.byPrint[fingerprint] = group
+		d.current.Groups = append(d.current.Groups, group)
+	}
+	group.Count++
+	group.Last = now
+
+	return errors.CaptureID("digest " + fingerprint)
+}
+
+// Flush sends a digest of the alerts accumulated so far, if any, and starts a new digest.
+func (d *Digester) Flush() error {
+	now := time.Now()
+
+	d.mu.Lock()
+	digest := d.current
+	digest.End = now
+	if digest.Total > 0 { // empty digests are not sent, so do not count
+		d.digests++
+		for _, group := range digest.Groups {
+			if _, ok := d.seen[group.Fingerprint]; !ok {
+				digest.New = append(digest.New, group)
+			}
+			d.seen[group.Fingerprint] = d.digests
+		}
+		for fingerprint, last := range d.seen {
+			if d.digests-last >= remember {
+				delete(d.seen, fingerprint)
+			}
+		}
+	}
+	d.reset(now)
+	d.mu.Unlock()
+
+	if digest.Total == 0 {
+		return nil
+	}
+
+	sort.SliceStable(digest.Groups, func(i, j int) bool { return digest.Groups[i].Count > digest.Groups[j].Count })
+	sort.SliceStable(digest.New, func(i, j int) bool { return digest.New[i].Count > digest.New[j].Count })
+	if len(digest.New) > d.config.Top {
+		digest.New = digest.New[:d.config.Top]
+	}
+
+	body := &bytes.Buffer{}
+	if err := d.config.Template.Execute(body, digest); err != nil {
+		return errors.Wrap(err, "failed to render digest")
+	}
+
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\n", d.config.From)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(d.config.To, ", "))
+	fmt.Fprintf(msg, "Subject: %s (%d)\r\n", d.config.Subject, digest.Total)
+	fmt.Fprintf(msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(msg, "\r\n")
+	msg.Write(body.Bytes())
+
+	err := d.config.SendMail(d.config.Addr, d.config.Auth, d.config.From, d.config.To, msg.Bytes())
+	return errors.Wrapf(err, "failed to send digest (%d alerts)", digest.Total)
+}
+
+// Close stops sending digests periodically, after sending a final digest. Closing again sends any alerts captured
+// since.
+func (d *Digester) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+	})
+	return d.Flush()
+}
+
+// reset starts a new digest. Caller must hold the lock.
+func (d *Digester) reset(now time.Time) {
+	d.current = &Digest{Start: now}
+	d.byPrint = map[string]*Group{}
+}
+
+func (d *Digester) run() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			if err := d.Flush(); err != nil {
+				log.Printf("digest capture failed: %+v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package digestcapture_test
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/digestcapture"
+	"github.com/stretchr/testify/assert"
+)
+
+func failure(id int) error {
+	return errors.Errorf("widget (%d) failed", id)
+}
+
+func TestDigest(t *testing.T) {
+	var sent []string
+	digest := digestcapture.New(digestcapture.Config{
+		Addr: "smtp.example.com:25",
+		From: "alerts@example.com",
+		To:   []string{"team@example.com"},
+		Link: func(fingerprint string) string { return "https://logs.example.com/?q=" + fingerprint },
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			assert.Equal(t, "smtp.example.com:25", addr)
+			assert.Equal(t, []string{"team@example.com"}, to)
+			sent = append(sent, string(msg))
+			return nil
+		},
+	})
+
+	id := digest.Capture(failure(1))
+	assert.Equal(t, id, digest.Capture(failure(2)))
+	digest.Capture(errors.New("something else"))
+
+	assert.NoError(t, digest.Flush())
+	if assert.Len(t, sent, 1) {
+		assert.Contains(t, sent[0], "Subject: error digest (3)")
+		assert.Contains(t, sent[0], "New errors:")
+		assert.Contains(t, sent[0], "widget (1) failed")
+		assert.Contains(t, sent[0], "something else")
+		assert.Contains(t, sent[0], "https://logs.example.com/?q="+errors.Fingerprint(failure(3)))
+	}
+
+	// nothing to report
+	assert.NoError(t, digest.Flush())
+	assert.Len(t, sent, 1)
+
+	// same error again is no longer new
+	digest.Capture(failure(3))
+	assert.NoError(t, digest.Close())
+	if assert.Len(t, sent, 2) {
+		assert.False(t, strings.Contains(sent[1], "New errors:"))
+		assert.Contains(t, sent[1], "widget (3) failed")
+	}
+
+	// a digest after closing, and closing again, is harmless
+	digest.Capture(failure(4))
+	assert.NoError(t, digest.Close())
+	assert.Len(t, sent, 3)
+}
+
+func TestForget(t *testing.T) {
+	var sent []string
+	digest := digestcapture.New(digestcapture.Config{
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			sent = append(sent, string(msg))
+			return nil
+		},
+	})
+	defer digest.Close()
+
+	digest.Capture(failure(1))
+	assert.NoError(t, digest.Flush())
+
+	// quiet intervals, without a digest, do not count
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, digest.Flush())
+	}
+	digest.Capture(failure(2))
+	assert.NoError(t, digest.Flush())
+
+	// forgotten after seven digests without it
+	for i := 0; i < 7; i++ {
+		digest.Capture(errors.New("something else"))
+		assert.NoError(t, digest.Flush())
+	}
+	digest.Capture(failure(3))
+	assert.NoError(t, digest.Flush())
+
+	if assert.Len(t, sent, 10) {
+		assert.Contains(t, sent[0], "New errors:")
+		assert.NotContains(t, sent[1], "New errors:", "seen in a recent digest")
+		assert.Contains(t, sent[9], "New errors:\n\n  widget (3) failed", "not seen in recent digests")
+	}
+}
+
+func TestMaxGroups(t *testing.T) {
+	var sent string
+	digest := digestcapture.New(digestcapture.Config{
+		MaxGroups: 1,
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			sent = string(msg)
+			return nil
+		},
+	})
+	digest.Capture(errors.New("first"))
+	assert.Equal(t, errors.CaptureID(""), digest.Capture(errors.New("second")))
+	assert.NoError(t, digest.Close())
+	assert.Contains(t, sent, "1 alert(s) not grouped")
+}
@@ -20,6 +20,14 @@ is responsible for peristing. The capture handler returns an
 identifier which becomes part of the error message, as a suffix
 enclosed in square brackets.
 
+# Annotations
+
+Use [Annotate] to attach metadata to an error, without changing the
+error message. Annotations are found by type, using [Annotation]. Some
+annotations have dedicated helpers, for example [WithCode] and
+[WithOwner]. Capture handlers may use annotations, along with
+[Fingerprint], to produce more informative alerts.
+
 # Expand and Expunge
 
 The [Expand] helper adds information to an error, while [Expunge] is
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Submission describes where a task came from, when it is processed elsewhere, i.e. by a pool of workers. It
+// annotates errors opened from an Envelope, see Annotation().
+type Submission struct {
+	// TaskID identifies the task, if the submitter provides an ID.
+	TaskID string
+
+	// Enqueued is when the task was submitted.
+	Enqueued time.Time
+
+	// Stack is the stack of the submitter, where the envelope was made.
+	Stack StackTrace
+}
+
+// Envelope pairs an error with details provided by whoever submitted the work that produced the error. Errors
+// produced by a worker goroutine have a stack trace of the worker, which does not reveal the code responsible for
+// the work. An envelope carries that provenance across channels.
+//
+//	// submitter
+//	tasks <- task{..., env: errors.NewEnvelope(id, UserID(user))}
+//
+//	// worker
+//	results <- t.env.Seal(process(t))
+//
+//	// consumer
+//	for env := range results {
+//	  if err := env.Open(); err != nil { ... }
+//	}
+type Envelope struct {
+	Submission
+
+	// Annotation values are added to the error when the envelope is opened.
+	Annotation []any
+
+	// Err is the outcome of the work, nil if successful.
+	Err error
+}
+
+// NewEnvelope records the submitter's stack and the current time, along with a task ID and annotations.
+func NewEnvelope(taskID string, annotation ...any) Envelope {
+	return Envelope{
+		Submission: Submission{
+			TaskID:   taskID,
+			Enqueued: time.Now(),
+			Stack:    callers(0),
+		},
+		Annotation: annotation,
+	}
+}
+
+// Seal returns a copy of the envelope, holding an error.
+func (env Envelope) Seal(err error) Envelope {
+	env.Err = err
+	return env
+}
+
+// Open returns nil when the envelope holds no error; otherwise, it returns the error annotated with the
+// envelope's Submission and Annotation values. Verbose output of the error ("%+v") includes the submitter's stack.
+func (env Envelope) Open() error {
+	if isNil(env.Err, "Envelope.Open") {
+		return nil
+	}
+	return Annotate(env.Err, concat([]any{env.Submission}, env.Annotation...)...)
+}
+
+// String describes a submission in verbose output.
+func (s Submission) String() string {
+	if s.TaskID == "" {
+		return fmt.Sprintf("submitted at %s", s.Enqueued.Format(time.RFC3339Nano))
+	}
+	return fmt.Sprintf("task (%q) submitted at %s", s.TaskID, s.Enqueued.Format(time.RFC3339Nano))
+}
+
+// callers returns the stack of the caller's caller, skipping additional frames as specified.
+func callers(skip int) StackTrace {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(skip+3, pc) // skip runtime.Callers, callers, and our caller
+	stack := make(StackTrace, n)
+	for i := range stack {
+		stack[i] = pkgerrors.Frame(pc[i])
+	}
+	return stack
+}
@@ -0,0 +1,46 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envelopeUser string
+
+func submitTask(tasks chan<- errors.Envelope) {
+	tasks <- errors.NewEnvelope("task-1", envelopeUser("alice"))
+}
+
+func TestEnvelope(t *testing.T) {
+	tasks := make(chan errors.Envelope, 1)
+	results := make(chan errors.Envelope, 1)
+	submitTask(tasks)
+
+	go func() {
+		env := <-tasks
+		results <- env.Seal(errors.New("worker failed"))
+	}()
+
+	env := <-results
+	err := env.Open()
+	assert.Equal(t, "worker failed", err.Error())
+
+	submission, ok := errors.Annotation[errors.Submission](err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "task-1", submission.TaskID)
+		assert.WithinDuration(t, time.Now(), submission.Enqueued, time.Minute)
+	}
+	user, _ := errors.Annotation[envelopeUser](err)
+	assert.Equal(t, envelopeUser("alice"), user)
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.Contains(t, verbose, `--- task ("task-1") submitted at`)
+	assert.Contains(t, verbose, "submitTask", "verbose output should include the submitter's stack")
+
+	assert.Nil(t, errors.NewEnvelope("task-2").Seal(nil).Open())
+}
@@ -0,0 +1,62 @@
+// Package errexpvar publishes counts of alerts (see errors.Stats) as the expvar variable "errors", so that tools
+// which scrape expvar, i.e. from /debug/vars, monitor alerting without another metrics dependency. Import it for
+// its side effect:
+//
+//	import _ "github.com/memsql/errors/errexpvar"
+//
+// The variable is a JSON object:
+//
+//	{
+//	  "alerts_total": 12,
+//	  "suppressed_total": 3,
+//	  "capture_timeouts_total": 1,
+//	  "capture_failures_total": {"sentry": 1},
+//	  "captures": {"sentry": {"captured": 8, "skipped": 0, "sampled": 0, "failed": 0, "timed_out": 1, "panicked": 0}},
+//	  "throttled": {"db pool": 2},
+//	  "known_issues": {"code W-1": 5}
+//	}
+//
+// This is a separate package, as expvar registers a handler with http.DefaultServeMux.
+package errexpvar
+
+import (
+	"expvar"
+
+	"github.com/memsql/errors"
+)
+
+// Name is the name of the published variable.
+const Name = "errors"
+
+func init() {
+	expvar.Publish(Name, expvar.Func(Vars))
+}
+
+// Vars returns the value of the published variable.
+func Vars() any {
+	stats := errors.Stats()
+	failures := make(map[string]int64, len(stats.Failures))
+	for provider, n := range stats.Failures {
+		failures[string(provider)] = n
+	}
+	captures := make(map[string]map[string]int64, len(stats.Providers))
+	for provider, p := range stats.Providers {
+		captures[string(provider)] = map[string]int64{
+			"captured":  p.Captured,
+			"skipped":   p.Skipped,
+			"sampled":   p.Sampled,
+			"failed":    p.Failed,
+			"timed_out": p.TimedOut,
+			"panicked":  p.Panicked,
+		}
+	}
+	return map[string]any{
+		"alerts_total":           stats.Alerts,
+		"suppressed_total":       stats.Suppressed,
+		"capture_timeouts_total": stats.CaptureTimeouts,
+		"capture_failures_total": failures,
+		"captures":               captures,
+		"throttled":              stats.Throttled,
+		"known_issues":           stats.KnownIssues,
+	}
+}
@@ -0,0 +1,46 @@
+package errexpvar_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errexpvar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublished(t *testing.T) {
+	errors.RegisterCapture("TestPublished", func(error, ...any) errors.CaptureID { panic("TestPublished") })
+	defer errors.UnregisterCapture("TestPublished")
+	throttle := errors.Throttle{Scope: "TestPublished", Threshold: 1}
+	_ = throttle.Alertf("TestPublished")
+	_ = throttle.Alertf("TestPublished throttled")
+	assert.NoError(t, errors.SetKnownIssues([]errors.KnownIssue{{Code: "TestPublished", Expires: time.Now().Add(time.Hour)}}))
+	defer errors.SetKnownIssues(nil) //nolint:errcheck
+	_ = errors.Alert(errors.WithCode(errors.New("TestPublished known"), "TestPublished"))
+
+	v := expvar.Get(errexpvar.Name)
+	if !assert.NotNil(t, v) {
+		return
+	}
+	var vars struct {
+		Alerts    int64                       `json:"alerts_total"`
+		Failures  map[string]int64            `json:"capture_failures_total"`
+		Captures  map[string]map[string]int64 `json:"captures"`
+		Throttled map[string]int64            `json:"throttled"`
+		Known     map[string]int64            `json:"known_issues"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(v.String()), &vars))
+	stats := errors.Stats()
+	assert.Equal(t, stats.Alerts, vars.Alerts)
+	assert.NotZero(t, vars.Failures["TestPublished"])
+	assert.Equal(t, stats.Failures["TestPublished"], vars.Failures["TestPublished"])
+	assert.Equal(t, stats.Providers["TestPublished"].Panicked, vars.Captures["TestPublished"]["panicked"])
+	assert.Zero(t, vars.Captures["TestPublished"]["captured"])
+	assert.Equal(t, stats.Throttled["TestPublished"], vars.Throttled["TestPublished"])
+	assert.NotZero(t, vars.Throttled["TestPublished"])
+	assert.Equal(t, stats.KnownIssues["code TestPublished"], vars.Known["code TestPublished"])
+	assert.NotZero(t, vars.Known["code TestPublished"])
+}
@@ -0,0 +1,111 @@
+package errgrpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/memsql/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Keys of the named values (see errors.AnnotateKV) attached by interceptors.
+const (
+	MethodKey = "grpc.method"
+	PeerKey   = "grpc.peer"
+)
+
+// UnaryServerInterceptor converts errors returned by handlers to statuses, see ToStatus. A panic in a handler is
+// recovered and alerted, and returned as an error. Errors are annotated with the method called and the address of
+// the peer which called it, so these are sent to the client along with other named values.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = serverError(ctx, info.FullMethod, errors.FromPanic(recovered), true)
+			}
+		}()
+		resp, err = handler(ctx, req)
+		return resp, serverError(ctx, info.FullMethod, err, false)
+	}
+}
+
+// StreamServerInterceptor is the equivalent of UnaryServerInterceptor, for streaming calls.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = serverError(ss.Context(), info.FullMethod, errors.FromPanic(recovered), true)
+			}
+		}()
+		return serverError(ss.Context(), info.FullMethod, handler(srv, ss), false)
+	}
+}
+
+// serverError converts an error returned by a handler to a status error.
+func serverError(ctx context.Context, method string, err error, panicked bool) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+		return err // already a status, i.e. made by a handler with status.Error
+	}
+
+	err = errors.AnnotateKV(errors.WithStack(err), MethodKey, method)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		err = errors.AnnotateKV(err, PeerKey, p.Addr.String())
+	}
+	if panicked {
+		err = errors.Alert(errors.Wrap(err, "handler panicked"))
+	}
+	return ToStatus(err).Err()
+}
+
+// UnaryClientInterceptor converts statuses returned by calls to errors, see FromStatus. Errors are annotated with
+// the method called, and have the stack of the caller.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return clientError(method, invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor is the equivalent of UnaryClientInterceptor, for streaming calls. Errors sending and
+// receiving messages are converted too, except io.EOF, which marks the end of a stream.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, clientError(method, err)
+		}
+		return &clientStream{ClientStream: cs, method: method}, nil
+	}
+}
+
+// clientError converts an error returned by a call to an error received from another service.
+func clientError(method string, err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	if st, ok := status.FromError(err); ok {
+		err = FromStatus(st)
+	}
+	return errors.AnnotateKV(errors.WithStack(err), MethodKey, method)
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return clientError(s.method, s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return clientError(s.method, s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return clientError(s.method, s.ClientStream.CloseSend())
+}
@@ -0,0 +1,93 @@
+package errgrpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errgrpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerInterceptor(t *testing.T) {
+	var alerted []error
+	errors.RegisterCapture("TestServerInterceptor", func(err error, _ ...any) errors.CaptureID {
+		alerted = append(alerted, err)
+		return "TestServerInterceptor 1"
+	})
+	defer errors.UnregisterCapture("TestServerInterceptor")
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/widgets.Widgets/Get"}
+	interceptor := errgrpc.UnaryServerInterceptor()
+
+	_, err := interceptor(ctx, nil, info, func(context.Context, any) (any, error) {
+		return nil, errors.WithKind(errors.Errorf("widget (%d) not found", 42), errors.KindNotFound)
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "widget not found", st.Message())
+	assert.Empty(t, alerted)
+
+	_, err = interceptor(ctx, nil, info, func(context.Context, any) (any, error) {
+		panic("nil widget")
+	})
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.Unknown, st.Code())
+	remote := errgrpc.FromStatus(st)
+	method, _ := errors.Value(remote, errgrpc.MethodKey)
+	assert.Equal(t, "/widgets.Widgets/Get", method)
+	if assert.Len(t, alerted, 1) {
+		assert.Equal(t, "handler panicked: nil widget", alerted[0].Error())
+		address, _ := errors.Value(alerted[0], errgrpc.PeerKey)
+		assert.Equal(t, "10.0.0.1:5000", address)
+	}
+
+	// statuses made by handlers are returned unchanged
+	_, err = interceptor(ctx, nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.ResourceExhausted, "slow down")
+	})
+	assert.Equal(t, status.Error(codes.ResourceExhausted, "slow down").Error(), err.Error())
+}
+
+type recvStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s recvStream) RecvMsg(any) error { return s.err }
+
+func TestClientInterceptor(t *testing.T) {
+	sent := errgrpc.ToStatus(errors.WithKind(errors.Wrap(errQuota, "cannot create table"), errors.KindUnavailable))
+
+	err := errgrpc.UnaryClientInterceptor()(context.Background(), "/tables.Tables/Create", nil, nil, nil,
+		func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return sent.Err()
+		})
+	assert.ErrorIs(t, err, errQuota)
+	assert.Equal(t, errors.KindUnavailable, errors.KindOf(err))
+	method, _ := errors.Value(err, errgrpc.MethodKey)
+	assert.Equal(t, "/tables.Tables/Create", method)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "TestClientInterceptor")
+
+	for _, recvErr := range []error{io.EOF, sent.Err()} {
+		cs, err := errgrpc.StreamClientInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, "/tables.Tables/List",
+			func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+				return recvStream{err: recvErr}, nil
+			})
+		assert.NoError(t, err)
+		err = cs.RecvMsg(nil)
+		if recvErr == io.EOF {
+			assert.Equal(t, io.EOF, err)
+		} else {
+			assert.ErrorIs(t, err, errQuota)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package errgrpc carries errors across gRPC boundaries. ToStatus converts an error to a gRPC status, and
+// FromStatus converts a status received from another service back to an error, preserving:
+//
+//   - the redacted message (see errors.Redact)
+//   - the code and kind of the error (see errors.CodeOf and errors.KindOf)
+//   - registered sentinels which the error wraps (see errors.RegisterSentinel), so errors.Is() works as well on
+//     the receiving side as on the sending side
+//   - named values (see errors.AnnotateKV), as strings
+//   - capture IDs, so the receiver can find where the error was alerted
+//
+// These are sent as an ErrorInfo detail of the status, in the domain "github.com/memsql/errors". Unlike the
+// message, named values are not redacted. Errors returned to untrusted clients should not have named values
+// which are sensitive.
+//
+// The interceptors of this package convert errors in this way, at the boundaries of servers and clients:
+//
+//	server := grpc.NewServer(
+//	  grpc.ChainUnaryInterceptor(errgrpc.UnaryServerInterceptor()),
+//	  grpc.ChainStreamInterceptor(errgrpc.StreamServerInterceptor()),
+//	)
+//
+// This package is a separate module, so that programs which do not use gRPC do not depend on it.
+package errgrpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/memsql/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain identifies the ErrorInfo detail produced by ToStatus.
+const Domain = "github.com/memsql/errors"
+
+// Prefixes of ErrorInfo metadata keys.
+const (
+	sentinelKey = "sentinel."
+	captureKey  = "capture."
+	fieldKey    = "field."
+	kindKey     = "kind"
+)
+
+// kindCode maps kinds of errors to gRPC codes, and back.
+var kindCode = map[errors.Kind]codes.Code{
+	errors.KindInvalid:         codes.InvalidArgument,
+	errors.KindNotFound:        codes.NotFound,
+	errors.KindAlreadyExists:   codes.AlreadyExists,
+	errors.KindPermission:      codes.PermissionDenied,
+	errors.KindUnauthenticated: codes.Unauthenticated,
+	errors.KindUnavailable:     codes.Unavailable,
+	errors.KindTimeout:         codes.DeadlineExceeded,
+	errors.KindInternal:        codes.Internal,
+}
+
+// ToStatus converts an error to a gRPC status, which is safe to return to a client. The gRPC code of the status is
+// derived from the kind of the error; or if it has no kind, from a status the error wraps, or from
+// context.Canceled and context.DeadlineExceeded. Otherwise, the code is codes.Unknown. ToStatus returns a status
+// with codes.OK when the error is nil.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	st := status.New(Code(err), errors.Redact(err).Summary())
+	info := &errdetails.ErrorInfo{
+		Reason:   string(errors.CodeOf(err)),
+		Domain:   Domain,
+		Metadata: map[string]string{},
+	}
+	if kind := errors.KindOf(err); kind != "" {
+		info.Metadata[kindKey] = string(kind)
+	}
+	for i, s := range sentinels(err) {
+		info.Metadata[sentinelKey+strconv.Itoa(i)] = string(s)
+	}
+	for key, value := range errors.Annotations(err) {
+		info.Metadata[fieldKey+key] = fmt.Sprint(value)
+	}
+	var captured *errors.Captured
+	if errors.As(err, &captured) {
+		for provider, id := range captured.IDs() {
+			if id != "" {
+				info.Metadata[captureKey+string(provider)] = string(id)
+			}
+		}
+	}
+
+	detailed, detailErr := st.WithDetails(info)
+	if detailErr != nil {
+		return st // not expected, ErrorInfo can always be encoded
+	}
+	return detailed
+}
+
+// Code returns the gRPC code of an error, see ToStatus.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if code, ok := kindCode[errors.KindOf(err)]; ok {
+		return code
+	}
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcStatus) {
+		return grpcStatus.GRPCStatus().Code()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	return codes.Unknown
+}
+
+// FromStatus converts a status, typically received from another service, to an error. It returns nil when the
+// status is nil or has codes.OK. The error has the message of the status, and the details sent by ToStatus, when
+// present. The kind of the error is derived from the gRPC code, if the status does not specify it.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	e := &remote{status: st}
+	switch st.Code() {
+	case codes.Canceled:
+		e.wrapped = append(e.wrapped, context.Canceled)
+	case codes.DeadlineExceeded:
+		e.wrapped = append(e.wrapped, context.DeadlineExceeded)
+	}
+
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if i, ok := detail.(*errdetails.ErrorInfo); ok && i.GetDomain() == Domain {
+			info = i
+			break
+		}
+	}
+	metadata := info.GetMetadata()
+
+	// sort keys, so that sentinels are wrapped in order, and fields are annotated deterministically
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, sentinelKey) {
+			e.wrapped = append(e.wrapped, errors.String(metadata[key]))
+		}
+	}
+
+	var err error = e
+	kind := errors.Kind(metadata[kindKey])
+	if kind == "" {
+		for k, code := range kindCode {
+			if code == st.Code() && code != codes.DeadlineExceeded {
+				kind = k
+			}
+		}
+	}
+	if kind != "" {
+		err = errors.WithKind(err, kind)
+	}
+	if code := info.GetReason(); code != "" {
+		err = errors.WithCode(err, errors.Code(code))
+	}
+	id := map[errors.CaptureProvider]errors.CaptureID{}
+	for _, key := range keys {
+		switch {
+		case strings.HasPrefix(key, fieldKey):
+			err = errors.AnnotateKV(err, strings.TrimPrefix(key, fieldKey), metadata[key])
+		case strings.HasPrefix(key, captureKey):
+			id[errors.CaptureProvider(strings.TrimPrefix(key, captureKey))] = errors.CaptureID(metadata[key])
+		}
+	}
+	return errors.WithCaptureIDs(err, id)
+}
+
+// remote is an error received from another service.
+type remote struct {
+	status  *status.Status
+	wrapped []error
+}
+
+func (e *remote) Error() string { return e.status.Message() }
+
+// Unwrap returns the sentinels the error wrapped, in the service which sent it.
+func (e *remote) Unwrap() []error { return e.wrapped }
+
+// GRPCStatus returns the status received, so that an error may be returned, unchanged, to the next client.
+func (e *remote) GRPCStatus() *status.Status { return e.status }
+
+// sentinels returns registered sentinels, and any other String errors, which an error wraps.
+func sentinels(err error) []errors.String {
+	var result []errors.String
+	seen := map[errors.String]bool{}
+	add := func(s errors.String) {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	errors.Walk(err, func(ex error) bool {
+		if s, ok := ex.(errors.String); ok {
+			add(s)
+		}
+		return true
+	})
+	for _, s := range errors.Sentinels() {
+		if errors.Is(err, s.String) {
+			add(s.String)
+		}
+	}
+	return result
+}
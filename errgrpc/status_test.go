@@ -0,0 +1,64 @@
+package errgrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errgrpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errQuota errors.String = "errgrpc quota exceeded"
+
+func init() {
+	errors.RegisterSentinel(errQuota)
+}
+
+func TestStatus(t *testing.T) {
+	errors.RegisterCapture("TestStatus", func(error, ...any) errors.CaptureID { return "TestStatus 1" })
+	err := errors.AnnotateKV(errors.Wrap(errQuota, "cannot create table (secret)"), "tenant", "acme")
+	err = errors.Alert(errors.WithCode(errors.WithKind(err, errors.KindUnavailable), "Q-1"))
+	errors.UnregisterCapture("TestStatus")
+
+	st := errgrpc.ToStatus(err)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, "cannot create table", st.Message())
+
+	// as though received from another service
+	received, ok := status.FromError(status.ErrorProto(st.Proto()))
+	assert.True(t, ok)
+	remote := errgrpc.FromStatus(received)
+	assert.Equal(t, "cannot create table", remote.Error())
+	assert.ErrorIs(t, remote, errQuota)
+	assert.Equal(t, errors.Code("Q-1"), errors.CodeOf(remote))
+	assert.Equal(t, errors.KindUnavailable, errors.KindOf(remote))
+	tenant, _ := errors.Value(remote, "tenant")
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, "cannot create table [code Q-1] [TestStatus 1]", errors.Redact(remote).Error())
+	assert.Equal(t, codes.Unavailable, errgrpc.ToStatus(remote).Code(), "status should survive another hop")
+	assert.Equal(t, codes.Unavailable, status.Code(remote))
+}
+
+func TestFromStatus(t *testing.T) {
+	assert.Nil(t, errgrpc.FromStatus(nil))
+	assert.Nil(t, errgrpc.FromStatus(status.New(codes.OK, "")))
+
+	err := errgrpc.FromStatus(status.New(codes.NotFound, "widget not found"))
+	assert.Equal(t, "widget not found", err.Error())
+	assert.Equal(t, errors.KindNotFound, errors.KindOf(err))
+
+	err = errgrpc.FromStatus(status.New(codes.DeadlineExceeded, "too slow"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, errors.Kind(""), errors.KindOf(err))
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, codes.OK, errgrpc.Code(nil))
+	assert.Equal(t, codes.Unknown, errgrpc.Code(errors.New("TestCode")))
+	assert.Equal(t, codes.Canceled, errgrpc.Code(errors.Wrap(context.Canceled, "TestCode")))
+	assert.Equal(t, codes.ResourceExhausted, errgrpc.Code(errors.Wrap(status.Error(codes.ResourceExhausted, "full"), "TestCode")))
+	assert.Equal(t, codes.InvalidArgument, errgrpc.Code(errors.WithKind(errors.New("TestCode"), errors.KindInvalid)))
+}
@@ -0,0 +1,93 @@
+// Package errhttp handles errors of HTTP handlers consistently. Middleware recovers panics of handlers, and
+// HandlerFunc adapts handlers which return errors:
+//
+//	mux.Handle("/widgets/", errhttp.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+//	  widget, err := store.Widget(r.Context(), path.Base(r.URL.Path))
+//	  if err != nil {
+//	    return errors.Wrap(err, "failed to get widget")
+//	  }
+//	  return json.NewEncoder(w).Encode(widget)
+//	}))
+//	http.ListenAndServe(addr, errhttp.Middleware(mux))
+//
+// Errors are annotated with the method, path and ID of the request (see errors.AnnotateKV). Errors which are not
+// the client's fault, that is those with a status of 500 or more (see errors.HTTPStatus), and all panics, are
+// alerted. The response is a redacted problem details document (see package problem), so no sensitive detail
+// reaches the client.
+package errhttp
+
+import (
+	"net/http"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/problem"
+)
+
+// RequestIDHeader is the header which identifies a request, i.e. as set by a load balancer.
+var RequestIDHeader = "X-Request-Id"
+
+// HandlerFunc is an HTTP handler which returns an error, rather than writing an error response itself.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f, and responds with the error it returns, if any.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := &responseWriter{ResponseWriter: w}
+	if err := f(rw, r); err != nil {
+		respond(rw, r, err, false)
+	}
+}
+
+// Middleware recovers panics of the next handler, alerts, and responds with an error. A panic with
+// http.ErrAbortHandler is not recovered, as it is the conventional way to abort a response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			if err := errors.FromPanic(recovered); err != nil {
+				respond(rw, r, errors.Wrap(err, "handler panicked"), true)
+			}
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// respond annotates an error with details of the request, alerts if need be, and writes a response unless one
+// has already been started.
+func respond(w *responseWriter, r *http.Request, err error, panicked bool) {
+	err = errors.AnnotateKV(err, "method", r.Method)
+	err = errors.AnnotateKV(err, "path", r.URL.Path)
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		err = errors.AnnotateKV(err, "request_id", id)
+	}
+	if panicked || errors.HTTPStatus(err) >= http.StatusInternalServerError {
+		err = errors.Alert(err)
+	}
+
+	if w.written {
+		return // too late to respond with the error
+	}
+	problem.Write(w, r, err)
+}
+
+// responseWriter records whether a response has been started.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer, i.e. to flush.
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
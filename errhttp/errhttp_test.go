@@ -0,0 +1,72 @@
+package errhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func capture(t *testing.T) *[]error {
+	var captured []error
+	errors.RegisterCapture("errhttp_test", func(err error, _ ...any) errors.CaptureID {
+		captured = append(captured, err)
+		return "errhttp 1"
+	})
+	t.Cleanup(func() { errors.UnregisterCapture("errhttp_test") })
+	return &captured
+}
+
+func serve(handler http.Handler) (*httptest.ResponseRecorder, map[string]any) {
+	request := httptest.NewRequest(http.MethodPost, "/widgets/42", nil)
+	request.Header.Set("X-Request-Id", "req-1")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var body map[string]any
+	_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+	return recorder, body
+}
+
+func TestMiddleware(t *testing.T) {
+	captured := capture(t)
+
+	recorder, body := serve(errhttp.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("widget (secret) exploded")
+	})))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "handler panicked", body["detail"])
+	assert.Equal(t, "errhttp 1", body["capture_id"])
+	if assert.Len(t, *captured, 1) {
+		assert.Equal(t, "handler panicked: widget (secret) exploded", (*captured)[0].Error())
+		assert.Equal(t, map[string]any{"method": "POST", "path": "/widgets/42", "request_id": "req-1"}, errors.Annotations((*captured)[0]))
+	}
+
+	assert.Panics(t, func() {
+		serve(errhttp.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		})))
+	})
+}
+
+func TestHandlerFunc(t *testing.T) {
+	captured := capture(t)
+
+	recorder, body := serve(errhttp.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+		return errors.WithKind(errors.Errorf("widget (%d) not found", 42), errors.KindNotFound)
+	}))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "widget not found", body["detail"])
+	assert.Empty(t, *captured, "client errors are not alerted")
+
+	recorder, _ = serve(errhttp.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return errors.New("failed after responding")
+	}))
+	assert.Equal(t, http.StatusAccepted, recorder.Code, "response already started")
+	assert.Len(t, *captured, 1)
+}
@@ -1,12 +1,11 @@
 package errors
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	pkgerrors "github.com/pkg/errors"
 )
@@ -17,10 +16,80 @@ var (
 
 	As     = errors.As
 	Is     = errors.Is
-	Join   = errors.Join
 	Unwrap = errors.Unwrap
 )
 
+// Join returns an error that wraps the errors passed in, like Join() in the standard library "errors" package.
+//
+// Unlike the standard library, Join omits errors which appear more than once, including errors that are wrapped
+// by another of the errors passed in. So when an error is mistakenly joined with an error that wraps it, its
+// message and arguments are not reported twice.
+func Join(errs ...error) error {
+	// find errors wrapped by each of errs
+	wrapped := map[error]bool{}
+	for _, err := range errs {
+		root := true
+		Walk(err, func(ex error) bool {
+			if !root && identifiable(ex) {
+				wrapped[ex] = true
+			}
+			root = false
+			return true
+		})
+	}
+
+	kept := make([]error, 0, len(errs))
+	seen := map[error]bool{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if identifiable(err) {
+			if wrapped[err] || seen[err] {
+				continue // redundant
+			}
+			seen[err] = true
+		}
+		kept = append(kept, err)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return &joinError{errs: kept}
+}
+
+// joinError is produced by Join.
+type joinError struct {
+	errs []error
+}
+
+// Error separates the messages of joined errors with newlines, like Join() in the standard library.
+func (e *joinError) Error() string {
+	text := make([]string, len(e.errs))
+	for i := range e.errs {
+		text[i] = e.errs[i].Error()
+	}
+	return strings.Join(text, "\n")
+}
+
+func (e *joinError) Unwrap() []error { return e.errs }
+
+// Format includes the verbose details of each joined error, when "%+v" is the format string.
+func (e *joinError) Format(f fmt.State, c rune) {
+	switch c {
+	case 'v':
+		if verbose(f) {
+			writeVerbose(f, e.Error(), e)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(f, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", e.Error())
+	}
+}
+
 type StackTrace = pkgerrors.StackTrace
 
 // Error implements Go's error interface; and can format verbose messages, including stack traces.
@@ -30,6 +99,15 @@ type Error struct {
 
 	// arg records the arguments used to construct an error message; it serves as metadata about the error
 	arg []interface{}
+
+	// format is the template of the error message, see Template()
+	format string
+
+	// decoration is recorded in dev mode, see decorate()
+	decoration *decoration
+
+	// created is when the error was produced, see CreatedAt()
+	created time.Time
 }
 
 // Unwrap allows errors.Unwrap to return the parent error.
@@ -40,52 +118,12 @@ func (e *Error) Unwrap() error { return e.error }
 func (e *Error) Format(f fmt.State, c rune) {
 	switch c {
 	case 'v':
-		// start with the error message
-		_, _ = io.WriteString(f, e.Error()) // if this fails, not much we can do
-
-		if f.Flag('+') {
-
-			// If we've wrapped an error that implements fmt.Formatter, we defer to it's Format() behavior for
-			// verbose output.  Typically this adds a stack trace, although it might add more details. It also
-			// will display the wrapped message, which is usually redundant, included in the error message we've
-			// already written. So the code that follows take steps to remove the redundant information.  We also
-			// remove parts of stack traces that are internal to this package, as they may add several lines of
-			// unimportant information that distracts from the real points of interest in the stack.
-
-			var formatter interface {
-				fmt.Formatter
-				error
-			}
-			if As(e.error, &formatter) {
-				// the wrapped error implements Format, so it might add additional verbose details
-				buf := &bytes.Buffer{}
-				_, _ = fmt.Fprintf(buf, "%+v", formatter)
-
-				// omit leading lines that repeat text that already appears in error message
-				// omit leading stack within this package
-				leading := true
-				scanner := bufio.NewScanner(buf)
-				for scanner.Scan() {
-					line := scanner.Text()
-					if leading {
-						if strings.Contains(e.Error(), line) {
-							// line is redunant, a portion of the error message
-							continue
-						}
-						if strings.HasPrefix(line, "github.com/memsql/errors.") {
-							// line is stack trace within this package, not relevant to the human inspecting the stack
-							if !scanner.Scan() { // skip two lines of stack trace
-								break
-							}
-							continue
-						}
-					}
-					_, _ = io.WriteString(f, "\n"+line) // if this fails, not much we can do
-					leading = false
-				}
-			}
-
+		if verbose(f) {
+			// Include the verbose details, typically a stack trace, of errors we've wrapped. See writeVerbose().
+			writeVerbose(f, e.Error(), e.error)
+			return
 		}
+		_, _ = io.WriteString(f, e.Error()) // if this fails, not much we can do
 	case 's':
 		_, _ = fmt.Fprintf(f, "%s", e.error)
 	case 'q':
@@ -93,6 +131,17 @@ func (e *Error) Format(f fmt.State, c rune) {
 	}
 }
 
+// Template returns the format string, or message, from which the error was produced. Unlike the error message, it
+// does not include dynamic arguments; so it identifies errors produced by the same code, even when their messages
+// differ. For example, the template of
+//
+//	errors.Errorf("user (%d) not found", id)
+//
+// is "user (%d) not found". Errors produced by Wrap() have the template message + ": %w".
+func (e *Error) Template() string {
+	return e.format
+}
+
 // New emulates the behavior of stdlib's errors.New(), and includes a stack trace with the error.
 func New(text string) error {
 	return WithStack(errors.New(text))
@@ -131,8 +180,10 @@ func FromPanic(in interface{}) (exception error) {
 // for example a wrapped error or string included in error text.
 func Errorf(format string, a ...interface{}) *Error {
 	exception := &Error{
-		error: WithStack(fmt.Errorf(format, a...)),
-		arg:   a,
+		error:   WithStack(fmt.Errorf(format, a...)),
+		arg:     a,
+		format:  format,
+		created: time.Now(),
 	}
 
 	// if wrapping an error, no need to include it in args
@@ -145,6 +196,7 @@ func Errorf(format string, a ...interface{}) *Error {
 		exception.arg = exception.arg[1:]
 	}
 
+	trace("Errorf", exception, true)
 	return exception
 }
 
@@ -156,7 +208,7 @@ func Errorf(format string, a ...interface{}) *Error {
 // To add information to an error message, use Errorf() instead. This function is provided to add a stack trace
 // to a third-party error without otherwise altering the error text.
 func WithStack(err error) error {
-	if err == nil {
+	if isNil(err, "WithStack") {
 		return nil
 	}
 
@@ -190,10 +242,14 @@ type StackTracer interface {
 //
 //	return errors.Wrap(f(), "failed objective")
 func Wrap(exception error, message string) error {
-	if exception == nil {
+	if isNil(exception, "Wrap") {
 		return nil
 	}
-	return Errorf("%s: %w", message, exception)
+	wrapped := Errorf("%s: %w", message, exception)
+	wrapped.format = message + ": %w"
+	decorate(wrapped, exception, message, 1)
+	trace("Wrap", wrapped, true)
+	return wrapped
 }
 
 // Wrapf returns nil when the exception passed in is nil; otherwise, it produces text based on the format string
@@ -201,10 +257,37 @@ func Wrap(exception error, message string) error {
 //
 // See Wrap() for rationale.
 func Wrapf(exception error, format string, a ...interface{}) error {
-	if exception == nil {
+	if isNil(exception, "Wrapf") {
+		return nil
+	}
+	wrapped := Errorf(format+": %w", concat(a, exception)...)
+	decorate(wrapped, exception, format, 1)
+	trace("Wrapf", wrapped, true)
+	return wrapped
+}
+
+// WrapAll is like Wrap(), except that when the exception is a join of errors (see Join, and Join in the standard
+// library), each of the joined errors is wrapped, and the wrapped errors are joined. So rather than a message
+// which prefixes only the first of the joined messages, each message is prefixed, and each joined error keeps its
+// own annotations and arguments.
+//
+//	err := errors.WrapAll(errors.Join(errA, errB), "stage 2 failed")
+//	// stage 2 failed: A
+//	// stage 2 failed: B
+func WrapAll(exception error, message string) error {
+	if isNil(exception, "WrapAll") {
 		return nil
 	}
-	return Errorf(format+": %w", concat(a, exception)...)
+	branches, ok := joinedErrors(exception)
+	if !ok {
+		// not a join, or not one whose messages can be wrapped individually, i.e. fmt.Errorf("%w; %w", a, b)
+		return Wrap(exception, message)
+	}
+	wrapped := make([]error, len(branches))
+	for i := range branches {
+		wrapped[i] = Wrap(branches[i], message)
+	}
+	return Join(wrapped...)
 }
 
 // Expand rewites an error message, when an error is non-nil.
@@ -213,14 +296,16 @@ func Wrapf(exception error, format string, a ...interface{}) error {
 // immediately before returning it.
 func Expand(exception *error, format string, a ...interface{}) {
 	recovered := false
-	if *exception == nil {
+	if isNil(*exception, "Expand") {
 		*exception = FromPanic(recover())
 		recovered = true
 	}
 	if *exception == nil {
 		return // nothing to do
 	}
-	*exception = Errorf(format+": %w", concat(a, *exception)...)
+	expanded := Errorf(format+": %w", concat(a, *exception)...)
+	decorate(expanded, *exception, format, 1)
+	*exception = expanded
 
 	if recovered {
 		*exception = Alert(*exception)
@@ -237,7 +322,7 @@ func Expand(exception *error, format string, a ...interface{}) {
 // immediately before returning it from a public API.
 func Expunge(exception *error, format string, a ...interface{}) {
 	recovered := false
-	if *exception == nil {
+	if isNil(*exception, "Expunge") {
 		*exception = FromPanic(recover())
 		recovered = true
 	}
@@ -247,6 +332,7 @@ func Expunge(exception *error, format string, a ...interface{}) {
 
 	ex := Errorf("%s: %w", fmt.Sprintf(format, a...), Redact(*exception))
 	ex.arg = append(ex.arg, a...)
+	ex.format = format + ": %w"
 	*exception = ex
 
 	if recovered {
@@ -261,7 +347,7 @@ func Expunge(exception *error, format string, a ...interface{}) {
 // affect the error. In other words, the last reached deferred ExpungeOnce() will determine the final error
 // message.
 func ExpungeOnce(exception *error, format string, a ...interface{}) {
-	if *exception == nil {
+	if isNil(*exception, "ExpungeOnce") {
 		*exception = Alert(FromPanic(recover()))
 	}
 	if *exception == nil {
@@ -279,7 +365,7 @@ func ExpungeOnce(exception *error, format string, a ...interface{}) {
 
 // concat is like append(), without side effects. So the slice passed in will not be changed (even if it has high capacity).
 func concat(head []any, tail ...any) []any {
-	result := make([]any, len(head), len(head) + len(tail))
+	result := make([]any, len(head), len(head)+len(tail))
 	copy(result, head)
 	result = append(result, tail...)
 	return result
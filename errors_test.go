@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	stderrors "errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -187,3 +188,64 @@ func TestFromPanic(t *testing.T) {
 type myStringer struct{}
 
 func (s myStringer) String() string { return "hello world" }
+
+func TestJoinDuplicate(t *testing.T) {
+	inner := errors.Errorf("inner (%s)", "needle")
+	outer := errors.Wrap(inner, "outer")
+
+	joined := errors.Join(inner, nil, outer, outer)
+	assert.Equal(t, "outer: inner (needle)", joined.Error())
+	assert.ErrorIs(t, joined, inner)
+	assert.Nil(t, errors.Join(nil, nil))
+
+	// the same error may be reached twice in a tree built by the standard library
+	tree := fmt.Errorf("%w; %w", inner, outer)
+	visited := 0
+	errors.Walk(tree, func(ex error) bool {
+		if ex == inner {
+			visited++
+		}
+		return true
+	})
+	assert.Equal(t, 1, visited)
+
+	var arg []any
+	errors.RegisterCapture("TestJoinDuplicate", func(_ error, a ...any) errors.CaptureID {
+		arg = a
+		return "TestJoinDuplicate"
+	})
+	defer errors.UnregisterCapture("TestJoinDuplicate")
+	_ = errors.Alert(tree)
+	assert.Equal(t, []any{"needle"}, arg, "args should not be reported twice")
+}
+
+func TestWrapAll(t *testing.T) {
+	assert.Nil(t, errors.WrapAll(nil, "stage 2 failed"))
+
+	a := errors.WithCode(errors.Errorf("table (%s) missing", "widgets"), "T-1")
+	b := errors.New("disk full")
+	joined := errors.WrapAll(errors.Join(a, b), "stage 2 failed")
+	assert.Equal(t, "stage 2 failed: table (widgets) missing\nstage 2 failed: disk full", joined.Error())
+	assert.ErrorIs(t, joined, a)
+	assert.ErrorIs(t, joined, b)
+
+	var branches []error
+	errors.Walk(joined, func(ex error) bool {
+		if e, ok := ex.(*errors.Error); ok && e.Template() == "stage 2 failed: %w" {
+			branches = append(branches, e)
+		}
+		return true
+	})
+	if assert.Len(t, branches, 2) {
+		assert.Equal(t, errors.Code("T-1"), errors.CodeOf(branches[0]))
+		assert.Equal(t, errors.Code(""), errors.CodeOf(branches[1]))
+	}
+
+	stdlib := errors.WrapAll(stderrors.Join(a, b), "stage 2 failed")
+	assert.Equal(t, joined.Error(), stdlib.Error())
+
+	// not a join, so wrapped as a whole
+	tree := fmt.Errorf("%w; %w", a, b)
+	assert.Equal(t, "stage 2 failed: table (widgets) missing; disk full", errors.WrapAll(tree, "stage 2 failed").Error())
+	assert.Equal(t, "stage 2 failed: disk full", errors.WrapAll(b, "stage 2 failed").Error())
+}
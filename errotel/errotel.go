@@ -0,0 +1,84 @@
+// Package errotel correlates errors with OpenTelemetry traces. AnnotateFromContext records the trace and span
+// where an error occurred:
+//
+//	if err := process(ctx, order); err != nil {
+//	  return errotel.AnnotateFromContext(ctx, errors.Wrap(err, "failed to process order"))
+//	}
+//
+// The IDs are named values (see errors.AnnotateKV), so they appear with the error wherever it is logged or
+// captured. Capture records alerted errors on their spans, so that a trace shows the error along with the IDs of
+// other capture providers:
+//
+//	errors.RegisterCapture("otel", errotel.Capture)
+//
+// This package is a separate module, so that programs which do not use OpenTelemetry do not depend on it.
+package errotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/memsql/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Keys of the named values attached by AnnotateFromContext.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// span annotates an error with the span where it occurred, see Capture.
+type span struct {
+	trace.Span
+}
+
+// AnnotateFromContext annotates an error with the trace ID and span ID of the span in ctx. The error is returned
+// unchanged if ctx has no valid span, and nil is returned if the error is nil.
+func AnnotateFromContext(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	s := trace.SpanFromContext(ctx)
+	sc := s.SpanContext()
+	if !sc.IsValid() {
+		return err
+	}
+	if existing, ok := errors.Annotation[span](err); ok && existing.SpanContext().Equal(sc) {
+		return err // already annotated, i.e. by a deeper call in the same span
+	}
+	err = errors.AnnotateKV(err, TraceIDKey, sc.TraceID().String())
+	err = errors.AnnotateKV(err, SpanIDKey, sc.SpanID().String())
+	return errors.Annotate(err, span{s})
+}
+
+// TraceID returns the ID of the trace recorded by AnnotateFromContext, if any.
+func TraceID(err error) (trace.TraceID, bool) {
+	s, ok := errors.Annotation[span](err)
+	if !ok {
+		return trace.TraceID{}, false
+	}
+	return s.SpanContext().TraceID(), true
+}
+
+// Capture is a capture handler which records an error on the span recorded by AnnotateFromContext, as an
+// exception event with the stack trace of the error, and sets the status of the span to error. The capture ID is
+// the trace ID. Errors without a span, or whose span has ended, are skipped.
+func Capture(exception error, _ ...any) errors.CaptureID {
+	s, ok := errors.Annotation[span](exception)
+	if !ok || !s.IsRecording() {
+		return ""
+	}
+	attr := []attribute.KeyValue{
+		attribute.String("exception.stacktrace", fmt.Sprintf("%+v", exception)),
+		attribute.String("error.fingerprint", errors.Fingerprint(exception)),
+	}
+	if code := errors.CodeOf(exception); code != "" {
+		attr = append(attr, attribute.String("error.code", string(code)))
+	}
+	s.RecordError(exception, trace.WithAttributes(attr...))
+	s.SetStatus(codes.Error, errors.Redact(exception).Summary())
+	return errors.CaptureID(s.SpanContext().TraceID().String())
+}
@@ -0,0 +1,57 @@
+package errotel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCapture(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("errotel_test")
+
+	errors.RegisterCapture("TestCapture", errotel.Capture)
+	defer errors.UnregisterCapture("TestCapture")
+
+	assert.Equal(t, errors.New("no span").Error(), errotel.AnnotateFromContext(context.Background(), errors.New("no span")).Error())
+	assert.Nil(t, errotel.AnnotateFromContext(context.Background(), nil))
+
+	ctx, span := tracer.Start(context.Background(), "TestCapture")
+	err := errotel.AnnotateFromContext(ctx, errors.Errorf("order (%d) failed", 42))
+	assert.Equal(t, err, errotel.AnnotateFromContext(ctx, err), "annotated once per span")
+
+	traceID, ok := errotel.TraceID(err)
+	assert.True(t, ok)
+	assert.Equal(t, span.SpanContext().TraceID(), traceID)
+	value, _ := errors.Value(err, errotel.SpanIDKey)
+	assert.Equal(t, span.SpanContext().SpanID().String(), value)
+
+	captured := errors.Alert(err)
+	span.End()
+
+	var c *errors.Captured
+	if assert.True(t, errors.As(captured, &c)) {
+		assert.Equal(t, errors.CaptureID(traceID.String()), c.ID("TestCapture"))
+	}
+	ended := recorder.Ended()
+	if assert.Len(t, ended, 1) {
+		assert.Equal(t, codes.Error, ended[0].Status().Code)
+		if assert.Len(t, ended[0].Events(), 1) {
+			event := ended[0].Events()[0]
+			assert.Equal(t, "exception", event.Name)
+			var stack string
+			for _, attr := range event.Attributes {
+				if attr.Key == "exception.stacktrace" {
+					stack = attr.Value.AsString()
+				}
+			}
+			assert.Contains(t, stack, "errotel_test.TestCapture")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package errprom exposes counts of alerts (see errors.Stats) as Prometheus metrics:
+//
+//	prometheus.MustRegister(errprom.NewCollector())
+//
+// The metrics are:
+//
+//	errors_alerts_total                            alerts, including those suppressed
+//	errors_alerts_suppressed_total                 alerts not sent to capture handlers
+//	errors_alerts_throttled_total{scope}           alerts suppressed by a throttle
+//	errors_alerts_known_issue_total{issue}         alerts demoted because they match a known issue
+//	errors_captures_total{provider,status}         outcomes of capture handlers
+//	errors_capture_timeouts_total                  capture handlers which did not finish in time
+//
+// The status of a capture is one of "ok", "skipped", "sampled", "failed", "timed out" or "panicked", see
+// errors.CaptureStatus.
+//
+// This package is a separate module, so that programs which do not use Prometheus do not depend on it.
+package errprom
+
+import (
+	"github.com/memsql/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace prefixes the names of metrics.
+const Namespace = "errors"
+
+type collector struct {
+	alerts     *prometheus.Desc
+	suppressed *prometheus.Desc
+	throttled  *prometheus.Desc
+	known      *prometheus.Desc
+	captures   *prometheus.Desc
+	timeouts   *prometheus.Desc
+}
+
+// NewCollector produces a collector of the metrics of this package. The metrics are read from errors.Stats()
+// when collected, so the collector may be registered at any time without missing earlier alerts.
+func NewCollector() prometheus.Collector {
+	return &collector{
+		alerts: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "alerts_total"),
+			"Alerts, including those suppressed.", nil, nil),
+		suppressed: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "alerts", "suppressed_total"),
+			"Alerts not sent to capture handlers, because they were throttled, muted, demoted or dropped.", nil, nil),
+		throttled: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "alerts", "throttled_total"),
+			"Alerts suppressed by a throttle, by scope.", []string{"scope"}, nil),
+		known: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "alerts", "known_issue_total"),
+			"Alerts demoted because they match a known issue, by issue.", []string{"issue"}, nil),
+		captures: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "captures_total"),
+			"Outcomes of capture handlers, by provider.", []string{"provider", "status"}, nil),
+		timeouts: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "capture_timeouts_total"),
+			"Capture handlers which did not finish in time.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.alerts
+	ch <- c.suppressed
+	ch <- c.throttled
+	ch <- c.known
+	ch <- c.captures
+	ch <- c.timeouts
+}
+
+// Collect implements prometheus.Collector.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := errors.Stats()
+	ch <- prometheus.MustNewConstMetric(c.alerts, prometheus.CounterValue, float64(stats.Alerts))
+	ch <- prometheus.MustNewConstMetric(c.suppressed, prometheus.CounterValue, float64(stats.Suppressed))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.CaptureTimeouts))
+	for scope, n := range stats.Throttled {
+		ch <- prometheus.MustNewConstMetric(c.throttled, prometheus.CounterValue, float64(n), scope)
+	}
+	for issue, n := range stats.KnownIssues {
+		ch <- prometheus.MustNewConstMetric(c.known, prometheus.CounterValue, float64(n), issue)
+	}
+	for provider, p := range stats.Providers {
+		for status, n := range map[errors.CaptureStatus]int64{
+			errors.CaptureOK:       p.Captured,
+			errors.CaptureSkipped:  p.Skipped,
+			errors.CaptureSampled:  p.Sampled,
+			errors.CaptureFailed:   p.Failed,
+			errors.CaptureTimedOut: p.TimedOut,
+			errors.CapturePanicked: p.Panicked,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.captures, prometheus.CounterValue, float64(n), string(provider), status.String())
+		}
+	}
+}
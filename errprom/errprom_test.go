@@ -0,0 +1,48 @@
+package errprom_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	errors.RegisterCapture("TestCollector", func(error, ...any) errors.CaptureID { panic("TestCollector") })
+	defer errors.UnregisterCapture("TestCollector")
+
+	throttle := errors.Throttle{Scope: "TestCollector", Threshold: 1}
+	_ = throttle.Alertf("first")
+	_ = throttle.Alertf("second")
+	assert.NoError(t, errors.SetKnownIssues([]errors.KnownIssue{{Code: "TestCollector", Expires: time.Now().Add(time.Hour)}}))
+	defer errors.SetKnownIssues(nil) //nolint:errcheck
+	_ = errors.Alert(errors.WithCode(errors.New("known"), "TestCollector"))
+
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(errprom.NewCollector()))
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP errors_alerts_total Alerts, including those suppressed.
+# TYPE errors_alerts_total counter
+errors_alerts_total 3
+# HELP errors_alerts_known_issue_total Alerts demoted because they match a known issue, by issue.
+# TYPE errors_alerts_known_issue_total counter
+errors_alerts_known_issue_total{issue="code TestCollector"} 1
+# HELP errors_alerts_throttled_total Alerts suppressed by a throttle, by scope.
+# TYPE errors_alerts_throttled_total counter
+errors_alerts_throttled_total{scope="TestCollector"} 1
+# HELP errors_captures_total Outcomes of capture handlers, by provider.
+# TYPE errors_captures_total counter
+errors_captures_total{provider="TestCollector",status="failed"} 0
+errors_captures_total{provider="TestCollector",status="ok"} 0
+errors_captures_total{provider="TestCollector",status="panicked"} 1
+errors_captures_total{provider="TestCollector",status="sampled"} 0
+errors_captures_total{provider="TestCollector",status="skipped"} 0
+errors_captures_total{provider="TestCollector",status="timed out"} 0
+`), "errors_alerts_total", "errors_alerts_known_issue_total", "errors_alerts_throttled_total", "errors_captures_total"))
+}
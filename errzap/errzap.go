@@ -0,0 +1,130 @@
+// Package errzap logs errors with zap as structured objects, rather than as flat strings.
+//
+//	logger.Error("request failed", errzap.Error(err))
+//
+// The object includes the message, code, kind, owner, severity and fingerprint of the error; its tags, named values
+// and arguments; the IDs of alerts which captured it; and the stack trace where it originated. The fields are named
+// as those of errors.SlogAttrs().
+//
+// This package is a separate module, so that programs which do not use zap do not depend on it.
+package errzap
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/memsql/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Error produces a field named "error", which describes err. It is the equivalent of zap.Error(), and likewise
+// produces a field which is skipped when err is nil.
+func Error(err error) zap.Field {
+	return NamedError("error", err)
+}
+
+// NamedError is like Error, with a field named key.
+func NamedError(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, Object(err))
+}
+
+// Object adapts an error to zapcore.ObjectMarshaler.
+func Object(err error) zapcore.ObjectMarshaler {
+	return object{err}
+}
+
+type object struct {
+	error
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (o object) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	err := o.error
+	errors.Handled(err)
+	event := errors.NewEvent(err)
+
+	enc.AddString("msg", event.Message)
+	for key, value := range map[string]string{
+		"code":    string(event.Code),
+		"kind":    string(event.Kind),
+		"owner":   event.Owner,
+		"runbook": string(event.Runbook),
+	} {
+		if value != "" {
+			enc.AddString(key, value)
+		}
+	}
+	enc.AddString("severity", event.Severity.String())
+	enc.AddString("fingerprint", event.Fingerprint)
+
+	var failed error
+	add := func(err error) {
+		if failed == nil {
+			failed = err
+		}
+	}
+	if len(event.Tags) > 0 {
+		add(enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, tag := range event.Tags {
+				arr.AppendString(tag)
+			}
+			return nil
+		})))
+	}
+	if annotations := errors.Annotations(err); len(annotations) > 0 {
+		add(enc.AddObject("annotations", zapcore.ObjectMarshalerFunc(func(obj zapcore.ObjectEncoder) error {
+			for key, value := range annotations {
+				if err := obj.AddReflected(key, value); err != nil {
+					obj.AddString(key, fmt.Sprint(value)) // not all values can be encoded
+				}
+			}
+			return nil
+		})))
+	}
+	if len(event.Layers) > 0 {
+		add(enc.AddArray("args", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for i := range event.Layers {
+				arr.AppendString(event.Layers[i].String()) // shows which layer of the error supplied each arg
+			}
+			return nil
+		})))
+	}
+	if len(event.ID) > 0 {
+		add(enc.AddObject("capture", zapcore.ObjectMarshalerFunc(func(obj zapcore.ObjectEncoder) error {
+			providers := make([]string, 0, len(event.ID))
+			for provider := range event.ID {
+				providers = append(providers, string(provider))
+			}
+			sort.Strings(providers)
+			for _, provider := range providers {
+				obj.AddString(provider, string(event.ID[errors.CaptureProvider(provider)]))
+			}
+			return nil
+		})))
+	}
+	if stack := originStack(err); len(stack) > 0 {
+		add(enc.AddArray("stack", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for i := range stack {
+				arr.AppendString(fmt.Sprintf("%+v", stack[i]))
+			}
+			return nil
+		})))
+	}
+	return failed
+}
+
+// originStack returns the innermost stack of an error, which is closest to where the error originated.
+func originStack(err error) errors.StackTrace {
+	var stack errors.StackTrace
+	errors.Walk(err, func(ex error) bool {
+		if tracer, ok := ex.(errors.StackTracer); ok && len(tracer.StackTrace()) > 0 {
+			stack = tracer.StackTrace()
+		}
+		return true
+	})
+	return stack
+}
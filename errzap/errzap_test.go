@@ -0,0 +1,50 @@
+package errzap_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/errzap"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel))
+
+	errors.RegisterCapture("TestError", func(error, ...any) errors.CaptureID { return "TestError 1" })
+	err := errors.Alert(errors.WithCode(errors.Errorkv("user not found", "user_id", 42), "U-1"))
+	errors.UnregisterCapture("TestError")
+
+	logger.Error("TestError", errzap.Error(err), errzap.NamedError("other", nil))
+	assert.NoError(t, logger.Sync())
+
+	var record struct {
+		Error struct {
+			Msg         string            `json:"msg"`
+			Code        string            `json:"code"`
+			Severity    string            `json:"severity"`
+			Fingerprint string            `json:"fingerprint"`
+			Annotations map[string]any    `json:"annotations"`
+			Args        []string          `json:"args"`
+			Capture     map[string]string `json:"capture"`
+			Stack       []string          `json:"stack"`
+		} `json:"error"`
+		Other any `json:"other"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record), buf.String())
+	assert.Contains(t, record.Error.Msg, "user not found (user_id=42)")
+	assert.Equal(t, "U-1", record.Error.Code)
+	assert.Equal(t, "error", record.Error.Severity)
+	assert.Equal(t, errors.Fingerprint(err), record.Error.Fingerprint)
+	assert.Equal(t, map[string]any{"user_id": 42.0}, record.Error.Annotations)
+	assert.NotEmpty(t, record.Error.Args)
+	assert.Equal(t, map[string]string{"TestError": "TestError 1"}, record.Error.Capture)
+	assert.Contains(t, strings.Join(record.Error.Stack, "\n"), "errzap_test.TestError")
+	assert.Nil(t, record.Other)
+}
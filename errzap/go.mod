@@ -0,0 +1,17 @@
+module github.com/memsql/errors/errzap
+
+go 1.20
+
+require (
+	github.com/memsql/errors v0.0.0-20261016134454-a87a81350d90
+	github.com/stretchr/testify v1.9.0
+	go.uber.org/zap v1.26.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"time"
+)
+
+// Event describes an error at the time it is captured. It gathers the details which capture handlers, and tools
+// that analyze captured errors, are most likely to need.
+type Event struct {
+	Time          time.Time
+	Age           time.Duration // how long before Time the error was produced, see Age()
+	Release       string
+	Error         error
+	Message       string
+	Template      string
+	Fingerprint   string
+	CorrelationID CorrelationID // see CorrelationIDOf()
+	Severity      Severity
+	Kind          Kind
+	Code          Code
+	Owner         string
+	Runbook       Runbook
+	Tags          []string
+	TagValues     map[string]string // see TagValuesOf()
+	Resources     map[string]string // see ResourceTags()
+	Annotations   map[string]any    // named values, see Annotations()
+	Arg           []any
+
+	// Stack is the stack trace where the error originated.
+	Stack StackTrace
+
+	// Layers are the arguments of the error, grouped by the layer which supplied them.
+	Layers []ArgLayer
+
+	// ID are identifiers of earlier captures of the error, found in its chain of wrapped errors.
+	ID map[CaptureProvider]CaptureID
+}
+
+// NewEvent describes an error, along with the arguments passed to a capture handler. The exception must not be
+// nil.
+func NewEvent(exception error, arg ...any) Event {
+	a := Analyze(exception)
+	correlation, _ := IndexedAnnotation[CorrelationID](a.Indexed)
+	event := Event{
+		Time:          time.Now(),
+		Release:       Release,
+		Error:         exception,
+		Message:       exception.Error(),
+		Template:      templateOf(exception),
+		Fingerprint:   Fingerprint(exception),
+		CorrelationID: correlation,
+		Severity:      a.Severity(),
+		Kind:          a.Kind(),
+		Code:          a.Code(),
+		Owner:         a.Owner(),
+		Runbook:       a.Runbook(),
+		Tags:          a.Tags(),
+		TagValues:     a.TagValues(),
+		Resources:     a.Resources(),
+		Annotations:   a.Annotations(),
+		Arg:           arg,
+		Stack:         originStack(exception),
+		Layers:        a.ArgLayers(),
+		ID:            a.captureIDs(),
+	}
+	if created, ok := a.CreatedAt(); ok {
+		event.Age = event.Time.Sub(created)
+	}
+	return event
+}
+
+// templateOf returns the template of the outermost *Error with one, see Error.Template().
+func templateOf(exception error) string {
+	var template string
+	Walk(exception, func(ex error) bool {
+		if e, ok := ex.(*Error); ok && usableTemplate(e.format) {
+			template = e.format
+			return false
+		}
+		return true
+	})
+	return template
+}
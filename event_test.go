@@ -0,0 +1,26 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEvent(t *testing.T) {
+	err := errors.WithOwner(errors.WithKind(errors.WithCode(errors.Errorf("widget (%d) failed", 42), "W-1"), errors.KindNotFound), "team-widget")
+	event := errors.NewEvent(err, 42)
+
+	assert.Equal(t, "widget (42) failed", event.Message)
+	assert.Equal(t, "widget (%d) failed", event.Template)
+	assert.Equal(t, errors.Fingerprint(err), event.Fingerprint)
+	assert.Equal(t, errors.SeverityError, event.Severity)
+	assert.Equal(t, errors.KindNotFound, event.Kind)
+	assert.Equal(t, errors.Code("W-1"), event.Code)
+	assert.Equal(t, "team-widget", event.Owner)
+	assert.Equal(t, errors.Runbook(""), event.Runbook)
+	assert.Equal(t, []any{42}, event.Arg)
+	assert.Equal(t, errors.Release, event.Release)
+	assert.False(t, event.Time.IsZero())
+	assert.Empty(t, event.ID)
+}
@@ -0,0 +1,154 @@
+// Package export writes captured errors in formats suitable for offline analysis, i.e. loading into a data
+// warehouse to find regressions by release.
+//
+// A Recorder is a capture handler which keeps events in memory, until they are drained and written:
+//
+//	recorder := &export.Recorder{}
+//	errors.RegisterCapture("export", recorder.Capture)
+//	...
+//	err := export.NewCSVWriter(file).Write(recorder.Drain())
+//
+// Formats other than CSV, for example Parquet, can be supported by implementing Writer, typically using the
+// Row produced for each event. Row has struct tags understood by common Parquet libraries.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memsql/errors"
+)
+
+// Writer writes a batch of events.
+type Writer interface {
+	Write(events []errors.Event) error
+}
+
+// Row is the flattened representation of an event, as written by exporters.
+type Row struct {
+	Time        time.Time `parquet:"time,timestamp"`
+	Release     string    `parquet:"release"`
+	Fingerprint string    `parquet:"fingerprint"`
+	Severity    string    `parquet:"severity"`
+	Kind        string    `parquet:"kind"`
+	Code        string    `parquet:"code"`
+	Owner       string    `parquet:"owner"`
+	Message     string    `parquet:"message"`
+	Arg         string    `parquet:"arg"`
+	ID          string    `parquet:"id"`
+	Template    string    `parquet:"template"`
+}
+
+// Columns names the fields of a Row, in the order they are written to CSV.
+var Columns = []string{"time", "release", "fingerprint", "severity", "kind", "code", "owner", "message", "arg", "id",
+	"template"}
+
+// NewRow flattens an event.
+func NewRow(event errors.Event) Row {
+	arg := make([]string, len(event.Arg))
+	for i := range event.Arg {
+		arg[i] = fmt.Sprint(event.Arg[i])
+	}
+
+	id := make([]string, 0, len(event.ID))
+	for provider := range event.ID {
+		id = append(id, fmt.Sprintf("%s=%s", provider, event.ID[provider]))
+	}
+	sort.Strings(id)
+
+	return Row{
+		Time:        event.Time.UTC(),
+		Release:     event.Release,
+		Fingerprint: event.Fingerprint,
+		Severity:    event.Severity.String(),
+		Kind:        string(event.Kind),
+		Code:        string(event.Code),
+		Owner:       event.Owner,
+		Message:     event.Message,
+		Arg:         strings.Join(arg, " "),
+		ID:          strings.Join(id, " "),
+		Template:    event.Template,
+	}
+}
+
+// record returns the fields of a row, in the order of Columns.
+func (r Row) record() []string {
+	return []string{
+		r.Time.Format(time.RFC3339Nano),
+		r.Release,
+		r.Fingerprint,
+		r.Severity,
+		r.Kind,
+		r.Code,
+		r.Owner,
+		r.Message,
+		r.Arg,
+		r.ID,
+		r.Template,
+	}
+}
+
+// CSVWriter writes events as CSV, one row per event. A header row is written before the first batch.
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter produces a CSVWriter which writes to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes a batch of events, and flushes them to the underlying writer.
+func (c *CSVWriter) Write(events []errors.Event) error {
+	if !c.header {
+		if err := c.w.Write(Columns); err != nil {
+			return errors.Wrap(err, "failed to write CSV header")
+		}
+		c.header = true
+	}
+	for _, event := range events {
+		if err := c.w.Write(NewRow(event).record()); err != nil {
+			return errors.Wrapf(err, "failed to write CSV row (%s)", event.Fingerprint)
+		}
+	}
+	c.w.Flush()
+	return errors.Wrap(c.w.Error(), "failed to flush CSV")
+}
+
+// Recorder is a capture handler which records events in memory.
+type Recorder struct {
+	// Max limits how many events are kept. When the limit is reached, the oldest events are discarded. If zero,
+	// there is no limit.
+	Max int
+
+	mu     sync.Mutex
+	events []errors.Event
+}
+
+// Capture is a capture handler, which records an event.
+func (r *Recorder) Capture(exception error, arg ...any) errors.CaptureID {
+	event := errors.NewEvent(exception, arg...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	if r.Max > 0 && len(r.events) > r.Max {
+		r.events = r.events[len(r.events)-r.Max:]
+	}
+	return ""
+}
+
+// Drain returns the events recorded, and forgets them.
+func (r *Recorder) Drain() []errors.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
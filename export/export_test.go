@@ -0,0 +1,55 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/export"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSV(t *testing.T) {
+	recorder := &export.Recorder{Max: 2}
+	errors.RegisterCapture("TestCSV", recorder.Capture)
+	defer errors.UnregisterCapture("TestCSV")
+
+	_ = errors.Alertf("discarded (%d)", 0)
+	_ = errors.Alertf("widget (%d) failed", 1)
+	_ = errors.Alert(errors.WithCode(errors.WithSeverity(errors.New("disk full"), errors.SeverityCritical), "DISK-1"))
+
+	buf := &bytes.Buffer{}
+	w := export.NewCSVWriter(buf)
+	assert.NoError(t, w.Write(recorder.Drain()))
+	assert.Empty(t, recorder.Drain())
+	assert.NoError(t, w.Write(nil)) // header is written only once
+
+	records, err := csv.NewReader(buf).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, export.Columns, records[0])
+		assert.Equal(t, "widget (1) failed", records[1][7])
+		assert.Equal(t, "1", records[1][8])
+		assert.Equal(t, "widget (%d) failed", records[1][10])
+		assert.Equal(t, "critical", records[2][3])
+		assert.Equal(t, "DISK-1", records[2][5])
+		assert.Equal(t, errors.Release, records[2][1])
+	}
+}
+
+func TestRow(t *testing.T) {
+	err := errors.New("TestRow")
+	captured := &export.Recorder{}
+	errors.RegisterCapture("TestRow", func(error, ...any) errors.CaptureID { return "first" })
+	err = errors.Alert(err)
+	errors.UnregisterCapture("TestRow")
+
+	captured.Capture(errors.Wrap(err, "again"))
+	rows := captured.Drain()
+	if assert.Len(t, rows, 1) {
+		row := export.NewRow(rows[0])
+		assert.Equal(t, "TestRow=first", row.ID)
+		assert.Equal(t, "error", row.Severity)
+	}
+}
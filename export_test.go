@@ -0,0 +1,44 @@
+package errors
+
+// Reopen undoes Close(), so that tests which follow can alert.
+func Reopen() {
+	pending.mu.Lock()
+	defer pending.mu.Unlock()
+	pending.closed = false
+
+	if q := workers.Load(); q != nil && q.stopped() {
+		workers.Store(newCaptureQueue(captureWorkers, captureQueueSize))
+	}
+}
+
+// stopped returns whether the workers have been stopped, see Close().
+func (q *captureQueue) stopped() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.closed
+}
+
+// CaptureWorkStopped returns whether the workers which invoke capture handlers have been stopped.
+func CaptureWorkStopped() bool {
+	q := workers.Load()
+	return q != nil && q.stopped()
+}
+
+// ForgetAlertOnce forgets which lines have called AlertOnce(), so that a test may be run more than once.
+func ForgetAlertOnce() {
+	alertedOnce.Range(func(pc, _ any) bool {
+		alertedOnce.Delete(pc)
+		return true
+	})
+}
+
+// SetCaptureQueue replaces the workers and queue of capture handlers, until the function returned is called.
+func SetCaptureQueue(n, size int) (restore func()) {
+	old := captureWork()
+	q := newCaptureQueue(n, size)
+	workers.Store(q)
+	return func() {
+		workers.Store(old)
+		q.close()
+	}
+}
@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// ContextExtractor returns values found in a context which describe errors that occur in that context, i.e. the ID
+// of the request being served. The values become annotations, see Annotate(). Named values may be returned as
+// Fields.
+type ContextExtractor func(ctx context.Context) []any
+
+type namedExtractor struct {
+	name      string
+	extractor ContextExtractor
+}
+
+var (
+	extractorMu sync.RWMutex
+	extractors  []namedExtractor // in order of registration
+)
+
+// RegisterContextExtractor adds an extractor which annotates errors produced by ErrorfCtx() and WrapCtx(), and
+// errors passed to AnnotateContext(). Extractors are typically registered by the package which puts the values
+// into contexts:
+//
+//	func init() {
+//	  errors.RegisterContextExtractor("request", func(ctx context.Context) []any {
+//	    if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+//	      return []any{errors.Fields{"request_id": id}}
+//	    }
+//	    return nil
+//	  })
+//	}
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	extractorMu.Lock()
+	defer extractorMu.Unlock()
+	for i := range extractors {
+		if extractors[i].name == name {
+			log.Panicf("context extractor (%q) already registered", name)
+		}
+	}
+	extractors = append(extractors, namedExtractor{name: name, extractor: extractor})
+}
+
+func UnregisterContextExtractor(name string) {
+	extractorMu.Lock()
+	defer extractorMu.Unlock()
+	for i := range extractors {
+		if extractors[i].name == name {
+			extractors = append(extractors[:i:i], extractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// AnnotateContext annotates an error with the values extracted from ctx by registered extractors. It returns nil
+// when the exception is nil, and returns the exception unchanged when no values are extracted.
+func AnnotateContext(ctx context.Context, exception error) error {
+	if isNil(exception, "AnnotateContext") {
+		return nil
+	}
+
+	extractorMu.RLock()
+	current := extractors
+	extractorMu.RUnlock()
+
+	var value []any
+	for _, e := range current {
+		value = append(value, e.extractor(ctx)...)
+	}
+	return Annotate(exception, value...)
+}
+
+// ErrorfCtx is like Errorf(), and annotates the error with values extracted from ctx, see AnnotateContext().
+func ErrorfCtx(ctx context.Context, format string, a ...any) error {
+	return AnnotateContext(ctx, Errorf(format, a...))
+}
+
+// WrapCtx is like Wrap(), and annotates the error with values extracted from ctx, see AnnotateContext(). It returns
+// nil when the exception is nil.
+func WrapCtx(ctx context.Context, exception error, message string) error {
+	if isNil(exception, "WrapCtx") {
+		return nil
+	}
+	return AnnotateContext(ctx, Wrap(exception, message))
+}
@@ -0,0 +1,54 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+type tenantKey struct{}
+
+type customer string
+
+func init() {
+	errors.RegisterContextExtractor("extract_test request", func(ctx context.Context) []any {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []any{errors.Fields{"request_id": id}}
+		}
+		return nil
+	})
+	errors.RegisterContextExtractor("extract_test tenant", func(ctx context.Context) []any {
+		if t, ok := ctx.Value(tenantKey{}).(customer); ok {
+			return []any{t}
+		}
+		return nil
+	})
+}
+
+func TestContextExtractor(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	ctx = context.WithValue(ctx, tenantKey{}, customer("acme"))
+
+	err := errors.ErrorfCtx(ctx, "widget (%d) not found", 42)
+	assert.Equal(t, "widget (42) not found", err.Error())
+	id, _ := errors.Value(err, "request_id")
+	assert.Equal(t, "req-1", id)
+	who, _ := errors.Annotation[customer](err)
+	assert.Equal(t, customer("acme"), who)
+
+	wrapped := errors.WrapCtx(ctx, errors.New("no rows"), "lookup failed")
+	assert.Equal(t, "lookup failed: no rows", wrapped.Error())
+	id, _ = errors.Value(wrapped, "request_id")
+	assert.Equal(t, "req-1", id)
+	assert.Nil(t, errors.WrapCtx(ctx, nil, "lookup failed"))
+
+	// nothing to extract
+	plain := errors.New("plain")
+	assert.Equal(t, plain, errors.AnnotateContext(context.Background(), plain))
+
+	assert.Panics(t, func() { errors.RegisterContextExtractor("extract_test tenant", nil) })
+}
@@ -0,0 +1,56 @@
+package errors
+
+// TestingT is the part of testing.TB used by FailOnAlert.
+type TestingT interface {
+	Helper()
+	Name() string
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// FailOnAlert registers a capture handler, for the duration of a test, which fails the test when an error is
+// alerted. So code paths which alert unexpectedly are found by tests, rather than by whoever is on call.
+//
+//	func TestImport(t *testing.T) {
+//	  errors.FailOnAlert(t, ErrDuplicateRow, errors.Code("IMP-7"))
+//	  ...
+//	}
+//
+// Alerts which are expected may be allowed. Each allowance is either an error, which allows alerts that wrap it
+// (see Is); a Code, which allows alerts with the code (see CodeOf); or a func(error) bool, which allows alerts for
+// which it returns true. FailOnAlert panics if passed an allowance of another type.
+//
+// Because capture handlers receive the alerts of all goroutines, FailOnAlert is not suited to parallel tests.
+func FailOnAlert(t TestingT, allow ...any) {
+	t.Helper()
+	for _, a := range allow {
+		switch a.(type) {
+		case error, Code, func(error) bool:
+		default:
+			panic(Errorf("FailOnAlert cannot allow alerts by (%T)", a))
+		}
+	}
+
+	provider := CaptureProvider("FailOnAlert " + t.Name())
+	RegisterCapture(provider, func(err error, _ ...any) CaptureID {
+		for _, a := range allow {
+			switch a := a.(type) {
+			case Code:
+				if CodeOf(err) == a {
+					return ""
+				}
+			case func(error) bool:
+				if a(err) {
+					return ""
+				}
+			case error:
+				if Is(err, a) {
+					return ""
+				}
+			}
+		}
+		t.Errorf("unexpected alert: %+v", err)
+		return "" // the error message is not changed by this handler
+	})
+	t.Cleanup(func() { UnregisterCapture(provider) })
+}
@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingT records failures, rather than failing the test.
+type recordingT struct {
+	*testing.T
+	failures []string
+}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+const errFailOnAlertExpected errors.String = "TestFailOnAlert expected"
+
+func TestFailOnAlert(t *testing.T) {
+	var rec *recordingT
+	t.Run("alerts", func(t *testing.T) {
+		rec = &recordingT{T: t}
+		errors.FailOnAlert(rec, errFailOnAlertExpected, errors.Code("FOA-1"), func(err error) bool {
+			return err.Error() == "allowed by func"
+		})
+
+		_ = errors.Alert(errors.Wrap(errFailOnAlertExpected, "wrapped"))
+		_ = errors.Alert(errors.WithCode(errors.New("coded"), "FOA-1"))
+		_ = errors.Alertf("allowed by func")
+		assert.Empty(t, rec.failures)
+
+		err := errors.Alertf("TestFailOnAlert unexpected")
+		assert.Equal(t, "TestFailOnAlert unexpected", err.Error(), "message should not change")
+		if assert.Len(t, rec.failures, 1) {
+			assert.Contains(t, rec.failures[0], "unexpected alert: TestFailOnAlert unexpected")
+		}
+	})
+
+	// the handler is unregistered when the test finishes
+	_ = errors.Alertf("TestFailOnAlert after")
+	assert.Len(t, rec.failures, 1)
+
+	assert.Panics(t, func() { errors.FailOnAlert(t, "not an allowance") })
+}
@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldPath locates the field which could not be mapped, when data is scanned or unmarshaled into a struct.
+type FieldPath struct {
+	// Type is the name of the destination type, i.e. "Order".
+	Type string
+
+	// Path lists the fields and indexes leading to the offending field, i.e. ["Items", "[3]", "SKU"].
+	Path []string
+}
+
+// String joins the type and path, i.e. "Order.Items[3].SKU".
+func (f FieldPath) String() string {
+	b := &strings.Builder{}
+	b.WriteString(f.Type)
+	for _, segment := range f.Path {
+		if b.Len() > 0 && !strings.HasPrefix(segment, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+type (
+	// fieldSegment is the annotation type for one step of a FieldPath.
+	fieldSegment string
+
+	// fieldType is the annotation type for the destination type of a FieldPath.
+	fieldType string
+)
+
+// AtField returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and records that it concerns a field, or an element when field is an index such as "[3]". Code which maps data
+// recursively annotates an error at each level, as it returns, so the outermost field comes first in the path.
+//
+//	for i := range items {
+//	  if err := scanItem(&items[i], row); err != nil {
+//	    return errors.AtField(errors.AtField(err, fmt.Sprintf("[%d]", i)), "Items")
+//	  }
+//	}
+func AtField(exception error, field string) error {
+	return Annotate(exception, fieldSegment(field))
+}
+
+// IntoType returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and records the type of the destination which could not be mapped. Pointers are dereferenced, so dest may be
+// the value passed to a scan or unmarshal function.
+func IntoType(exception error, dest any) error {
+	t := reflect.TypeOf(dest)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := "<nil>"
+	if t != nil {
+		name = t.Name()
+		if name == "" {
+			name = t.String()
+		}
+	}
+	return Annotate(exception, fieldType(name))
+}
+
+// FieldPathOf returns the location of the field which could not be mapped, if AtField() or IntoType() annotated
+// the error.
+func FieldPathOf(exception error) (FieldPath, bool) {
+	var (
+		result FieldPath
+		found  bool
+	)
+	Walk(exception, func(ex error) bool {
+		a, ok := ex.(*annotated)
+		if !ok {
+			return true
+		}
+		for _, v := range a.value {
+			switch v := v.(type) {
+			case fieldSegment:
+				result.Path = append(result.Path, string(v))
+				found = true
+			case fieldType:
+				if result.Type == "" {
+					result.Type = string(v)
+				}
+				found = true
+			}
+		}
+		return true
+	})
+	return result, found
+}
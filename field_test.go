@@ -0,0 +1,54 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldOrder struct {
+	Items []fieldItem
+}
+
+type fieldItem struct {
+	SKU string
+}
+
+func scanItem(item *fieldItem, sku any) error {
+	s, ok := sku.(string)
+	if !ok {
+		return errors.AtField(errors.Errorf("cannot scan (%T) into string", sku), "SKU")
+	}
+	item.SKU = s
+	return nil
+}
+
+func scanOrder(order *fieldOrder, skus []any) error {
+	order.Items = make([]fieldItem, len(skus))
+	for i := range skus {
+		if err := scanItem(&order.Items[i], skus[i]); err != nil {
+			return errors.IntoType(errors.AtField(errors.AtField(err, fmt.Sprintf("[%d]", i)), "Items"), order)
+		}
+	}
+	return nil
+}
+
+func TestFieldPath(t *testing.T) {
+	err := scanOrder(&fieldOrder{}, []any{"a", "b", "c", 4})
+	assert.Equal(t, "cannot scan (int) into string", err.Error())
+
+	path, ok := errors.FieldPathOf(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "fieldOrder", path.Type)
+		assert.Equal(t, []string{"Items", "[3]", "SKU"}, path.Path)
+		assert.Equal(t, "fieldOrder.Items[3].SKU", path.String())
+	}
+
+	_, ok = errors.FieldPathOf(errors.New("TestFieldPath"))
+	assert.False(t, ok)
+	assert.Equal(t, "[]int", errors.FieldPath{Type: "[]int"}.String())
+	assert.Equal(t, "[2].Name", errors.FieldPath{Path: []string{"[2]", "Name"}}.String())
+}
@@ -0,0 +1,209 @@
+package errors
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/memsql/errors/internal/redact"
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// packagePrefix begins the name of every function in this package.
+const packagePrefix = "github.com/memsql/errors."
+
+// FingerprintFrames is how many frames of the stack where an error originated determine its fingerprint: the
+// origin, and the frames of the app which called it. One frame groups errors by the function where they
+// originated, wherever it was called from; more frames split the errors of a shared helper by its callers.
+var FingerprintFrames = 3
+
+// Fingerprint produces a key which is the same for errors that are likely to have the same cause. Capture
+// handlers may use it to group or de-duplicate errors, and logs and metrics may record it, so that the same key
+// finds an error wherever it was reported.
+//
+// The fingerprint is computed from the templates of the error message (see Error.Template) and the top frames of
+// the stack where the error originated, that is the origin and its callers in the app (see FingerprintFrames).
+// Frames of this package, and of frameworks (see SetFrameClassifier), are not considered. When an error has no
+// template, its message is used instead, redacted as by Redact(): without text in parentheses, and without the
+// identifiers of clusters, workspaces and databases (see Message Conventions, in the package documentation). So
+// the fingerprint does not change when the dynamic parts of a message change, or when unrelated code is added to
+// a source file. Functions, rather than lines, identify frames, and the key is a hash, so fingerprints are stable
+// from one build and one machine to another.
+//
+// When an error has been given explicit parts by WithFingerprint(), the fingerprint is computed from those parts
+// alone.
+func Fingerprint(exception error) string {
+	if exception == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	if parts, ok := FingerprintParts(exception); ok {
+		for _, part := range parts {
+			_, _ = io.WriteString(h, part)
+			_, _ = io.WriteString(h, "\n")
+		}
+		return fmt.Sprintf("%016x", h.Sum64())
+	}
+
+	_, _ = io.WriteString(h, groupingKey(exception))
+	for _, frame := range fingerprintFrames(exception) {
+		_, _ = io.WriteString(h, "\n")
+		_, _ = io.WriteString(h, symbol.FuncName(frame))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// fingerprint is the annotation type recording explicit grouping parts of an error.
+type fingerprint []string
+
+// WithFingerprint returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and is grouped by the parts passed in, rather than by automatic fingerprinting. Use it for known
+// errors which automatic grouping merges with unrelated errors, or splits into many groups.
+//
+//	return errors.WithFingerprint(err, string(CodeOf(err)), table)
+//
+// Fingerprint() honors the outermost parts. Capture handlers for providers which accept a list of grouping parts
+// may use FingerprintParts() instead.
+func WithFingerprint(exception error, parts ...string) error {
+	if len(parts) == 0 {
+		return Safe(exception)
+	}
+	return Annotate(exception, fingerprint(parts))
+}
+
+// FingerprintParts returns the grouping parts of an error, if any were specified by WithFingerprint().
+func FingerprintParts(exception error) ([]string, bool) {
+	parts, ok := Annotation[fingerprint](exception)
+	return parts, ok
+}
+
+// groupingKey returns the static parts of an error message. These are the templates of each *Error in the tree,
+// along with the redacted messages of other errors they wrap. If no error in the tree has a template, it is the
+// redacted message of the error.
+func groupingKey(exception error) string {
+	var (
+		part      []string
+		templated bool
+		covered   string // message of the last templated error; errors it is built upon have the same message
+	)
+	Walk(exception, func(ex error) bool {
+		if e, ok := ex.(*Error); ok && usableTemplate(e.format) {
+			part = append(part, e.format)
+			templated = true
+			covered = e.Error()
+			return true
+		}
+		if isLeaf(ex) && ex.Error() != covered {
+			part = append(part, redactedKey(exception, ex.Error()))
+		}
+		return true
+	})
+	if !templated {
+		return redactedKey(exception, exception.Error())
+	}
+	return strings.Join(part, "\n")
+}
+
+// redactedKey returns a message of an error, less any text in parentheses and identifiers of resources.
+func redactedKey(exception error, msg string) string {
+	return scrubResources(exception, redact.Parens(msg))
+}
+
+// usableTemplate returns false for templates with no static text, i.e. Errorf("%s", text).
+func usableTemplate(format string) bool {
+	switch format {
+	case "", "%s", "%v", "%+v", "%w", "%s: %w":
+		return false
+	}
+	return true
+}
+
+// isLeaf returns true for an error which wraps no other errors.
+func isLeaf(exception error) bool {
+	switch e := exception.(type) {
+	case interface{ Unwrap() []error }:
+		return len(e.Unwrap()) == 0
+	case interface{ Unwrap() error }:
+		return e.Unwrap() == nil
+	}
+	return true
+}
+
+// originStack returns the innermost stack trace of an error, that is the stack where the error originated, less
+// any frames skipped by WithOriginSkip().
+func originStack(exception error) StackTrace {
+	var stack StackTrace
+	Walk(exception, func(ex error) bool {
+		if tracer, ok := ex.(StackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+		return true
+	})
+	if skip, ok := Annotation[originSkip](exception); ok {
+		for len(stack) > 1 && strings.HasPrefix(symbol.FuncName(stack[0]), packagePrefix) {
+			stack = stack[1:] // frames of this package are not counted
+		}
+		for ; skip > 0 && len(stack) > 1; skip-- {
+			stack = stack[1:]
+		}
+	}
+	return stack
+}
+
+// origin returns the name of the function where an error originated, see originFrame().
+func origin(exception error) string {
+	frame, ok := originFrame(exception)
+	if !ok {
+		return ""
+	}
+	return symbol.FuncName(frame)
+}
+
+// originFrame returns the frame of the function where an error originated, see origin(). That is the innermost
+// frame of the app, or if there is none, the innermost frame which is not of a framework (see ClassifyFrame).
+func originFrame(exception error) (pkgerrors.Frame, bool) {
+	var (
+		fallback pkgerrors.Frame
+		found    bool
+	)
+	for _, frame := range originStack(exception) {
+		switch ClassifyFrame(frame) {
+		case FrameApp:
+			return frame, true
+		case FrameFramework:
+			continue
+		}
+		if !found {
+			fallback, found = frame, true
+		}
+	}
+	return fallback, found
+}
+
+// fingerprintFrames returns the frames which determine a fingerprint: the origin frame (see originFrame), followed
+// by up to FingerprintFrames-1 frames of the app which called it.
+func fingerprintFrames(exception error) []pkgerrors.Frame {
+	frame, ok := originFrame(exception)
+	if !ok {
+		return nil
+	}
+	result := []pkgerrors.Frame{frame}
+	if ClassifyFrame(frame) != FrameApp {
+		return result
+	}
+	stack := originStack(exception)
+	i := 0
+	for i < len(stack) && stack[i] != frame {
+		i++
+	}
+	for i++; i < len(stack) && len(result) < FingerprintFrames; i++ {
+		if ClassifyFrame(stack[i]) == FrameApp {
+			result = append(result, stack[i])
+		}
+	}
+	return result
+}
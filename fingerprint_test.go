@@ -0,0 +1,80 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func fingerprintFailure(id int) error {
+	return errors.Errorf("widget (%d) failed", id)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, "", errors.Fingerprint(nil))
+
+	// dynamic parts of the message do not affect the fingerprint
+	assert.Equal(t, errors.Fingerprint(fingerprintFailure(1)), errors.Fingerprint(fingerprintFailure(2)))
+
+	// the same text from different origins has a different fingerprint
+	assert.NotEqual(t, errors.Fingerprint(fingerprintFailure(1)), errors.Fingerprint(errors.Errorf("widget (%d) failed", 1)))
+
+	// static text matters
+	assert.NotEqual(t, errors.Fingerprint(errors.New("one")), errors.Fingerprint(errors.New("two")))
+
+	// identifiers of resources do not
+	workspace := func(id errors.WorkspaceID) error {
+		return errors.WithWorkspace(errors.New("workspace "+string(id)+" suspended"), id)
+	}
+	assert.Equal(t, errors.Fingerprint(workspace("ws-1")), errors.Fingerprint(workspace("ws-2")))
+}
+
+func fingerprintCallerA() error { return fingerprintFailure(1) }
+
+func fingerprintCallerB() error { return fingerprintFailure(1) }
+
+func TestFingerprintFrames(t *testing.T) {
+	defer func(frames int) { errors.FingerprintFrames = frames }(errors.FingerprintFrames)
+
+	// the callers of the origin split the errors of a shared function
+	assert.NotEqual(t, errors.Fingerprint(fingerprintCallerA()), errors.Fingerprint(fingerprintCallerB()))
+	assert.Equal(t, errors.Fingerprint(fingerprintCallerA()), errors.Fingerprint(fingerprintCallerA()))
+
+	errors.FingerprintFrames = 1
+	assert.Equal(t, errors.Fingerprint(fingerprintCallerA()), errors.Fingerprint(fingerprintCallerB()))
+}
+
+func TestTemplate(t *testing.T) {
+	err := errors.Errorf("user (%d) not found in %s", 42, "us-east-1")
+	assert.Equal(t, "user (%d) not found in %s", err.Template())
+
+	var wrapped *errors.Error
+	assert.True(t, errors.As(errors.Wrap(err, "failed to log in"), &wrapped))
+	assert.Equal(t, "failed to log in: %w", wrapped.Template())
+
+	// the dynamic parts of a message do not affect the fingerprint, with or without parentheses
+	region := func(r string) error { return errors.Wrap(errors.Errorf("user not found in %s", r), "failed") }
+	assert.Equal(t, errors.Fingerprint(region("us-east-1")), errors.Fingerprint(region("eu-west-2")))
+	assert.NotEqual(t, errors.Fingerprint(region("us-east-1")), errors.Fingerprint(errors.Wrap(region("us-east-1"), "again")))
+}
+
+func TestWithFingerprint(t *testing.T) {
+	assert.Nil(t, errors.WithFingerprint(nil, "part"))
+
+	// automatic fingerprinting splits these errors, as they originate in different functions
+	one := errors.WithFingerprint(fingerprintFailure(1), "widget", "table one")
+	two := errors.WithFingerprint(errors.New("something else"), "widget", "table one")
+	assert.Equal(t, errors.Fingerprint(one), errors.Fingerprint(errors.Wrap(two, "wrapped")))
+
+	// and merges these
+	three := errors.WithFingerprint(fingerprintFailure(3), "widget", "table two")
+	assert.NotEqual(t, errors.Fingerprint(one), errors.Fingerprint(three))
+
+	parts, ok := errors.FingerprintParts(errors.WithFingerprint(three, "outermost"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"outermost"}, parts)
+
+	_, ok = errors.FingerprintParts(fingerprintFailure(4))
+	assert.False(t, ok)
+}
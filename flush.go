@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// ErrCaptureClosed is the cause of capture handlers not being invoked, after Close().
+const ErrCaptureClosed = String("capture closed")
+
+// pending tracks capture handlers which have not returned, including handlers which an alert stopped waiting for.
+var pending = &captures{}
+
+type captures struct {
+	mu     sync.Mutex
+	n      int
+	idle   chan struct{} // closed when n is zero; nil until a handler starts
+	closed bool
+}
+
+// Flush waits until all capture handlers have returned, or ctx is done. Alerts wait for handlers at most
+// CaptureTimeout, and handlers which are slower continue to run after the alert returns; so before a program
+// exits, it should flush, or their captures may be lost.
+//
+//	defer errors.Flush(ctx)
+//
+// It returns an error, with the cause of ctx being done, if handlers are still running.
+func Flush(ctx context.Context) error {
+	pending.mu.Lock()
+	if pending.n == 0 {
+		pending.mu.Unlock()
+		return nil
+	}
+	idle := pending.idle
+	pending.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		pending.mu.Lock()
+		n := pending.n
+		pending.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		return Errorf("capture handlers (%d) still running: %w", n, context.Cause(ctx))
+	}
+}
+
+// Close stops capture handlers from being invoked for later alerts, which are logged instead, and flushes, waiting
+// at most CaptureTimeout (see Flush). Then it stops the workers which invoke handlers. Call it when a program shuts
+// down, after which alerts would be lost.
+func Close() error {
+	pending.mu.Lock()
+	pending.closed = true
+	pending.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), CaptureTimeout)
+	defer cancel()
+	err := Flush(ctx)
+	stopCaptureWork()
+	return err
+}
+
+// start counts n handlers about to be invoked. It returns false, and counts nothing, after Close().
+func (c *captures) start(n int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	if c.n == 0 && n > 0 {
+		c.idle = make(chan struct{})
+	}
+	c.n += n
+	return true
+}
+
+// done counts a handler which has returned.
+func (c *captures) done() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n--
+	if c.n == 0 {
+		close(c.idle)
+	}
+}
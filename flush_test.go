@@ -0,0 +1,54 @@
+package errors_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlush(t *testing.T) {
+	assert.NoError(t, errors.Flush(context.Background()))
+
+	var finished atomic.Int32
+	errors.RegisterCapture("TestFlush", func(error, ...any) errors.CaptureID {
+		time.Sleep(50 * time.Millisecond)
+		finished.Add(1)
+		return "TestFlush"
+	})
+	defer errors.UnregisterCapture("TestFlush")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, failed := errors.AlertSync(ctx, errors.New("TestFlush"))
+	assert.Len(t, failed, 1, "alert should not wait for the handler")
+	assert.Zero(t, finished.Load())
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, errors.Flush(ctx), context.DeadlineExceeded)
+
+	assert.NoError(t, errors.Flush(context.Background()))
+	assert.Equal(t, int32(1), finished.Load(), "flush should wait for the handler")
+}
+
+func TestClose(t *testing.T) {
+	captured := 0
+	errors.RegisterCapture("TestClose", func(error, ...any) errors.CaptureID {
+		captured++
+		return "TestClose"
+	})
+	defer errors.UnregisterCapture("TestClose")
+
+	errors.Alert(errors.New("TestClose"))
+	assert.NoError(t, errors.Close())
+	defer errors.Reopen()
+	assert.True(t, errors.CaptureWorkStopped())
+
+	_, failed := errors.AlertSync(context.Background(), errors.New("TestClose closed"))
+	assert.ErrorIs(t, failed["TestClose"], errors.ErrCaptureClosed)
+	assert.Equal(t, 1, captured)
+}
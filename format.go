@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// verboseDefault is set by SetVerboseDefault().
+var verboseDefault atomic.Bool
+
+// SetVerboseDefault makes "%v" format errors of this package as "%+v" does, that is with the details of the tree
+// of wrapped errors, including where each originated. It is intended for debug and staging builds, where a log line
+// which used "%v" would otherwise lose the stack. The output of "%v" then spans several lines; "%s" and Error()
+// still produce only the message.
+func SetVerboseDefault(verbose bool) {
+	verboseDefault.Store(verbose)
+}
+
+// verbose returns whether an error is formatted verbosely, given the flags of its format.
+func verbose(f fmt.State) bool {
+	return f.Flag('+') || verboseDefault.Load()
+}
+
+// writeVerbose writes the verbose details of an error, as produced by "%+v". After the message, and the runbook of
+// the error if it has one, it writes the details of each error in the tree of wrapped errors which has them,
+// typically a stack trace. The details are written in a deterministic order, from outermost to innermost error
+// (and depth first, in the order joined errors were joined). Each is labeled with the message of the error that
+// provided it.
+//
+// Parts of stack traces that are internal to this package are omitted, as they add several lines of unimportant
+// information that distracts from the real points of interest in the stack.
+func writeVerbose(w io.Writer, message string, exception error) {
+	_, _ = io.WriteString(w, message) // if this fails, not much we can do
+
+	// the runbook follows the message, so whoever reads the error finds how to respond before the details
+	if runbook := RunbookOf(exception); runbook != "" {
+		_, _ = fmt.Fprintf(w, "\nrunbook: %s", runbook)
+	}
+
+	Walk(exception, func(ex error) bool {
+		var details string
+		switch e := ex.(type) {
+		case *annotated:
+			// a submission includes the stack of code which submitted the task, see Envelope; and progress shows
+			// how far a long operation got
+			for _, v := range e.value {
+				switch v := v.(type) {
+				case Submission:
+					_, _ = fmt.Fprintf(w, "\n--- %s%s", v, stackDetails(v.Stack))
+				case Progress:
+					_, _ = fmt.Fprintf(w, "\n--- progress: %s", v)
+				}
+			}
+			return true
+		case *Error, *Captured, *joinError, *noStack:
+			// types in this package add no details, other than what appears in the message
+			return true
+		case StackTracer:
+			details = stackDetails(e.StackTrace())
+		case fmt.Formatter:
+			if Unwrap(ex) != nil {
+				// the details of wrapped errors are written when we reach them, don't write them twice
+				return true
+			}
+			details = formatterDetails(message, e)
+		}
+		if details != "" {
+			_, _ = fmt.Fprintf(w, "\n--- %q%s", ex.Error(), details)
+		}
+		return true
+	})
+}
+
+// stackDetails formats a stack trace, omitting leading frames within this package. See renderStack().
+func stackDetails(stack StackTrace) string {
+	for len(stack) > 0 && strings.HasPrefix(symbol.FuncName(stack[0]), packagePrefix) {
+		stack = stack[1:]
+	}
+	return renderStack(stack)
+}
+
+// formatterDetails produces the verbose output of an error that implements fmt.Formatter. It omits lines that
+// repeat text which already appears in the message.
+func formatterDetails(message string, formatter fmt.Formatter) string {
+	buf := &bytes.Buffer{}
+	_, _ = fmt.Fprintf(buf, "%+v", formatter)
+
+	details := &strings.Builder{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(message, line) {
+			continue // line is redundant, a portion of the error message
+		}
+		details.WriteString("\n" + line)
+	}
+	return details.String()
+}
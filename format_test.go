@@ -0,0 +1,99 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func formatFirst() error  { return errors.New("first") }
+func formatSecond() error { return pkgerrors.New("second") }
+
+// TestFormatOrder checks that "%+v" includes details of every error in a tree, outermost first and joined errors
+// in the order they were joined, regardless of how the errors were wrapped.
+func TestFormatOrder(t *testing.T) {
+	first := formatFirst()
+	second := pkgerrors.Wrap(formatSecond(), "wrapped")
+	joined := errors.Join(fmt.Errorf("std: %w", first), second)
+	outer := errors.Errorf("outer: %w", joined)
+
+	for i := 0; i < 10; i++ {
+		verbose := fmt.Sprintf("%+v", outer)
+		assert.True(t, strings.HasPrefix(verbose, outer.Error()+"\n"), verbose)
+
+		f := strings.Index(verbose, "formatFirst")
+		s := strings.Index(verbose, "formatSecond")
+		if assert.True(t, f > 0, "missing stack of first error: %s", verbose) &&
+			assert.True(t, s > 0, "missing stack of second error: %s", verbose) {
+			assert.Less(t, f, s, "stacks out of order: %s", verbose)
+		}
+		assert.Contains(t, verbose, `--- "first"`)
+		assert.Contains(t, verbose, `--- "second"`)
+		assert.Equal(t, 1, strings.Count(verbose, "formatFirst"), "stack should appear once: %s", verbose)
+		assert.NotContains(t, verbose, "github.com/memsql/errors.New\n", "internal frames should be omitted")
+	}
+}
+
+func TestFormatCaptured(t *testing.T) {
+	errors.RegisterCapture("TestFormatCaptured", func(error, ...any) errors.CaptureID { return "captured-id" })
+	defer errors.UnregisterCapture("TestFormatCaptured")
+
+	captured := errors.Alert(formatFirst())
+	verbose := fmt.Sprintf("%+v", captured)
+	assert.True(t, strings.HasPrefix(verbose, "first [captured-id]\n"), verbose)
+	assert.Less(t, strings.Index(verbose, "TestFormatCaptured"), strings.Index(verbose, "formatFirst"),
+		"stack of alert should precede stack of error: %s", verbose)
+}
+
+// TestFormatRunbook checks that the runbook of an error follows its message, ahead of stack traces.
+func TestFormatRunbook(t *testing.T) {
+	err := errors.Errorf("outer: %w", errors.WithRunbook(formatFirst(), "https://runbooks.example.com/first"))
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(verbose, "outer: first\nrunbook: https://runbooks.example.com/first\n"), verbose)
+	assert.Equal(t, 1, strings.Count(verbose, "runbook:"), verbose)
+	assert.Equal(t, "outer: first", fmt.Sprintf("%v", err))
+}
+
+// TestFormatOnce checks that the details of an error are not repeated by errors which wrap it.
+func TestFormatOnce(t *testing.T) {
+	wrapped := pkgerrors.Wrap(formatSecond(), "wrapped")
+	verbose := fmt.Sprintf("%+v", errors.Errorf("outer: %w", wrapped))
+	assert.Equal(t, 1, strings.Count(verbose, "formatSecond"), "stack should appear once: %s", verbose)
+}
+
+// TestFormatStack checks that stack traces show module-relative files, in aligned columns, with frames of the main
+// module marked.
+func TestFormatStack(t *testing.T) {
+	verbose := fmt.Sprintf("%+v", errors.Wrap(formatFirst(), "outer"))
+	lines := strings.Split(verbose, "\n")
+	if !assert.Greater(t, len(lines), 3, verbose) {
+		return
+	}
+	assert.Equal(t, "outer: first", lines[0])
+	assert.Equal(t, `--- "first"`, lines[1])
+	assert.Regexp(t, `^  \* github.com/memsql/errors_test.formatFirst +format_test.go:\d+$`, lines[2])
+	assert.Regexp(t, `^  \* github.com/memsql/errors_test.TestFormatStack +format_test.go:\d+$`, lines[3])
+	assert.Regexp(t, `^    testing.tRunner +testing/testing.go:\d+$`, lines[4], "frames outside the main module are not marked")
+
+	column := strings.Index(lines[2], "format_test.go")
+	for _, line := range lines[3:] {
+		assert.Equal(t, column, strings.LastIndex(line, "  ")+2, "columns should be aligned: %s", verbose)
+	}
+}
+
+func TestSetVerboseDefault(t *testing.T) {
+	err := errors.Wrap(formatFirst(), "wrapped")
+	assert.Equal(t, "wrapped: first", fmt.Sprintf("%v", err))
+
+	errors.SetVerboseDefault(true)
+	defer errors.SetVerboseDefault(false)
+	assert.Equal(t, fmt.Sprintf("%+v", err), fmt.Sprintf("%v", err))
+	assert.Contains(t, fmt.Sprintf("%v", err), "formatFirst")
+	assert.Contains(t, fmt.Sprintf("%v", errors.Annotate(err, "value")), "formatFirst")
+	assert.Equal(t, "wrapped: first", fmt.Sprintf("%s", err))
+}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// FrameClass is the origin of the code of a stack frame.
+type FrameClass int
+
+const (
+	// FrameApp is code of the program itself, that is of its main module.
+	FrameApp FrameClass = iota + 1
+
+	// FrameFramework is code which calls, or is called by, the program but is rarely of interest when looking for
+	// the cause of an error, i.e. this package, or middleware. Verbose output omits framework frames.
+	FrameFramework
+
+	// FrameStdlib is code of the standard library.
+	FrameStdlib
+
+	// FrameVendor is code of other modules the program depends on.
+	FrameVendor
+)
+
+func (c FrameClass) String() string {
+	switch c {
+	case FrameApp:
+		return "app"
+	case FrameFramework:
+		return "framework"
+	case FrameStdlib:
+		return "stdlib"
+	case FrameVendor:
+		return "vendor"
+	default:
+		return "unknown"
+	}
+}
+
+// FrameClassifier determines the class of a frame, given the full name of its function, i.e.
+// "github.com/memsql/errors.New", and the path of its file.
+type FrameClassifier func(function, file string) FrameClass
+
+// classifier is set by SetFrameClassifier().
+var classifier atomic.Pointer[FrameClassifier]
+
+// SetFrameClassifier replaces how stack frames are classified. The classification is shared by everything which
+// considers frames: verbose output ("%+v") marks frames of the app and omits frames of frameworks, Fingerprint()
+// prefers the innermost frame of the app as the origin of an error, and capture handlers may use ClassifyFrame()
+// to tell providers which frames are of interest.
+//
+// A classifier typically defers to DefaultFrameClassifier for frames it does not recognize:
+//
+//	errors.SetFrameClassifier(func(function, file string) errors.FrameClass {
+//	  if strings.HasPrefix(function, "example.com/app/middleware.") {
+//	    return errors.FrameFramework
+//	  }
+//	  return errors.DefaultFrameClassifier(function, file)
+//	})
+//
+// Pass nil to restore DefaultFrameClassifier.
+func SetFrameClassifier(f FrameClassifier) {
+	if f == nil {
+		classifier.Store(nil)
+		return
+	}
+	classifier.Store(&f)
+}
+
+// DefaultFrameClassifier classifies frames of this package as framework, frames of packages without a domain in
+// their import path as stdlib, frames of the main module as app, and others as vendor.
+func DefaultFrameClassifier(function, _ string) FrameClass {
+	pkg := symbol.Package(function)
+	switch {
+	case strings.HasPrefix(function, packagePrefix):
+		return FrameFramework
+	case pkg == "main":
+		return FrameApp
+	case !strings.Contains(strings.SplitN(pkg, "/", 2)[0], "."):
+		if main := symbol.MainModule(); main != "" && symbol.InModule(pkg, main) {
+			return FrameApp // a main module without a domain, i.e. in a test
+		}
+		return FrameStdlib
+	case symbol.InModule(pkg, symbol.MainModule()):
+		return FrameApp
+	default:
+		return FrameVendor
+	}
+}
+
+// ClassifyFrame returns the class of a stack frame, as determined by the classifier set by SetFrameClassifier().
+func ClassifyFrame(frame pkgerrors.Frame) FrameClass {
+	pc := uintptr(frame) - 1 // a frame is the program counter + 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return FrameVendor
+	}
+	file, _ := fn.FileLine(pc)
+	if f := classifier.Load(); f != nil {
+		return (*f)(fn.Name(), file)
+	}
+	return DefaultFrameClassifier(fn.Name(), file)
+}
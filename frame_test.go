@@ -0,0 +1,52 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func frameFailure() error { return errors.New("TestFrameClassifier") }
+
+func TestFrameClassifier(t *testing.T) {
+	assert.Equal(t, errors.FrameApp, errors.DefaultFrameClassifier("github.com/memsql/errors_test.TestFrameClassifier", ""))
+	assert.Equal(t, errors.FrameApp, errors.DefaultFrameClassifier("main.main", ""))
+	assert.Equal(t, errors.FrameFramework, errors.DefaultFrameClassifier("github.com/memsql/errors.New", ""))
+	assert.Equal(t, errors.FrameStdlib, errors.DefaultFrameClassifier("net/http.(*conn).serve", ""))
+	assert.Equal(t, errors.FrameVendor, errors.DefaultFrameClassifier("github.com/pkg/errors.New", ""))
+	assert.Equal(t, "vendor", errors.FrameVendor.String())
+
+	err := errors.Wrap(frameFailure(), "wrapped")
+	var tracer errors.StackTracer
+	if assert.True(t, errors.As(err, &tracer)) {
+		classes := map[errors.FrameClass]bool{}
+		for _, frame := range tracer.StackTrace() {
+			classes[errors.ClassifyFrame(frame)] = true
+		}
+		assert.Equal(t, map[errors.FrameClass]bool{errors.FrameApp: true, errors.FrameFramework: true, errors.FrameStdlib: true}, classes)
+	}
+	verbose := fmt.Sprintf("%+v", err)
+	assert.Contains(t, verbose, "testing.tRunner")
+	fingerprint := errors.Fingerprint(err)
+
+	// treat the test function itself as framework
+	errors.SetFrameClassifier(func(function, file string) errors.FrameClass {
+		if strings.HasSuffix(function, ".frameFailure") || strings.HasPrefix(function, "testing.") {
+			return errors.FrameFramework
+		}
+		return errors.DefaultFrameClassifier(function, file)
+	})
+	defer errors.SetFrameClassifier(nil)
+
+	verbose = fmt.Sprintf("%+v", err)
+	assert.NotContains(t, verbose, "frameFailure", "framework frames should be omitted")
+	assert.NotContains(t, verbose, "testing.tRunner", "framework frames should be omitted")
+	assert.Contains(t, verbose, "(1 framework frames omitted)")
+	assert.NotEqual(t, fingerprint, errors.Fingerprint(err), "origin should be the innermost frame of the app")
+
+	errors.SetFrameClassifier(nil)
+	assert.Equal(t, fingerprint, errors.Fingerprint(err))
+}
@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// globalAnnotations is set by SetGlobalAnnotations().
+var globalAnnotations atomic.Pointer[[]any]
+
+// SetGlobalAnnotations sets values which annotate every alerted error (see Annotate), i.e. the name and version of a
+// service, and the host where it runs:
+//
+//	errors.SetGlobalAnnotations(errors.Fields{"service": "billing", "version": version, "host": hostname})
+//
+// Capture handlers find the values as they find any annotation, i.e. with Annotations() or Annotation(). Values
+// of the error take priority: a global value is not added when the error already has an annotation of the same
+// type, or for Fields, a named value of the same name. Calling SetGlobalAnnotations replaces the values set
+// before; calling it with no values removes them.
+func SetGlobalAnnotations(value ...any) {
+	if len(value) == 0 {
+		globalAnnotations.Store(nil)
+		return
+	}
+	value = append([]any(nil), value...)
+	globalAnnotations.Store(&value)
+}
+
+// GlobalAnnotations returns the values set by SetGlobalAnnotations().
+func GlobalAnnotations() []any {
+	if value := globalAnnotations.Load(); value != nil {
+		return append([]any(nil), *value...)
+	}
+	return nil
+}
+
+// applyGlobalAnnotations annotates an alerted error with the global values it does not already have.
+func applyGlobalAnnotations(exception error) error {
+	global := globalAnnotations.Load()
+	if global == nil {
+		return exception
+	}
+
+	has := map[reflect.Type]bool{}
+	Walk(exception, func(ex error) bool {
+		if e, ok := ex.(*annotated); ok {
+			for _, v := range e.value {
+				has[reflect.TypeOf(v)] = true
+			}
+		}
+		return true
+	})
+	named := Annotations(exception)
+
+	var value []any
+	for _, v := range *global {
+		if fields, ok := v.(Fields); ok {
+			missing := Fields{}
+			for key := range fields {
+				if _, exists := named[key]; !exists {
+					missing[key] = fields[key]
+				}
+			}
+			if len(missing) > 0 {
+				value = append(value, missing)
+			}
+		} else if !has[reflect.TypeOf(v)] {
+			value = append(value, v)
+		}
+	}
+	return Annotate(exception, value...)
+}
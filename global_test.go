@@ -0,0 +1,43 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type release string
+
+func TestGlobalAnnotations(t *testing.T) {
+	var captured []error
+	errors.RegisterCapture("TestGlobalAnnotations", func(err error, _ ...any) errors.CaptureID {
+		captured = append(captured, err)
+		return "TestGlobalAnnotations"
+	})
+	defer errors.UnregisterCapture("TestGlobalAnnotations")
+
+	errors.SetGlobalAnnotations(errors.Fields{"service": "billing", "host": "db-1"}, release("v1.2.3"))
+	defer errors.SetGlobalAnnotations()
+	assert.Len(t, errors.GlobalAnnotations(), 2)
+
+	errors.Alert(errors.New("TestGlobalAnnotations"))
+	errors.Alert(errors.Annotate(errors.New("TestGlobalAnnotations"), errors.Fields{"host": "db-2"}, release("v2")))
+	if assert.Len(t, captured, 2) {
+		assert.Equal(t, map[string]any{"service": "billing", "host": "db-1"}, errors.Annotations(captured[0]))
+		r, _ := errors.Annotation[release](captured[0])
+		assert.Equal(t, release("v1.2.3"), r)
+
+		assert.Equal(t, map[string]any{"service": "billing", "host": "db-2"}, errors.Annotations(captured[1]),
+			"values of the error have priority")
+		r, _ = errors.Annotation[release](captured[1])
+		assert.Equal(t, release("v2"), r)
+	}
+
+	errors.SetGlobalAnnotations()
+	assert.Nil(t, errors.GlobalAnnotations())
+	errors.Alert(errors.New("TestGlobalAnnotations"))
+	if assert.Len(t, captured, 3) {
+		assert.Empty(t, errors.Annotations(captured[2]))
+	}
+}
@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"context"
+)
+
+// CaptureHandler is a capture handler which is passed an Event, rather than the error and its arguments, see
+// RegisterCaptureV2(). The context is done when the alert stops waiting for the handler, see CaptureTimeout and
+// AlertSync(). A handler which returns an error has the result CaptureFailed.
+type CaptureHandler func(ctx context.Context, event Event) (CaptureID, error)
+
+// captureV2 tracks handlers registered by RegisterCaptureV2(), which are also in capture. It is guarded by captureMu.
+var captureV2 = map[CaptureProvider]CaptureHandler{}
+
+// RegisterCaptureV2 adds a handler to the set that will be invoked each time an error is captured, as
+// RegisterCapture() does. The handler is passed the error described as an Event, so that it need not walk the
+// error to find its stack, severity, tags, fingerprint and annotations, nor guess the meaning of positional
+// arguments. The handler is unregistered by UnregisterCapture(), and may be replaced by ReplaceCaptureV2().
+//
+//	errors.RegisterCaptureV2("webhook", func(ctx context.Context, e errors.Event) (errors.CaptureID, error) {
+//	  id, err := client.Send(ctx, e.Fingerprint, e.Message, e.Annotations)
+//	  return errors.CaptureID(id), err
+//	})
+func RegisterCaptureV2(name CaptureProvider, handler CaptureHandler) {
+	registerCapture(name, captureFuncV2(handler), handler, false)
+}
+
+// ReplaceCaptureV2 registers a handler as RegisterCaptureV2() does, replacing the handler registered as name, if
+// any, without missing an alert, see ReplaceCapture().
+func ReplaceCaptureV2(name CaptureProvider, handler CaptureHandler) {
+	registerCapture(name, captureFuncV2(handler), handler, true)
+}
+
+// captureFuncV2 adapts a handler to be called without a context, see invokeCapture().
+func captureFuncV2(handler CaptureHandler) CaptureFunc {
+	return func(err error, arg ...any) CaptureID {
+		id, _ := handler(context.Background(), NewEvent(err, arg...))
+		return id
+	}
+}
+
+// invokeCapture invokes the handler registered as provider, and returns its result. The event, if not nil, has
+// been returned by the function set by SetBeforeCapture(), and is passed to the handler in place of the error and
+// its arguments.
+func invokeCapture(ctx context.Context, provider CaptureProvider, handler CaptureFunc, v2 CaptureHandler,
+	exception error, arg []any, event *Event) CaptureResult {
+	if v2 == nil {
+		if event != nil {
+			exception, arg = event.Error, event.Arg
+		}
+		result := CaptureResult{Status: CaptureOK, ID: handler(exception, arg...)}
+		if result.ID == "" {
+			result.Status = CaptureSkipped
+		}
+		return result
+	}
+
+	if event == nil {
+		e := NewEvent(exception, arg...)
+		event = &e
+	}
+	id, err := v2(ctx, *event)
+	switch {
+	case err != nil:
+		return CaptureResult{Status: CaptureFailed, Err: Errorf("capture handler (%q) failed: %w", provider, err)}
+	case id == "":
+		return CaptureResult{Status: CaptureSkipped}
+	}
+	return CaptureResult{Status: CaptureOK, ID: id}
+}
@@ -0,0 +1,53 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterCaptureV2(t *testing.T) {
+	var (
+		events   []errors.Event
+		deadline []bool
+	)
+	errors.RegisterCaptureV2("TestRegisterCaptureV2", func(ctx context.Context, e errors.Event) (errors.CaptureID, error) {
+		_, ok := ctx.Deadline()
+		deadline = append(deadline, ok)
+		events = append(events, e)
+		if e.Code == "FAIL" {
+			return "", errors.New("provider unavailable")
+		}
+		return "TestRegisterCaptureV2", nil
+	})
+	defer errors.UnregisterCapture("TestRegisterCaptureV2")
+	assert.Panics(t, func() {
+		errors.RegisterCaptureV2("TestRegisterCaptureV2", nil)
+	})
+
+	err := errors.WithSeverity(errors.WithTags(errors.Errorkv("widget failed", "table", "orders"), "billing"), errors.SeverityWarning)
+	var c *errors.Captured
+	if assert.True(t, errors.As(errors.Alert(err), &c)) {
+		assert.Equal(t, errors.CaptureID("TestRegisterCaptureV2"), c.ID("TestRegisterCaptureV2"))
+	}
+	if assert.Len(t, events, 1) {
+		e := events[0]
+		assert.Equal(t, "widget failed (table=orders)", e.Message)
+		assert.Equal(t, errors.SeverityWarning, e.Severity)
+		assert.Equal(t, []string{"billing"}, e.Tags)
+		assert.Equal(t, "orders", e.Annotations["table"])
+		assert.Equal(t, errors.Fingerprint(err), e.Fingerprint)
+		assert.NotEmpty(t, e.Stack)
+		assert.True(t, deadline[0], "context should have the deadline of the alert, see CaptureTimeout")
+	}
+
+	_, failed := errors.AlertSync(context.Background(), errors.WithCode(errors.New("TestRegisterCaptureV2"), "FAIL"))
+	assert.ErrorContains(t, failed["TestRegisterCaptureV2"], "provider unavailable")
+	assert.Equal(t, "failed", errors.CaptureFailed.String())
+
+	errors.UnregisterCapture("TestRegisterCaptureV2")
+	errors.Alert(errors.New("TestRegisterCaptureV2"))
+	assert.Len(t, events, 2)
+}
@@ -0,0 +1,24 @@
+package errors
+
+// hint is the annotation type recording advice to the user who encounters an error.
+type hint string
+
+// WithHint returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and advises the user who encounters it, i.e. "try reducing the batch size". Unlike the message of the error,
+// which Redact() truncates, the hint is written for users, so it is kept: the public message is the redacted
+// message followed by the hint.
+//
+//	return errors.WithHint(errors.Errorf("batch (%d rows) too large: %w", n, err), "try reducing the batch size")
+//
+// Text in parentheses is removed from a hint, as from the message, so a hint should not be built from dynamic
+// details.
+func WithHint(exception error, advice string) error {
+	return Annotate(exception, hint(advice))
+}
+
+// Hint returns the hint of an error, or the empty string if it has none. When an error has several hints, the
+// outermost is returned.
+func Hint(exception error) string {
+	advice, _ := Annotation[hint](exception)
+	return string(advice)
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"log"
+	"sync"
+)
+
+// AlertHook is invoked when an error is alerted, before the error is passed to capture handlers. A hook may
+// return the error as-is, return an error which wraps it (i.e. with annotations), or return nil to prevent the
+// error from being captured.
+type AlertHook func(exception error) error
+
+type namedHook struct {
+	name string
+	hook AlertHook
+}
+
+var (
+	hookMu sync.RWMutex
+	hooks  []namedHook // in order of registration
+)
+
+// RegisterAlertHook adds a hook that will be invoked each time an error is alerted. Hooks are invoked in the
+// order they are registered, each receiving the error returned by the previous hook.
+func RegisterAlertHook(name string, hook AlertHook) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	for i := range hooks {
+		if hooks[i].name == name {
+			log.Panicf("alert hook (%q) already registered", name)
+		}
+	}
+	hooks = append(hooks, namedHook{name: name, hook: hook})
+}
+
+func UnregisterAlertHook(name string) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	for i := range hooks {
+		if hooks[i].name == name {
+			hooks = append(hooks[:i:i], hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// runHooks passes an error through each registered hook. It returns nil if a hook prevents the error from being
+// captured.
+func runHooks(exception error) error {
+	if exception == nil {
+		return nil
+	}
+
+	hookMu.RLock()
+	current := hooks
+	hookMu.RUnlock()
+
+	for _, h := range current {
+		exception = h.hook(exception)
+		if exception == nil {
+			log.Printf("alert not captured, dropped by hook (%q)", h.name)
+			return nil
+		}
+	}
+	return exception
+}
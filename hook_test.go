@@ -0,0 +1,43 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertHook(t *testing.T) {
+	var captured []error
+	errors.RegisterCapture("TestAlertHook", func(err error, _ ...any) errors.CaptureID {
+		captured = append(captured, err)
+		return "TestAlertHook"
+	})
+	defer errors.UnregisterCapture("TestAlertHook")
+
+	const drop = errors.String("drop")
+	errors.RegisterAlertHook("drop", func(err error) error {
+		if errors.Is(err, drop) {
+			return nil
+		}
+		return err
+	})
+	defer errors.UnregisterAlertHook("drop")
+	errors.RegisterAlertHook("owner", func(err error) error {
+		return errors.WithOwner(err, "TestAlertHook")
+	})
+	defer errors.UnregisterAlertHook("owner")
+
+	assert.Panics(t, func() { errors.RegisterAlertHook("owner", nil) })
+
+	err := errors.Alert(drop)
+	assert.ErrorIs(t, err, drop)
+	var c *errors.Captured
+	assert.False(t, errors.As(err, &c), "dropped error should not be captured")
+
+	err = errors.Alert(errors.New("TestAlertHook"))
+	assert.True(t, errors.As(err, &c))
+	if assert.Len(t, captured, 1) {
+		assert.Equal(t, "TestAlertHook", errors.OwnerOf(captured[0]))
+	}
+}
@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// Indexed holds the annotations of an error, indexed by type. See Index().
+type Indexed struct {
+	exception error
+
+	// value is the outermost annotation of each type
+	value map[reflect.Type]any
+
+	// ordered is every annotation, outermost first, to find values of interface types
+	ordered []any
+}
+
+// Index walks the tree of wrapped errors once, and returns a handle from which annotations can be looked up
+// without walking the tree again. Where an error is inspected repeatedly, i.e. by several middleware checking
+// its code, kind and owner, index it once at the boundary.
+//
+//	ix := errors.Index(err)
+//	if ix.Kind() == errors.KindNotFound { ... }
+//	if user, ok := errors.IndexedAnnotation[UserID](ix); ok { ... }
+//
+// The handle reflects the error as it was when indexed. Returns nil when the exception passed in is nil; the
+// methods of a nil handle return zero values.
+func Index(exception error) *Indexed {
+	if isNil(exception, "Index") {
+		return nil
+	}
+	ix := newIndexed(exception)
+	Walk(exception, func(ex error) bool {
+		ix.add(ex)
+		return true
+	})
+	return ix
+}
+
+func newIndexed(exception error) *Indexed {
+	return &Indexed{
+		exception: exception,
+		value:     map[reflect.Type]any{},
+	}
+}
+
+// add indexes the annotations of one error of the tree. Errors must be added outermost first.
+func (ix *Indexed) add(exception error) {
+	var value []any
+	if a, ok := exception.(*annotated); ok {
+		value = a.value
+	} else {
+		value = boundValues(exception)
+	}
+	for _, v := range value {
+		if v == nil {
+			continue
+		}
+		ix.ordered = append(ix.ordered, v)
+		if _, ok := ix.value[reflect.TypeOf(v)]; !ok {
+			ix.value[reflect.TypeOf(v)] = v
+		}
+	}
+}
+
+// Err returns the error which was indexed.
+func (ix *Indexed) Err() error {
+	if ix == nil {
+		return nil
+	}
+	return ix.exception
+}
+
+// IndexedAnnotation is like Annotation(), looking up a value in an index. When T is a concrete type, the lookup
+// takes constant time.
+func IndexedAnnotation[T any](ix *Indexed) (T, bool) {
+	var result T
+	if ix == nil {
+		return result, false
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		v, ok := ix.value[t]
+		if ok {
+			result = v.(T)
+		}
+		return result, ok
+	}
+	for _, v := range ix.ordered {
+		if result, ok := v.(T); ok {
+			return result, true
+		}
+	}
+	return result, false
+}
+
+// Code is like CodeOf().
+func (ix *Indexed) Code() Code {
+	code, _ := IndexedAnnotation[Code](ix)
+	return code
+}
+
+// Kind is like KindOf().
+func (ix *Indexed) Kind() Kind {
+	kind, _ := IndexedAnnotation[Kind](ix)
+	return kind
+}
+
+// Owner is like OwnerOf().
+func (ix *Indexed) Owner() string {
+	team, _ := IndexedAnnotation[owner](ix)
+	return string(team)
+}
+
+// Severity is like SeverityOf().
+func (ix *Indexed) Severity() Severity {
+	if severity, ok := IndexedAnnotation[Severity](ix); ok {
+		return severity
+	}
+	return SeverityError
+}
@@ -0,0 +1,63 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type indexUser string
+
+func TestIndex(t *testing.T) {
+	err := errors.WithCode(errors.New("TestIndex"), "IDX-001")
+	err = errors.WithKind(errors.Wrap(err, "outer"), errors.KindNotFound)
+	err = errors.Annotate(err, indexUser("alice"), errors.SeverityWarning)
+	err = errors.Annotate(err, indexUser("bob"))
+
+	ix := errors.Index(err)
+	assert.Equal(t, err, ix.Err())
+	assert.Equal(t, errors.CodeOf(err), ix.Code())
+	assert.Equal(t, errors.KindOf(err), ix.Kind())
+	assert.Equal(t, errors.SeverityOf(err), ix.Severity())
+	assert.Equal(t, "", ix.Owner())
+
+	user, ok := errors.IndexedAnnotation[indexUser](ix)
+	assert.True(t, ok)
+	assert.Equal(t, indexUser("bob"), user, "outermost annotation wins")
+
+	stringer, ok := errors.IndexedAnnotation[fmt.Stringer](ix)
+	assert.True(t, ok)
+	assert.Equal(t, errors.SeverityWarning, stringer)
+
+	_, ok = errors.IndexedAnnotation[int](ix)
+	assert.False(t, ok)
+
+	var nilIndex *errors.Indexed = errors.Index(nil)
+	assert.Nil(t, nilIndex)
+	assert.Equal(t, errors.SeverityError, nilIndex.Severity())
+}
+
+func BenchmarkIndex(b *testing.B) {
+	err := errors.WithCode(errors.New("BenchmarkIndex"), "IDX-001")
+	for i := 0; i < 5; i++ {
+		err = errors.Wrap(err, "layer")
+	}
+	b.Run("Annotation", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = errors.CodeOf(err)
+			_ = errors.KindOf(err)
+			_ = errors.SeverityOf(err)
+		}
+	})
+	b.Run("Index", func(b *testing.B) {
+		ix := errors.Index(err)
+		for i := 0; i < b.N; i++ {
+			_ = ix.Code()
+			_ = ix.Kind()
+			_ = ix.Severity()
+		}
+	})
+}
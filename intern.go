@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// InternLimit bounds how many distinct errors Intern() keeps. After the limit is reached, Intern() continues to
+// work, but produces a new error for each message not already interned.
+var InternLimit int32 = 4096
+
+const (
+	internMaxArgs   = 4  // more args are not interned
+	internMaxString = 64 // longer strings are not interned
+)
+
+// internKey identifies an interned error by format and arguments. Only comparable, basic values are used as
+// arguments, so that the key can be used in a map.
+type internKey struct {
+	format string
+	n      int
+	arg    [internMaxArgs]any
+}
+
+var (
+	interned      sync.Map // internKey to *Error
+	internedCount atomic.Int32
+)
+
+// Intern is like Errorf(), except that it returns the same error each time it is called with the same format and
+// arguments, and the error has no stack trace (see NoStack). This is intended for tight loops which produce, and
+// usually discard, errors which classify an outcome. For example,
+//
+//	for _, row := range rows {
+//	  if err := validate(row); err != nil { ... }
+//	}
+//
+//	func validate(row Row) error {
+//	  if row.Size > max {
+//	    return errors.Intern("row too large (%d)", max)
+//	  }
+//	  ...
+//	}
+//
+// Errors are interned only when there are few arguments, and each is a bool, number or short string. Otherwise,
+// a new error is produced each time, still without a stack trace. The error returned must not be modified.
+func Intern(format string, a ...any) error {
+	key, ok := newInternKey(format, a)
+	if !ok {
+		return newInterned(format, a)
+	}
+	if exception, ok := interned.Load(key); ok {
+		return exception.(*Error)
+	}
+	exception := newInterned(format, a)
+	if internedCount.Load() >= InternLimit {
+		return exception
+	}
+	actual, loaded := interned.LoadOrStore(key, exception)
+	if !loaded {
+		internedCount.Add(1)
+	}
+	return actual.(*Error)
+}
+
+func newInterned(format string, a []any) *Error {
+	return &Error{
+		error:  NoStack(fmt.Errorf(format, a...)),
+		arg:    a,
+		format: format,
+	}
+}
+
+func newInternKey(format string, a []any) (internKey, bool) {
+	key := internKey{format: format, n: len(a)}
+	if len(a) > internMaxArgs {
+		return key, false
+	}
+	for i := range a {
+		if a[i] == nil {
+			continue
+		}
+		switch v := reflect.ValueOf(a[i]); v.Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		case reflect.String:
+			if v.Len() > internMaxString {
+				return key, false
+			}
+		default:
+			return key, false
+		}
+		key.arg[i] = a[i]
+	}
+	return key, true
+}
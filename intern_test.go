@@ -0,0 +1,43 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntern(t *testing.T) {
+	first := errors.Intern("row too large (%d)", 42)
+	assert.Equal(t, "row too large (42)", first.Error())
+	assert.Same(t, first, errors.Intern("row too large (%d)", 42))
+	assert.NotSame(t, first, errors.Intern("row too large (%d)", 43))
+	assert.NotSame(t, first, errors.Intern("row too large (%d)", int64(42)), "different types are different args")
+	assert.Equal(t, "row too large (42)", fmt.Sprintf("%+v", first), "interned errors have no stack")
+
+	// errors are not interned when arguments are not simple values
+	inner := errors.New("inner")
+	wrapped := errors.Intern("wrapped: %w", inner)
+	assert.NotSame(t, wrapped, errors.Intern("wrapped: %w", inner))
+	assert.ErrorIs(t, wrapped, inner)
+
+	// wrapping does not add a stack
+	assert.Equal(t, "validate: row too large (42)", fmt.Sprintf("%+v", errors.Wrap(first, "validate")))
+}
+
+func BenchmarkIntern(b *testing.B) {
+	b.Run("Errorf", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = errors.Errorf("row too large (%d)", 42)
+		}
+	})
+	b.Run("Intern", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = errors.Intern("row too large (%d)", 42)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+// Package redact removes sensitive details from error messages. It is the text processing behind errors.Redact()
+// and fingerprints, which must agree on what is removed.
+package redact
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parenReg matches parentheticals and preceding space.
+var parenReg = regexp.MustCompile(` \([^()]*\)`)
+
+// Parens removes content in parentheses, which by convention holds potentially sensitive details, along with the
+// space preceding it. Nested parentheses are not expected.
+func Parens(s string) string {
+	return parenReg.ReplaceAllString(s, "")
+}
+
+// Word replaces occurrences of word in s which are not part of a longer identifier, so that database "db" does
+// not alter "dbs".
+func Word(s, word, replacement string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, word)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if identRune(before) || identRune(after) {
+			b.WriteString(s[:end])
+		} else {
+			b.WriteString(s[:i])
+			b.WriteString(replacement)
+		}
+		s = s[end:]
+	}
+}
+
+func identRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Expressions matching values which are likely to have been formatted into a message.
+var (
+	quotedReg = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+	uuidReg   = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	hexReg    = regexp.MustCompile(`\b(?:0x[0-9a-fA-F]+|[0-9a-fA-F]{6,})\b`)
+	numberReg = regexp.MustCompile(`\b\d+(?:\.\d+)*(?:ns|us|µs|ms|s|m|h)?\b`)
+)
+
+// Literals replaces values which are likely to have been formatted into a message, i.e. numbers, quoted strings
+// and identifiers, with a verb, so that messages formatted from the same template yield the same text:
+//
+//	Literals(`user "bob" not found after 3 attempts`) == `user %q not found after %v attempts`
+func Literals(s string) string {
+	s = quotedReg.ReplaceAllString(s, "%q")
+	s = uuidReg.ReplaceAllString(s, "%s")
+	s = hexReg.ReplaceAllStringFunc(s, func(word string) string {
+		// words such as "facade" are not identifiers, nor are numbers
+		if !strings.HasPrefix(word, "0x") &&
+			(strings.IndexFunc(word, unicode.IsDigit) < 0 || strings.IndexFunc(word, unicode.IsLetter) < 0) {
+			return word
+		}
+		return "%x"
+	})
+	return numberReg.ReplaceAllString(s, "%v")
+}
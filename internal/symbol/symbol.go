@@ -0,0 +1,83 @@
+// Package symbol resolves the functions, packages and files of stack frames, for rendering and classifying stack
+// traces.
+package symbol
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// FuncName returns the name of the function in a stack frame.
+func FuncName(frame pkgerrors.Frame) string {
+	fn := runtime.FuncForPC(uintptr(frame) - 1) // a frame is the program counter + 1
+	if fn == nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+// Package returns the import path of the package of a function, given the function's full name, i.e.
+// "github.com/memsql/errors.RegisterSentinel".
+func Package(funcName string) string {
+	if bracket := strings.Index(funcName, "["); bracket >= 0 {
+		funcName = funcName[:bracket] // type parameters of a generic function may contain slashes
+	}
+	slash := strings.LastIndex(funcName, "/")
+	if dot := strings.Index(funcName[slash+1:], "."); dot >= 0 {
+		return funcName[:slash+1+dot]
+	}
+	return funcName
+}
+
+// Location returns the file and line of a frame, i.e. "store/db.go:42". The file is identified by the import path
+// of its package, which does not depend on where the module was built, and is relative to the main module when it
+// is part of it. Files of package main are identified by their directory.
+func Location(frame pkgerrors.Frame, name string) string {
+	pc := uintptr(frame) - 1 // a frame is the program counter + 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	file, line := fn.FileLine(pc)
+
+	dir := strings.TrimSuffix(Package(name), "_test")
+	if dir == "main" {
+		dir = path.Base(path.Dir(file))
+	} else if main := MainModule(); main != "" && InModule(dir, main) {
+		dir = strings.TrimPrefix(strings.TrimPrefix(dir, main), "/")
+	}
+	return path.Join(dir, path.Base(file)) + ":" + strconv.Itoa(line)
+}
+
+// InModule returns whether a package is part of a module.
+func InModule(pkg, module string) bool {
+	pkg = strings.TrimSuffix(pkg, "_test")
+	return pkg == module || strings.HasPrefix(pkg, module+"/") || pkg == "main"
+}
+
+var (
+	mainModuleOnce sync.Once
+	mainModulePath string
+)
+
+// MainModule returns the path of the main module of the program, or the empty string if it is not known.
+func MainModule() string {
+	mainModuleOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		mainModulePath = info.Main.Path
+		if mainModulePath == "" {
+			// test binaries of older versions of Go do not report the main module
+			mainModulePath = strings.TrimSuffix(info.Path, ".test")
+		}
+	})
+	return mainModulePath
+}
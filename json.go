@@ -0,0 +1,443 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+// jsonLayer is the JSON encoding of one error in a tree of errors. Each error records its own message, so the
+// messages of a decoded tree are exactly those of the encoded one.
+type jsonLayer struct {
+	Type        string                        `json:"type,omitempty"`
+	Message     string                        `json:"message"`
+	Template    string                        `json:"template,omitempty"`
+	Arg         []any                         `json:"arg,omitempty"`
+	Sentinel    string                        `json:"sentinel,omitempty"`
+	Annotations *jsonAnnotations              `json:"annotations,omitempty"`
+	ID          map[CaptureProvider]CaptureID `json:"id,omitempty"`
+	Wrapped     []jsonLayer                   `json:"wrapped,omitempty"`
+}
+
+// jsonAnnotations are the annotations which survive encoding. Annotations of other types are dropped, as they
+// cannot be decoded.
+type jsonAnnotations struct {
+	Code        Code              `json:"code,omitempty"`
+	Kind        Kind              `json:"kind,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Runbook     Runbook           `json:"runbook,omitempty"`
+	Hint        string            `json:"hint,omitempty"`
+	Severity    Severity          `json:"severity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	TagValues   map[string]string `json:"tag_values,omitempty"`
+	Fields      Fields            `json:"fields,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Retryable   *bool             `json:"retryable,omitempty"`
+	RetryAfter  time.Duration     `json:"retry_after,omitempty"`
+	HTTPStatus  int               `json:"http_status,omitempty"`
+	Cluster     ClusterID         `json:"cluster_id,omitempty"`
+	Workspace   WorkspaceID       `json:"workspace_id,omitempty"`
+	Database    DatabaseName      `json:"database,omitempty"`
+	Correlation CorrelationID     `json:"correlation_id,omitempty"`
+}
+
+// Types of jsonLayer. Errors of types not listed here are encoded with an empty type, and decoded as an error
+// with the same message, wrapping the same errors.
+const (
+	jsonError     = "error"
+	jsonAnnotated = "annotated"
+	jsonCaptured  = "captured"
+	jsonPublic    = "public"
+	jsonJoin      = "join"
+	jsonNoStack   = "nostack"
+	jsonString    = "string"
+	jsonIsString  = "string.errorf" // see String.Errorf()
+	jsonStdlib    = "stdlib"
+)
+
+// stdlib are sentinels of the standard library which are preserved by encoding, so that errors.Is() finds them
+// in a decoded error.
+var stdlib = map[string]error{
+	"context.Canceled":         context.Canceled,
+	"context.DeadlineExceeded": context.DeadlineExceeded,
+	"io.EOF":                   io.EOF,
+	"io.ErrUnexpectedEOF":      io.ErrUnexpectedEOF,
+	"fs.ErrNotExist":           fs.ErrNotExist,
+	"fs.ErrExist":              fs.ErrExist,
+	"fs.ErrPermission":         fs.ErrPermission,
+}
+
+// MarshalJSON encodes the error, and the errors it wraps, so that FromJSON() can rebuild them. See FromJSON().
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeLayer(e))
+}
+
+// MarshalJSON encodes the error, the errors it wraps, and its capture IDs, so that FromJSON() can rebuild them.
+// See FromJSON().
+func (e *Captured) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeLayer(e))
+}
+
+// MarshalJSON encodes only the public message. Unlike other errors of this package, the error it wraps is not
+// encoded, so a Public error is safe to send to an unprivileged client.
+func (e Public) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLayer{Type: jsonPublic, Message: e.msg})
+}
+
+// FromJSON rebuilds an error encoded as JSON by MarshalJSON() of *Error, *Captured or Public; or by
+// json.Marshal() of a struct containing one of those. This allows errors to be sent through message queues and
+// the like, and handled by the receiver as though they were produced locally.
+//
+//	var job struct {
+//	  Err json.RawMessage
+//	}
+//	...
+//	err := errors.FromJSON(job.Err)
+//	if errors.Is(err, ErrNotFound) { ... }
+//
+// The rebuilt error has the messages, templates, codes, kinds, owners, runbooks, severities, tags, named values
+// (see AnnotateKV), fingerprint parts, retry markers, HTTP statuses and capture IDs of the original. String
+// errors, and common sentinels of the standard library (i.e. context.Canceled), satisfy errors.Is(), as they did
+// in the original. Other annotations, arguments other than named values, and stack traces are not preserved.
+// Arguments are rebuilt as strings.
+//
+// FromJSON returns nil for "null". If data cannot be decoded, it returns an error which wraps ErrNotEncoded.
+func FromJSON(data []byte) error {
+	var layer *jsonLayer
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return Errorf("failed to decode error (%w): %w", err, ErrNotEncoded)
+	}
+	if layer == nil {
+		return nil
+	}
+	return layer.decode()
+}
+
+// ErrNotEncoded is wrapped by the error returned when FromJSON() or Decode() is passed data which is not an
+// encoded error.
+const ErrNotEncoded = String("not an encoded error")
+
+// Encode produces a representation of any error, which Decode() rebuilds, i.e. in another process. It is intended
+// for errors sent over RPC, so that errors.Is() works as well on the receiving side as on the sending side:
+//
+//	const ErrQuotaExceeded = errors.String("quota exceeded")
+//	...
+//	reply.Err = errors.Encode(errors.Wrap(ErrQuotaExceeded, "cannot create table"))
+//	...
+//	err := errors.Decode(reply.Err)
+//	if errors.Is(err, ErrQuotaExceeded) { ... }
+//
+// The representation is JSON, and what is preserved is described by FromJSON(). Encode returns nil when the
+// exception passed in is nil.
+func Encode(exception error) []byte {
+	if exception == nil {
+		return nil
+	}
+	Handled(exception)
+	data, err := json.Marshal(encodeLayer(exception))
+	if err != nil {
+		// not expected, as values that cannot be encoded are converted to strings
+		return []byte(fmt.Sprintf(`{"message": %q}`, exception.Error()))
+	}
+	return data
+}
+
+// Decode rebuilds an error produced by Encode(). It returns nil when data is empty. If data cannot be decoded, it
+// returns an error which wraps ErrNotEncoded.
+func Decode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return FromJSON(data)
+}
+
+// encodeLayer encodes an error and the errors it wraps.
+func encodeLayer(exception error) jsonLayer {
+	if m, ok := exception.(*mustHandle); ok {
+		return encodeLayer(m.error) // the mark applies only to this process
+	}
+
+	layer := jsonLayer{Message: exception.Error()}
+	switch e := exception.(type) {
+	case *Error:
+		layer.Type = jsonError
+		layer.Template = e.format
+		for _, a := range e.arg {
+			layer.Arg = append(layer.Arg, jsonArg(a))
+		}
+	case *annotated:
+		layer.Type = jsonAnnotated
+		layer.Annotations = encodeAnnotations(e.value)
+	case *Captured:
+		layer.Type = jsonCaptured
+		layer.ID = e.IDs()
+	case Public:
+		layer.Type = jsonPublic
+	case *joinError:
+		layer.Type = jsonJoin
+	case *noStack:
+		layer.Type = jsonNoStack
+	case String:
+		layer.Type = jsonString
+	case errorString:
+		layer.Type = jsonIsString
+		layer.Sentinel = string(e.s)
+		layer.Wrapped = []jsonLayer{encodeLayer(e.error)} // errorString does not implement Unwrap()
+		return layer
+	default:
+		for name, sentinel := range stdlib {
+			if exception == sentinel {
+				layer.Type = jsonStdlib
+				layer.Sentinel = name
+			}
+		}
+	}
+
+	switch e := exception.(type) {
+	case interface{ Unwrap() []error }:
+		for _, ex := range e.Unwrap() {
+			if ex != nil {
+				layer.Wrapped = append(layer.Wrapped, encodeLayer(ex))
+			}
+		}
+	case interface{ Unwrap() error }:
+		if ex := e.Unwrap(); ex != nil {
+			layer.Wrapped = []jsonLayer{encodeLayer(ex)}
+		}
+	}
+	return layer
+}
+
+// encodeAnnotations encodes the annotations which can be decoded, or returns nil if there are none.
+func encodeAnnotations(value []any) *jsonAnnotations {
+	var (
+		result jsonAnnotations
+		found  bool
+	)
+	for _, v := range value {
+		known := true
+		switch v := v.(type) {
+		case Code:
+			result.Code = v
+		case Kind:
+			result.Kind = v
+		case owner:
+			result.Owner = string(v)
+		case Runbook:
+			result.Runbook = v
+		case hint:
+			result.Hint = string(v)
+		case Severity:
+			result.Severity = v
+		case tags:
+			result.Tags = v
+		case keyTag:
+			if result.TagValues == nil {
+				result.TagValues = map[string]string{}
+			}
+			result.TagValues[v.key] = v.value
+		case Fields:
+			result.Fields = jsonArg(v).(Fields)
+		case fingerprint:
+			result.Fingerprint = v
+		case retryable:
+			r := bool(v)
+			result.Retryable = &r
+		case retryAfter:
+			result.RetryAfter = time.Duration(v)
+		case httpStatus:
+			result.HTTPStatus = int(v)
+		case ClusterID:
+			result.Cluster = v
+		case WorkspaceID:
+			result.Workspace = v
+		case DatabaseName:
+			result.Database = v
+		case CorrelationID:
+			result.Correlation = v
+		default:
+			known = false
+		}
+		found = found || known
+	}
+	if !found {
+		return nil
+	}
+	return &result
+}
+
+// jsonArg returns a value which can be encoded. Named values are kept, other values are converted to strings,
+// which is how they are rebuilt.
+func jsonArg(arg any) any {
+	fields, ok := arg.(Fields)
+	if !ok {
+		return fmt.Sprint(arg)
+	}
+	result := make(Fields, len(fields))
+	for key, value := range fields {
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprint(value)
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// decode rebuilds an error encoded by encodeLayer().
+func (layer jsonLayer) decode() error {
+	wrapped := make([]error, len(layer.Wrapped))
+	for i := range layer.Wrapped {
+		wrapped[i] = layer.Wrapped[i].decode()
+	}
+	var inner error
+	if len(wrapped) > 0 {
+		inner = wrapped[0]
+	}
+
+	switch layer.Type {
+	case jsonError:
+		if inner == nil {
+			inner = String(layer.Message)
+		}
+		arg := make([]any, len(layer.Arg))
+		for i, a := range layer.Arg {
+			if fields, ok := a.(map[string]any); ok {
+				arg[i] = Fields(fields)
+			} else {
+				arg[i] = a
+			}
+		}
+		return &Error{error: inner, arg: arg, format: layer.Template}
+	case jsonAnnotated:
+		if inner == nil {
+			break
+		}
+		return Annotate(inner, layer.Annotations.values()...)
+	case jsonCaptured:
+		if inner == nil {
+			break
+		}
+		return &Captured{error: inner, id: layer.ID}
+	case jsonPublic:
+		return Public{msg: layer.Message, error: inner}
+	case jsonJoin:
+		return &joinError{errs: wrapped}
+	case jsonNoStack:
+		if inner == nil {
+			break
+		}
+		return NoStack(inner)
+	case jsonString:
+		return String(layer.Message)
+	case jsonIsString:
+		if inner == nil {
+			break
+		}
+		return errorString{error: inner, s: String(layer.Sentinel)}
+	case jsonStdlib:
+		if sentinel, ok := stdlib[layer.Sentinel]; ok {
+			return sentinel
+		}
+	}
+
+	// an error of a type we cannot rebuild
+	switch len(wrapped) {
+	case 0:
+		return &decodedError{msg: layer.Message}
+	case 1:
+		return &decodedError{msg: layer.Message, error: inner}
+	default:
+		return &decodedJoin{msg: layer.Message, errs: wrapped}
+	}
+}
+
+// values returns the annotations as they are passed to Annotate().
+func (a *jsonAnnotations) values() []any {
+	if a == nil {
+		return nil
+	}
+	var value []any
+	if a.Code != "" {
+		value = append(value, a.Code)
+	}
+	if a.Kind != "" {
+		value = append(value, a.Kind)
+	}
+	if a.Owner != "" {
+		value = append(value, owner(a.Owner))
+	}
+	if a.Runbook != "" {
+		value = append(value, a.Runbook)
+	}
+	if a.Hint != "" {
+		value = append(value, hint(a.Hint))
+	}
+	if a.Severity != 0 {
+		value = append(value, a.Severity)
+	}
+	if len(a.Tags) > 0 {
+		value = append(value, tags(a.Tags))
+	}
+	if len(a.TagValues) > 0 {
+		keys := make([]string, 0, len(a.TagValues))
+		for key := range a.TagValues {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // so the decoded error is the same, each time
+		for _, key := range keys {
+			value = append(value, keyTag{key: key, value: a.TagValues[key]})
+		}
+	}
+	if len(a.Fields) > 0 {
+		value = append(value, a.Fields)
+	}
+	if len(a.Fingerprint) > 0 {
+		value = append(value, fingerprint(a.Fingerprint))
+	}
+	if a.Retryable != nil {
+		value = append(value, retryable(*a.Retryable))
+	}
+	if a.RetryAfter != 0 {
+		value = append(value, retryAfter(a.RetryAfter))
+	}
+	if a.HTTPStatus != 0 {
+		value = append(value, httpStatus(a.HTTPStatus))
+	}
+	if a.Cluster != "" {
+		value = append(value, a.Cluster)
+	}
+	if a.Workspace != "" {
+		value = append(value, a.Workspace)
+	}
+	if a.Database != "" {
+		value = append(value, a.Database)
+	}
+	if a.Correlation != "" {
+		value = append(value, a.Correlation)
+	}
+	return value
+}
+
+// decodedError stands in for an error, of a type which cannot be rebuilt, wrapping at most one error.
+type decodedError struct {
+	msg string
+	error
+}
+
+func (e *decodedError) Error() string { return e.msg }
+
+func (e *decodedError) Unwrap() error { return e.error }
+
+// decodedJoin stands in for an error, of a type which cannot be rebuilt, wrapping several errors.
+type decodedJoin struct {
+	msg  string
+	errs []error
+}
+
+func (e *decodedJoin) Error() string { return e.msg }
+
+func (e *decodedJoin) Unwrap() []error { return e.errs }
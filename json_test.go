@@ -0,0 +1,88 @@
+package errors_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const errJSONNotFound errors.String = "TestJSON not found"
+
+func TestJSON(t *testing.T) {
+	errors.RegisterCapture("TestJSON", func(error, ...any) errors.CaptureID { return "TestJSON 1" })
+	err := errors.Wrap(errors.Errorkv("lookup failed", "table", "widgets"), "request failed")
+	err = errors.WithTags(errors.WithCode(errors.WithKind(err, errors.KindNotFound), "W-1"), "widgets")
+	err = errors.Join(err, errJSONNotFound.Errorf("widget (%d) not found", 42), fmt.Errorf("query: %w", context.Canceled))
+	err = errors.WithRetryAfter(errors.WithSeverity(err, errors.SeverityWarning), time.Second)
+	err = errors.WithHTTPStatus(err, http.StatusTooManyRequests)
+	err = errors.Alert(errors.Wrap(err, "TestJSON"))
+	errors.UnregisterCapture("TestJSON")
+
+	data, jsonErr := json.Marshal(struct{ Err error }{err})
+	assert.NoError(t, jsonErr)
+	var envelope struct{ Err json.RawMessage }
+	assert.NoError(t, json.Unmarshal(data, &envelope))
+
+	decoded := errors.FromJSON(envelope.Err)
+	assert.Equal(t, err.Error(), decoded.Error())
+	assert.Equal(t, fmt.Sprint(err), fmt.Sprint(decoded))
+	assert.ErrorIs(t, decoded, errJSONNotFound)
+	assert.ErrorIs(t, decoded, context.Canceled)
+	assert.Equal(t, errors.Code("W-1"), errors.CodeOf(decoded))
+	assert.Equal(t, errors.KindNotFound, errors.KindOf(decoded))
+	assert.Equal(t, errors.SeverityWarning, errors.SeverityOf(decoded))
+	assert.Equal(t, []string{"widgets"}, errors.TagsOf(decoded))
+	assert.True(t, errors.IsRetryable(decoded))
+	assert.Equal(t, http.StatusTooManyRequests, errors.HTTPStatus(decoded))
+	delay, _ := errors.RetryAfter(decoded)
+	assert.Equal(t, time.Second, delay)
+	value, _ := errors.Value(decoded, "table")
+	assert.Equal(t, "widgets", value)
+
+	var captured *errors.Captured
+	if assert.True(t, errors.As(decoded, &captured)) {
+		assert.Equal(t, errors.CaptureID("TestJSON 1"), captured.ID("TestJSON"))
+	}
+	var e *errors.Error
+	if assert.True(t, errors.As(decoded, &e)) {
+		assert.Equal(t, "TestJSON: %w", e.Template())
+	}
+
+	assert.Nil(t, errors.FromJSON([]byte("null")))
+	assert.Error(t, errors.FromJSON([]byte("{")))
+}
+
+func TestJSONPublic(t *testing.T) {
+	public := errors.Redact(errors.New("user (secret) not found"))
+	data, err := json.Marshal(public)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.Equal(t, "user not found", errors.FromJSON(data).Error())
+}
+
+const errEncodeQuota errors.String = "TestEncode quota exceeded"
+
+func TestEncode(t *testing.T) {
+	assert.Nil(t, errors.Encode(nil))
+	assert.Nil(t, errors.Decode(nil))
+
+	for _, err := range []error{
+		errors.Wrap(errEncodeQuota, "cannot create table"),
+		fmt.Errorf("cannot create table: %w", errEncodeQuota),
+		errEncodeQuota.Errorf("tenant (%s) over limit", "acme"),
+	} {
+		decoded := errors.Decode(errors.Encode(err))
+		assert.Equal(t, err.Error(), decoded.Error())
+		assert.ErrorIs(t, decoded, errEncodeQuota, "sentinel should survive %q", err)
+		assert.NotErrorIs(t, decoded, errors.String("cannot create table"))
+	}
+
+	err := errors.Decode([]byte("not JSON"))
+	assert.ErrorIs(t, err, errors.ErrNotEncoded)
+}
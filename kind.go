@@ -0,0 +1,28 @@
+package errors
+
+// Kind is a broad category of error. Where a Code identifies a specific error, many errors may share a kind. The
+// kind determines, for example, how an error should be presented to a client.
+type Kind string
+
+const (
+	KindInvalid         Kind = "invalid"
+	KindNotFound        Kind = "not found"
+	KindAlreadyExists   Kind = "already exists"
+	KindPermission      Kind = "permission denied"
+	KindUnauthenticated Kind = "unauthenticated"
+	KindUnavailable     Kind = "unavailable"
+	KindTimeout         Kind = "timeout"
+	KindInternal        Kind = "internal"
+)
+
+// WithKind returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and has the kind passed in.
+func WithKind(exception error, kind Kind) error {
+	return Annotate(exception, kind)
+}
+
+// KindOf returns the kind of an error, or the empty string if no kind has been specified.
+func KindOf(exception error) Kind {
+	kind, _ := Annotation[Kind](exception)
+	return kind
+}
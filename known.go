@@ -0,0 +1,147 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// KnownIssue identifies errors which are accepted, for a while, so that they are logged rather than captured.
+// Typically, a manifest of known issues is produced from the errors captured by a previous deployment, and loaded
+// at startup, see LoadKnownIssues().
+type KnownIssue struct {
+	// Fingerprint, if not empty, matches errors with the fingerprint, see Fingerprint().
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Code, if not empty, matches errors with the code, see CodeOf().
+	Code Code `json:"code,omitempty"`
+
+	// Expires is when errors matching the issue are captured again.
+	Expires time.Time `json:"expires"`
+
+	// Reason explains why the issue is accepted, i.e. a link to a ticket.
+	Reason string `json:"reason,omitempty"`
+}
+
+// key identifies the issue in AlertStats.KnownIssues.
+func (k KnownIssue) key() string {
+	if k.Fingerprint != "" {
+		return k.Fingerprint
+	}
+	return "code " + string(k.Code)
+}
+
+var (
+	knownMu     sync.RWMutex
+	knownIssues []KnownIssue
+)
+
+// SetKnownIssues replaces the known issues. An alert of an error which matches an issue which has not expired is
+// demoted: it is logged, and counted by AlertStats.KnownIssues, but not passed to capture handlers. So
+// long-standing, accepted errors remain visible in logs and metrics, without drowning new errors. An issue with
+// both a fingerprint and a code matches errors which have both.
+//
+// Unlike Mute(), which silences an error found to be noisy while the program runs, known issues are meant to be
+// loaded as a whole, at startup.
+func SetKnownIssues(issues []KnownIssue) error {
+	for i, issue := range issues {
+		key := fmt.Sprintf("known_issues[%d]", i)
+		if issue.Fingerprint == "" && issue.Code == "" {
+			return InvalidConfig(key, nil, "fingerprint or code")
+		}
+		if issue.Expires.IsZero() {
+			return InvalidConfig(key+".expires", nil, "time")
+		}
+	}
+
+	knownMu.Lock()
+	defer knownMu.Unlock()
+	knownIssues = append([]KnownIssue(nil), issues...)
+	return nil
+}
+
+// LoadKnownIssues reads a manifest of known issues, a JSON array of KnownIssue, and replaces the known issues with
+// it (see SetKnownIssues).
+//
+//	[
+//	  {"fingerprint": "8f3a05b2c41d9e77", "expires": "2024-07-01T00:00:00Z", "reason": "OPS-1234"},
+//	  {"code": "W-1", "expires": "2024-06-15T00:00:00Z"}
+//	]
+func LoadKnownIssues(r io.Reader) error {
+	var issues []KnownIssue
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return Wrap(err, "cannot decode known issues")
+	}
+	return SetKnownIssues(issues)
+}
+
+// LoadKnownIssuesFile reads a manifest of known issues from a file, see LoadKnownIssues().
+func LoadKnownIssuesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return Wrap(err, "cannot open known issues")
+	}
+	defer f.Close()
+	return LoadKnownIssues(f)
+}
+
+// KnownIssues returns the known issues which have not expired.
+func KnownIssues() []KnownIssue {
+	now := time.Now()
+	knownMu.RLock()
+	defer knownMu.RUnlock()
+	var result []KnownIssue
+	for _, issue := range knownIssues {
+		if now.Before(issue.Expires) {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+// isKnown returns the known issue which an error matches, if any.
+func isKnown(exception error) (KnownIssue, bool) {
+	knownMu.RLock()
+	defer knownMu.RUnlock()
+	if len(knownIssues) == 0 {
+		return KnownIssue{}, false // avoid computing fingerprint
+	}
+
+	now := time.Now()
+	var fingerprint string
+	code := CodeOf(exception)
+	for _, issue := range knownIssues {
+		if !now.Before(issue.Expires) {
+			continue
+		}
+		if issue.Code != "" && issue.Code != code {
+			continue
+		}
+		if issue.Fingerprint != "" {
+			if fingerprint == "" {
+				fingerprint = Fingerprint(exception)
+			}
+			if issue.Fingerprint != fingerprint {
+				continue
+			}
+		}
+		return issue, true
+	}
+	return KnownIssue{}, false
+}
+
+// demoteKnown logs an alert of a known issue, rather than capturing it. It returns false if the error does not
+// match a known issue.
+func demoteKnown(exception error) bool {
+	issue, ok := isKnown(exception)
+	if !ok {
+		return false
+	}
+	countKnown(issue.key())
+	log.Printf("alert of known issue (%s) until %s (%s): %+v", issue.key(), issue.Expires.Format(time.RFC3339), issue.Reason, exception)
+	return true
+}
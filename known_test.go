@@ -0,0 +1,57 @@
+package errors_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func knownFailure() error { return errors.New("known failure") }
+
+func TestKnownIssues(t *testing.T) {
+	captured := 0
+	errors.RegisterCapture("TestKnownIssues", func(error, ...any) errors.CaptureID {
+		captured++
+		return "TestKnownIssues"
+	})
+	defer errors.UnregisterCapture("TestKnownIssues")
+	defer errors.SetKnownIssues(nil) //nolint:errcheck
+
+	fingerprint := errors.Fingerprint(knownFailure())
+	manifest := `[
+		{"fingerprint": "` + fingerprint + `", "expires": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `", "reason": "OPS-1"},
+		{"code": "KNOWN-1", "expires": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"},
+		{"code": "EXPIRED-1", "expires": "2000-01-01T00:00:00Z"}
+	]`
+	path := filepath.Join(t.TempDir(), "known.json")
+	assert.NoError(t, os.WriteFile(path, []byte(manifest), 0o600))
+	assert.NoError(t, errors.LoadKnownIssuesFile(path))
+	assert.Len(t, errors.KnownIssues(), 2, "expired issues should not be listed")
+
+	before := errors.Stats()
+	var c *errors.Captured
+	assert.False(t, errors.As(errors.Alert(knownFailure()), &c), "known issue should not be captured")
+	assert.False(t, errors.As(errors.Alert(errors.WithCode(errors.New("by code"), "KNOWN-1")), &c))
+	assert.True(t, errors.As(errors.Alert(errors.WithCode(errors.New("expired"), "EXPIRED-1")), &c))
+	assert.True(t, errors.As(errors.Alert(errors.New("new")), &c))
+	assert.Equal(t, 2, captured)
+
+	after := errors.Stats()
+	assert.Equal(t, before.KnownIssues[fingerprint]+1, after.KnownIssues[fingerprint])
+	assert.Equal(t, before.KnownIssues["code KNOWN-1"]+1, after.KnownIssues["code KNOWN-1"])
+	assert.Equal(t, before.Suppressed+2, after.Suppressed)
+
+	// an issue must identify errors, and expire
+	err := errors.LoadKnownIssues(strings.NewReader(`[{"expires": "2000-01-01T00:00:00Z"}]`))
+	if config, ok := errors.Annotation[errors.ConfigError](err); assert.True(t, ok) {
+		assert.Equal(t, "known_issues[0]", config.Key)
+	}
+	assert.Error(t, errors.SetKnownIssues([]errors.KnownIssue{{Code: "KNOWN-1"}}))
+	assert.Error(t, errors.LoadKnownIssues(strings.NewReader("not JSON")))
+	assert.Len(t, errors.KnownIssues(), 2, "invalid manifests should not replace known issues")
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields are named values describing an error. Errors produced by Errorkv() and Alertkv() pass their fields to
+// capture handlers, as a single argument of this type.
+type Fields map[string]any
+
+// KVStyle formats an error message, given the message and key-value pairs passed to Errorkv() or Alertkv().
+// Programs may replace it to change how all such messages are formatted, i.e. with PlainStyle.
+var KVStyle = LogfmtStyle
+
+// LogfmtStyle appends key-value pairs to a message, in logfmt style. Following the message conventions of this
+// package, the pairs are enclosed in parentheses, i.e. `user not found (user_id=42 region=us-east-1)`.
+func LogfmtStyle(message string, key []string, value []any) string {
+	if len(key) == 0 {
+		return message
+	}
+	pair := make([]string, len(key))
+	for i := range key {
+		text := fmt.Sprint(value[i])
+		if text == "" || strings.ContainsAny(text, " =\"()") {
+			text = strconv.Quote(text)
+		}
+		pair[i] = key[i] + "=" + text
+	}
+	return fmt.Sprintf("%s (%s)", message, strings.Join(pair, " "))
+}
+
+// PlainStyle leaves the message as-is. The key-value pairs are only passed to capture handlers.
+func PlainStyle(message string, _ []string, _ []any) string {
+	return message
+}
+
+// Errorkv produces an error with a message and named values. It is an alternative to Errorf(), for callers who
+// prefer structured key-value pairs.
+//
+//	return errors.Errorkv("user not found", "user_id", 42, "region", "us-east-1")
+//
+// Arguments alternate between keys and values. A key without a value has the value "!MISSING".
+func Errorkv(message string, kv ...any) *Error {
+	return &Error{
+		error:   WithStack(kvError(message, kv)),
+		arg:     []any{fields(kv)},
+		format:  message,
+		created: time.Now(),
+	}
+}
+
+// Alertkv produces an error with a message and named values, and alerts. It is equivalent to calling Errorkv() and
+// then Alert().
+func Alertkv(message string, kv ...any) error {
+	return alert(context.Background(), &Error{
+		// avoid a stack that is redundant with stack produced in alert()
+		error:  kvError(message, kv),
+		arg:    []any{fields(kv)},
+		format: message,
+	})
+}
+
+// kvError produces an error with text formatted according to KVStyle.
+func kvError(message string, kv []any) error {
+	key, value := pairs(kv)
+	return errors.New(KVStyle(message, key, value))
+}
+
+func fields(kv []any) Fields {
+	key, value := pairs(kv)
+	result := make(Fields, len(key))
+	for i := range key {
+		result[key[i]] = value[i]
+	}
+	return result
+}
+
+// pairs splits alternating keys and values.
+func pairs(kv []any) ([]string, []any) {
+	n := (len(kv) + 1) / 2
+	key := make([]string, n)
+	value := make([]any, n)
+	for i := 0; i < n; i++ {
+		key[i] = fmt.Sprint(kv[2*i])
+		if 2*i+1 < len(kv) {
+			value[i] = kv[2*i+1]
+		} else {
+			value[i] = "!MISSING"
+		}
+	}
+	return key, value
+}
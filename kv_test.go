@@ -0,0 +1,33 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorkv(t *testing.T) {
+	err := errors.Errorkv("user not found", "user_id", 42, "region", "us east", "odd")
+	assert.Equal(t, `user not found (user_id=42 region="us east" odd=!MISSING)`, err.Error())
+	assert.Equal(t, "user not found", errors.Redact(err).Error())
+
+	var withStack errors.StackTracer
+	assert.True(t, errors.As(err, &withStack))
+
+	var arg []any
+	errors.RegisterCapture("TestErrorkv", func(_ error, a ...any) errors.CaptureID {
+		arg = a
+		return "TestErrorkv"
+	})
+	defer errors.UnregisterCapture("TestErrorkv")
+
+	_ = errors.Alertkv("quota exceeded", "tenant", "acme", "limit", 10)
+	assert.Equal(t, []any{errors.Fields{"tenant": "acme", "limit": 10}}, arg)
+}
+
+func TestKVStyle(t *testing.T) {
+	defer func(style func(string, []string, []any) string) { errors.KVStyle = style }(errors.KVStyle)
+	errors.KVStyle = errors.PlainStyle
+	assert.Equal(t, "user not found", errors.Errorkv("user not found", "user_id", 42).Error())
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ArgLayer records the arguments supplied by one layer of an error, that is one call to Errorf(), Wrap(),
+// Expand() or similar.
+type ArgLayer struct {
+	// Depth is the position of the layer in the chain of wrapped errors, zero being the outermost.
+	Depth int
+
+	// Message is the text added by the layer, without the text of errors it wraps.
+	Message string
+
+	// Arg are the arguments supplied by the layer.
+	Arg []any
+}
+
+// String describes each argument, along with the layer that supplied it, i.e. `42 (from "handler failed")`.
+func (l ArgLayer) String() string {
+	arg := make([]string, len(l.Arg))
+	for i := range l.Arg {
+		arg[i] = fmt.Sprintf("%v (from %q)", l.Arg[i], l.Message)
+	}
+	return strings.Join(arg, ", ")
+}
+
+// ArgLayers returns the arguments of an error, grouped by the layer that supplied them. The arguments passed to
+// capture handlers are the arguments of all layers, flattened. Capture handlers may use ArgLayers() to present
+// where each argument came from.
+//
+// Only layers with arguments are returned.
+func ArgLayers(exception error) []ArgLayer {
+	var layers []ArgLayer
+	depth := 0
+	Walk(exception, func(ex error) bool {
+		// we don't use As() here, because it could skip over joined errors, instead we walk the entire error tree.
+		withArg, ok := ex.(*Error)
+		if !ok {
+			return true
+		}
+
+		if len(withArg.arg) > 0 {
+			layers = append(layers, ArgLayer{
+				Depth:   depth,
+				Message: ownMessage(withArg),
+				Arg:     withArg.arg,
+			})
+		}
+		depth++
+		return true
+	})
+	return layers
+}
+
+// ownMessage returns the text of an error, without the text of the error it wraps.
+func ownMessage(exception *Error) string {
+	text := exception.Error()
+	for ex := Unwrap(exception.error); ex != nil; ex = Unwrap(ex) {
+		if wrapped := ex.Error(); wrapped != text && strings.HasSuffix(text, ": "+wrapped) {
+			return strings.TrimSuffix(text, ": "+wrapped)
+		}
+	}
+	return text
+}
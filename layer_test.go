@@ -0,0 +1,23 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgLayers(t *testing.T) {
+	inner := errors.Errorf("user (%d) not found", 42)
+	middle := errors.Wrap(inner, "no arguments")
+	outer := errors.Wrapf(middle, "handler (%s) failed", "GET /user")
+
+	layers := errors.ArgLayers(outer)
+	if assert.Len(t, layers, 2) {
+		assert.Equal(t, errors.ArgLayer{Depth: 0, Message: "handler (GET /user) failed", Arg: []any{"GET /user"}}, layers[0])
+		assert.Equal(t, errors.ArgLayer{Depth: 2, Message: "user (42) not found", Arg: []any{42}}, layers[1])
+		assert.Equal(t, `42 (from "user (42) not found")`, layers[1].String())
+	}
+
+	assert.Empty(t, errors.ArgLayers(errors.New("no args")))
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// mustHandle marks an error which must be handled, see MustHandle().
+type mustHandle struct {
+	error
+
+	// where is the function, file and line which marked the error
+	where string
+
+	handled atomic.Bool
+}
+
+// Unwrap allows errors.Unwrap to return the parent error.
+func (e *mustHandle) Unwrap() error { return e.error }
+
+// Format defers to the wrapped error, as the mark does not change the error message.
+func (e *mustHandle) Format(f fmt.State, c rune) {
+	_, _ = fmt.Fprintf(f, fmt.FormatString(f, c), e.error)
+}
+
+// MustHandle marks an error which must not be silently dropped. When dev mode is enabled (see SetDevMode), an
+// error so marked which is garbage collected before it is handled is reported to DevModeReport. This catches
+// errors swallowed by mistake, i.e. in integration tests.
+//
+//	if err := tx.Commit(); err != nil {
+//	  return errors.MustHandle(errors.Wrap(err, "commit failed"))
+//	}
+//
+// An error is handled when it, or an error which wraps it, is alerted, logged by LogCapture or SlogAttrs, redacted
+// (see Redact), encoded (see Encode), or passed to Handled(). Returning an error does not handle it; the caller
+// which receives it becomes responsible for it.
+//
+// When dev mode is disabled, MustHandle returns the error passed in, unchanged.
+func MustHandle(exception error) error {
+	if isNil(exception, "MustHandle") {
+		return nil
+	}
+	if !devMode.Load() {
+		return exception
+	}
+
+	m := &mustHandle{error: exception, where: "unknown"}
+	if pc, file, line, ok := runtime.Caller(1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			m.where = fmt.Sprintf("%s (%s:%d)", fn.Name(), file, line)
+		}
+	}
+	runtime.SetFinalizer(m, func(m *mustHandle) {
+		if !m.handled.Load() {
+			DevModeReport(Errorf("error marked by %s was dropped without being handled: %w", m.where, m.error))
+		}
+	})
+	return m
+}
+
+// Handled records that an error has been handled, when it is deliberately discarded. See MustHandle().
+//
+//	errors.Handled(conn.Close()) // nothing to be done, the connection is no longer needed
+func Handled(exception error) {
+	if exception == nil {
+		return
+	}
+	Walk(exception, func(ex error) bool {
+		if m, ok := ex.(*mustHandle); ok {
+			m.handled.Store(true)
+		}
+		return true
+	})
+}
@@ -0,0 +1,61 @@
+package errors_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMustHandle(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		reported []string
+	)
+	report := errors.DevModeReport
+	errors.DevModeReport = func(problem error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, problem.Error())
+	}
+	defer func() { errors.DevModeReport = report }()
+
+	errors.SetDevMode(true)
+	defer errors.SetDevMode(false)
+
+	func() {
+		dropped := errors.MustHandle(errors.New("TestMustHandle dropped"))
+		discarded := errors.MustHandle(errors.New("TestMustHandle discarded"))
+		logged := errors.MustHandle(errors.New("TestMustHandle logged"))
+		assert.Equal(t, "TestMustHandle dropped", dropped.Error())
+		errors.Handled(errors.Wrap(discarded, "wrapped"))
+		errors.LogCapture(errors.Wrap(logged, "wrapped"))
+	}()
+
+	for i := 0; i < 50; i++ {
+		runtime.GC()
+		mu.Lock()
+		n := len(reported)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, reported, 1) {
+		assert.Contains(t, reported[0], "marked by github.com/memsql/errors_test.TestMustHandle")
+		assert.Contains(t, reported[0], "dropped without being handled: TestMustHandle dropped")
+	}
+
+	errors.SetDevMode(false)
+	err := errors.New("TestMustHandle")
+	assert.Same(t, err, errors.MustHandle(err), "no mark unless dev mode is enabled")
+}
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// CaptureMiddleware wraps a capture handler, returning a handler which does something before or after invoking
+// next, or instead of it. So timing, retries, metrics or filtering may be written once, and applied to any
+// handler, see WrapCapture().
+//
+//	timing := func(provider errors.CaptureProvider, next errors.CaptureHandler) errors.CaptureHandler {
+//	  return func(ctx context.Context, e errors.Event) (errors.CaptureID, error) {
+//	    start := time.Now()
+//	    defer func() { captureSeconds.WithLabelValues(string(provider)).Observe(time.Since(start).Seconds()) }()
+//	    return next(ctx, e)
+//	  }
+//	}
+type CaptureMiddleware func(provider CaptureProvider, next CaptureHandler) CaptureHandler
+
+// WrapCapture wraps the handler registered as name with middleware. The first middleware is the outermost, that
+// is invoked first. A handler registered by RegisterCapture() is wrapped as if registered by RegisterCaptureV2(),
+// returning no error; the ID it returns is unchanged.
+//
+// Middleware is part of the handler, so it is dropped when the handler is replaced, see ReplaceCapture().
+// WrapCapture panics if no handler is registered as name.
+func WrapCapture(name CaptureProvider, mw ...CaptureMiddleware) {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	handler := capture[name]
+	if handler == nil {
+		log.Panicf("capture provider (%q) not registered", name)
+	}
+
+	v2 := captureV2[name]
+	if v2 == nil {
+		v2 = func(_ context.Context, e Event) (CaptureID, error) {
+			return handler(e.Error, e.Arg...), nil
+		}
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		v2 = mw[i](name, v2)
+	}
+	capture[name], captureV2[name] = captureFuncV2(v2), v2
+}
+
+// RetryCapture is middleware which invokes a handler again when it fails, up to attempts times in all, waiting
+// delay between attempts. It gives up early when ctx is done, i.e. when the alert stops waiting for the handler
+// (see CaptureTimeout).
+func RetryCapture(attempts int, delay time.Duration) CaptureMiddleware {
+	return func(_ CaptureProvider, next CaptureHandler) CaptureHandler {
+		return func(ctx context.Context, e Event) (CaptureID, error) {
+			id, err := next(ctx, e)
+			for attempt := 1; err != nil && attempt < attempts; attempt++ {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return "", err
+				case <-timer.C:
+				}
+				id, err = next(ctx, e)
+			}
+			return id, err
+		}
+	}
+}
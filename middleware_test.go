@@ -0,0 +1,54 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapCapture(t *testing.T) {
+	assert.Panics(t, func() { errors.WrapCapture("TestWrapCapture") })
+
+	calls := 0
+	errors.RegisterCapture("TestWrapCapture", func(err error, arg ...any) errors.CaptureID {
+		calls++
+		if calls <= 1 {
+			return ""
+		}
+		return errors.CaptureID(err.Error())
+	})
+	defer errors.UnregisterCapture("TestWrapCapture")
+
+	var order []string
+	trace := func(name string) errors.CaptureMiddleware {
+		return func(provider errors.CaptureProvider, next errors.CaptureHandler) errors.CaptureHandler {
+			assert.Equal(t, errors.CaptureProvider("TestWrapCapture"), provider)
+			return func(ctx context.Context, e errors.Event) (errors.CaptureID, error) {
+				order = append(order, name)
+				id, err := next(ctx, e)
+				if id == "" && err == nil {
+					err = errors.New("skipped")
+				}
+				return id, err
+			}
+		}
+	}
+	errors.WrapCapture("TestWrapCapture", errors.RetryCapture(3, 0), trace("outer"), trace("inner"))
+
+	captured, failed := errors.AlertSync(context.Background(), errors.New("TestWrapCapture"))
+	assert.Empty(t, failed)
+	var c *errors.Captured
+	if assert.True(t, errors.As(captured, &c)) {
+		assert.Equal(t, errors.CaptureID("TestWrapCapture"), c.ID("TestWrapCapture"))
+	}
+	assert.Equal(t, []string{"outer", "inner", "outer", "inner"}, order, "the first middleware should be outermost")
+	assert.Equal(t, 2, calls, "the handler should be retried")
+
+	// attempts are limited
+	calls = -10
+	_, failed = errors.AlertSync(context.Background(), errors.New("TestWrapCapture again"))
+	assert.ErrorContains(t, failed["TestWrapCapture"], "skipped")
+	assert.Equal(t, -7, calls)
+}
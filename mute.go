@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Muted describes errors which are not captured, until some time, see Mute().
+type Muted struct {
+	Fingerprint string
+	Until       time.Time
+	Reason      string
+}
+
+var (
+	muteMu sync.Mutex
+	muted  = map[string]Muted{} // by fingerprint
+)
+
+// Mute prevents errors with a fingerprint (see Fingerprint()) from being captured, until the time passed in.
+// Muted errors are logged when alerted, but not passed to capture handlers. This allows a known, noisy error to
+// be silenced immediately, without a deploy. Muting the same fingerprint again replaces the previous expiration
+// and reason.
+func Mute(fingerprint string, until time.Time, reason string) {
+	muteMu.Lock()
+	defer muteMu.Unlock()
+	muted[fingerprint] = Muted{
+		Fingerprint: fingerprint,
+		Until:       until,
+		Reason:      reason,
+	}
+}
+
+// Unmute allows errors with a fingerprint to be captured, before the time their muting would expire.
+func Unmute(fingerprint string) {
+	muteMu.Lock()
+	defer muteMu.Unlock()
+	delete(muted, fingerprint)
+}
+
+// Mutes lists the fingerprints currently muted, soonest to expire first.
+func Mutes() []Muted {
+	now := time.Now()
+
+	muteMu.Lock()
+	result := make([]Muted, 0, len(muted))
+	for fingerprint, m := range muted {
+		if now.After(m.Until) {
+			delete(muted, fingerprint) // expired
+			continue
+		}
+		result = append(result, m)
+	}
+	muteMu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Until.Before(result[j].Until) })
+	return result
+}
+
+// isMuted returns whether an error is muted and, if so, why.
+func isMuted(exception error) (Muted, bool) {
+	muteMu.Lock()
+	none := len(muted) == 0
+	muteMu.Unlock()
+	if none {
+		return Muted{}, false // avoid computing fingerprint
+	}
+
+	// computing the fingerprint may be slow, so do it without holding the lock
+	fingerprint := Fingerprint(exception)
+
+	muteMu.Lock()
+	defer muteMu.Unlock()
+	m, ok := muted[fingerprint]
+	if !ok {
+		return Muted{}, false
+	}
+	if time.Now().After(m.Until) {
+		delete(muted, fingerprint) // expired
+		return Muted{}, false
+	}
+	return m, true
+}
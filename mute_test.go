@@ -0,0 +1,41 @@
+package errors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func noisy() error { return errors.New("noisy") }
+
+func TestMute(t *testing.T) {
+	captured := 0
+	errors.RegisterCapture("TestMute", func(error, ...any) errors.CaptureID {
+		captured++
+		return "TestMute"
+	})
+	defer errors.UnregisterCapture("TestMute")
+
+	fingerprint := errors.Fingerprint(noisy())
+	errors.Mute(fingerprint, time.Now().Add(time.Hour), "known issue")
+	errors.Mute("expired", time.Now().Add(-time.Second), "expired")
+	defer errors.Unmute(fingerprint)
+
+	mutes := errors.Mutes()
+	if assert.Len(t, mutes, 1, "expired mute should not be listed") {
+		assert.Equal(t, fingerprint, mutes[0].Fingerprint)
+		assert.Equal(t, "known issue", mutes[0].Reason)
+	}
+
+	var c *errors.Captured
+	assert.False(t, errors.As(errors.Alert(noisy()), &c), "muted error should not be captured")
+	assert.True(t, errors.As(errors.Alert(errors.New("not muted")), &c))
+	assert.Equal(t, 1, captured)
+
+	errors.Unmute(fingerprint)
+	assert.Empty(t, errors.Mutes())
+	assert.True(t, errors.As(errors.Alert(noisy()), &c))
+	assert.Equal(t, 2, captured)
+}
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// IsNil returns true if an error is nil, or if it is a non-nil interface holding a nil pointer. The latter
+// usually results from a function that returns a typed pointer, i.e. (*Error)(nil), as an error; which makes
+// `err != nil` true even though there is no error.
+//
+// Functions in this package which accept an error, for example Wrap() and Alert(), treat a nil pointer as nil.
+// When dev mode is enabled (see SetDevMode), they also report where the nil pointer was passed in.
+func IsNil(exception error) bool {
+	if exception == nil {
+		return true
+	}
+	v := reflect.ValueOf(exception)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// Safe returns nil if an error is nil or holds a nil pointer (see IsNil()); otherwise, it returns the error as-is.
+// Functions that build an error of a concrete type may use it to avoid returning a non-nil interface holding a
+// nil pointer.
+//
+//	func validate() error {
+//	  var err *ValidationError
+//	  ...
+//	  return errors.Safe(err)
+//	}
+func Safe(exception error) error {
+	if isNil(exception, "Safe") {
+		return nil
+	}
+	return exception
+}
+
+// isNil is like IsNil. In addition, when dev mode is enabled, it reports a nil pointer passed to the function
+// named where.
+func isNil(exception error, where string) bool {
+	if exception == nil {
+		return true
+	}
+	if !IsNil(exception) {
+		return false
+	}
+	if devMode.Load() {
+		DevModeReport(Errorf("non-nil error holding nil pointer (%T) passed to %s", exception, where))
+	}
+	return true
+}
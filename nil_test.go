@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// typedNil mimics a helper mistakenly returning a nil pointer as an error.
+func typedNil() error {
+	var captured *errors.Captured
+	return captured
+}
+
+func TestIsNil(t *testing.T) {
+	assert.True(t, errors.IsNil(nil))
+	assert.True(t, errors.IsNil(typedNil()))
+	assert.False(t, errors.IsNil(errors.New("TestIsNil")))
+	assert.False(t, errors.IsNil(errors.String("TestIsNil")))
+
+	assert.True(t, typedNil() != nil, "the problem: a non-nil interface holding nil")
+	assert.Nil(t, errors.Safe(typedNil()))
+	assert.Nil(t, errors.Wrap(typedNil(), "TestIsNil"))
+	assert.Nil(t, errors.Annotate(typedNil(), "TestIsNil"))
+	assert.Nil(t, errors.Alert(typedNil()))
+	assert.Nil(t, errors.WrapConfig(typedNil(), "TestIsNil", 1, "integer"))
+
+	err := typedNil()
+	errors.Expand(&err, "TestIsNil")
+	assert.Nil(t, err)
+}
+
+func TestDevModeNil(t *testing.T) {
+	var problems []error
+	defer func(report func(error)) { errors.DevModeReport = report }(errors.DevModeReport)
+	errors.DevModeReport = func(problem error) { problems = append(problems, problem) }
+
+	_ = errors.Wrap(typedNil(), "not in dev mode")
+	assert.Empty(t, problems)
+
+	errors.SetDevMode(true)
+	defer errors.SetDevMode(false)
+	_ = errors.Wrap(typedNil(), "in dev mode")
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, "non-nil error holding nil pointer (*errors.Captured) passed to Wrap", problems[0].Error())
+	}
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// noStack marks an error which should not have a stack trace. It implements StackTracer with an empty stack, so
+// that WithStack() and Errorf() treat it as though it already has one.
+type noStack struct {
+	error
+}
+
+// Unwrap allows errors.Unwrap to return the parent error.
+func (e *noStack) Unwrap() error { return e.error }
+
+// StackTrace is always empty.
+func (e *noStack) StackTrace() StackTrace { return nil }
+
+// Format defers to the wrapped error, as the marker does not change the error message.
+func (e *noStack) Format(f fmt.State, c rune) {
+	_, _ = fmt.Fprintf(f, fmt.FormatString(f, c), e.error)
+}
+
+// NoStack returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and will not acquire a stack trace. WithStack() and Errorf() skip capturing a stack for it and for errors which
+// wrap it, and "%+v" omits its stack.
+//
+// This is intended for sentinels which are expected, returned very often, and sometimes wrapped. For example, a
+// cache miss. Capturing a stack is relatively expensive, and a stack adds little information to such an error.
+//
+//	var ErrCacheMiss = errors.NoStack(errors.String("cache miss"))
+func NoStack(exception error) error {
+	if isNil(exception, "NoStack") {
+		return nil
+	}
+	return &noStack{error: exception}
+}
@@ -0,0 +1,40 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errCacheMiss = errors.NoStack(errors.String("cache miss"))
+
+func TestNoStack(t *testing.T) {
+	assert.Nil(t, errors.NoStack(nil))
+
+	assert.Equal(t, errCacheMiss, errors.WithStack(errCacheMiss), "WithStack should not wrap")
+
+	wrapped := errors.Errorf("lookup (%q): %w", "key", errCacheMiss)
+	assert.ErrorIs(t, wrapped, errCacheMiss)
+	assert.Equal(t, `lookup ("key"): cache miss`, fmt.Sprintf("%+v", wrapped), "no stack expected")
+	assert.Equal(t, "cache miss", fmt.Sprintf("%+v", errCacheMiss))
+
+	var sentinel errors.String
+	assert.True(t, errors.As(wrapped, &sentinel))
+}
+
+func BenchmarkNoStack(b *testing.B) {
+	b.Run("stack", func(b *testing.B) {
+		sentinel := errors.String("cache miss")
+		for i := 0; i < b.N; i++ {
+			_ = errors.Wrap(sentinel, "lookup")
+		}
+	})
+	b.Run("no stack", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = errors.Wrap(errCacheMiss, "lookup")
+		}
+	})
+}
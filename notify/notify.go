@@ -0,0 +1,163 @@
+// Package notify renders errors into notifications for users, i.e. emails and text messages. A notification may
+// use only details of an error which are safe for users to see:
+//
+//	tmpl, err := notify.Parse("sms", "Your export failed ({{.Code}}): {{.Message}}. See {{.DocURL}}")
+//	...
+//	text, err := tmpl.Render(exportErr)
+//
+// The details are the fields of Fields. The message is redacted (see errors.Redact). Templates which refer to
+// anything else are refused by Parse, and are in any case executed with nothing else to refer to.
+package notify
+
+import (
+	"bytes"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/memsql/errors"
+)
+
+// DocBase, if not empty, is the prefix of the URL which documents an error with a code. The URL is DocBase
+// followed by the code, i.e. "https://docs.example.com/errors/" produces "https://docs.example.com/errors/W-1".
+var DocBase string
+
+// Fields are the details of an error which a template may use.
+type Fields struct {
+	// Code is the code of the error, see errors.CodeOf.
+	Code errors.Code
+
+	// Message is the redacted message of the error, see errors.Public.Summary.
+	Message string
+
+	// DocURL documents the code of the error, see DocBase. It is empty if the error has no code.
+	DocURL string
+
+	// RetryAfter is how long the user should wait before trying again, see errors.RetryAfter. It is zero if the
+	// error is not retryable, or has no delay.
+	RetryAfter time.Duration
+}
+
+// NewFields returns the details of an error which are safe for users to see.
+func NewFields(err error) Fields {
+	f := Fields{
+		Code:    errors.CodeOf(err),
+		Message: errors.Redact(err).Summary(),
+	}
+	if DocBase != "" && f.Code != "" {
+		f.DocURL = DocBase + string(f.Code)
+	}
+	f.RetryAfter, _ = errors.RetryAfter(err)
+	return f
+}
+
+// Template is a notification template, see Parse.
+type Template struct {
+	t *template.Template
+}
+
+// Parse parses a notification template, in the syntax of "text/template". The template may refer only to the
+// fields of Fields, i.e. "{{.Code}}", and to methods of those fields, i.e. "{{.RetryAfter.Minutes}}". Templates
+// which refer to other fields, include other templates, or use range or with, are refused.
+func Parse(name, text string) (*Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse notification template (%s)", name)
+	}
+	for _, tree := range t.Templates() {
+		if err := checkNode(tree.Root); err != nil {
+			return nil, errors.Wrapf(err, "notification template (%s) refused", name)
+		}
+	}
+	return &Template{t: t}, nil
+}
+
+// MustParse is like Parse, but panics if the template is refused. It is intended for templates which are
+// constants.
+func MustParse(name, text string) *Template {
+	t, err := Parse(name, text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render fills in a template with the details of an error, see NewFields.
+func (t *Template) Render(err error) (string, error) {
+	buf := &bytes.Buffer{}
+	if execErr := t.t.Execute(buf, NewFields(err)); execErr != nil {
+		return "", errors.Wrapf(execErr, "failed to render notification template (%s)", t.t.Name())
+	}
+	return buf.String(), nil
+}
+
+// allowed are the fields a template may refer to.
+var allowed = map[string]bool{"Code": true, "Message": true, "DocURL": true, "RetryAfter": true}
+
+// checkNode refuses references to anything but the fields of Fields.
+func checkNode(node parse.Node) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkNode(n.Pipe)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return errors.Errorf("range (%s) is not allowed, as it changes what fields refer to", n.Pipe)
+	case *parse.WithNode:
+		return errors.Errorf("with (%s) is not allowed, as it changes what fields refer to", n.Pipe)
+	case *parse.TemplateNode:
+		return errors.Errorf("template (%s) is not allowed", n.Name)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkNode(cmd); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkNode(arg); err != nil {
+				return err
+			}
+		}
+	case *parse.FieldNode:
+		if !allowed[n.Ident[0]] {
+			return errors.Errorf("field (%s) is not allowed", n)
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 && !allowed[n.Ident[1]] {
+			return errors.Errorf("field (%s) is not allowed", n)
+		}
+	case *parse.ChainNode:
+		return checkNode(n.Node)
+	case *parse.TextNode, *parse.DotNode, *parse.IdentifierNode, *parse.StringNode, *parse.NumberNode,
+		*parse.BoolNode, *parse.NilNode, *parse.CommentNode, *parse.BreakNode, *parse.ContinueNode:
+		return nil
+	default:
+		return errors.Errorf("node (%T) is not allowed", node)
+	}
+	return nil
+}
+
+func checkBranch(n *parse.BranchNode) error {
+	if err := checkNode(n.Pipe); err != nil {
+		return err
+	}
+	if err := checkNode(n.List); err != nil {
+		return err
+	}
+	return checkNode(n.ElseList)
+}
@@ -0,0 +1,43 @@
+package notify_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	defer func(base string) { notify.DocBase = base }(notify.DocBase)
+	notify.DocBase = "https://docs.example.com/errors/"
+
+	tmpl := notify.MustParse("sms", `Export failed ({{.Code}}): {{.Message}}.{{if .RetryAfter}} Retry in {{.RetryAfter.Minutes}} minutes.{{end}} See {{$.DocURL}}`)
+
+	err := errors.WithCode(errors.Errorf("export of table (%s) failed", "secret_salaries"), "EXP-1")
+	text, renderErr := tmpl.Render(errors.WithRetryAfter(errors.MarkRetryable(err), 5*time.Minute))
+	assert.NoError(t, renderErr)
+	assert.Equal(t, "Export failed (EXP-1): export of table failed. Retry in 5 minutes. See https://docs.example.com/errors/EXP-1", text)
+
+	text, renderErr = tmpl.Render(err)
+	assert.NoError(t, renderErr)
+	assert.NotContains(t, text, "secret")
+	assert.NotContains(t, text, "Retry")
+}
+
+func TestParseRefused(t *testing.T) {
+	for _, text := range []string{
+		"{{.Error}}",
+		"{{$.Stack}}",
+		"{{if .Code}}{{.Arg}}{{end}}",
+		"{{with .Code}}{{.}}{{end}}",
+		"{{range .Code}}{{end}}",
+		`{{define "x"}}{{.Internal}}{{end}}`,
+		`{{template "x" .}}`,
+		"{{.Code",
+	} {
+		_, err := notify.Parse("refused", text)
+		assert.Error(t, err, text)
+	}
+}
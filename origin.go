@@ -0,0 +1,27 @@
+package errors
+
+// originSkip is the annotation type recording how many frames to skip, to find where an error originated.
+type originSkip int
+
+// WithOriginSkip returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and skips n frames of the stack where it originated, not counting frames of this package. It is
+// intended for generated code, i.e. query wrappers and RPC stubs, whose errors would otherwise originate in
+// generated files. A generated function which returns an error created within it skips itself, so that the origin
+// is the code which called it:
+//
+//	func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
+//	  ...
+//	  if err != nil {
+//	    return User{}, errors.WithOriginSkip(errors.Wrap(err, "get user"), 1)
+//	  }
+//	}
+//
+// The origin determines the fingerprint of the error (see Fingerprint), the package matched by policies, and the
+// location reported by TrimForLog(). The full stack is still shown by "%+v". The outermost skip is honored, and
+// skipping every frame leaves the outermost.
+func WithOriginSkip(exception error, n int) error {
+	if n <= 0 {
+		return Safe(exception)
+	}
+	return Annotate(exception, originSkip(n))
+}
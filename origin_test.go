@@ -0,0 +1,35 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// generatedGetWidget stands in for generated code, which skips its own frame.
+func generatedGetWidget(skip int) error {
+	return errors.WithOriginSkip(errors.Errorf("get widget (%d)", 42), skip)
+}
+
+func originCallerA(skip int) error { return generatedGetWidget(skip) }
+
+func originCallerB(skip int) error { return generatedGetWidget(skip) }
+
+func TestWithOriginSkip(t *testing.T) {
+	assert.Nil(t, errors.WithOriginSkip(nil, 1))
+
+	// without skipping, errors of the generated function have the same origin, wherever it is called
+	defer func(frames int) { errors.FingerprintFrames = frames }(errors.FingerprintFrames)
+	errors.FingerprintFrames = 1
+	assert.Equal(t, errors.Fingerprint(originCallerA(0)), errors.Fingerprint(originCallerB(0)))
+	assert.Contains(t, errors.TrimForLog(originCallerA(0), 200), "generatedGetWidget")
+
+	// skipping the generated function attributes errors to its callers
+	assert.NotEqual(t, errors.Fingerprint(originCallerA(1)), errors.Fingerprint(originCallerB(1)))
+	assert.Contains(t, errors.TrimForLog(originCallerA(1), 200), "originCallerA")
+	assert.Contains(t, errors.TrimForLog(originCallerB(1), 200), "originCallerB")
+
+	// skipping every frame leaves the outermost
+	assert.NotEmpty(t, errors.TrimForLog(originCallerA(1000), 200))
+}
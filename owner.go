@@ -0,0 +1,18 @@
+package errors
+
+// owner is the annotation type recording which team owns an error.
+type owner string
+
+// WithOwner returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and records the team responsible for it, i.e. "team-storage". Capture handlers may use the owner to notify the
+// right people, and alerts may be routed to the providers of a team, see OwnedBy(). Where errors cannot be
+// annotated at their source, a policy may assign an owner by package, see Policy.Owner.
+func WithOwner(exception error, team string) error {
+	return Annotate(exception, owner(team))
+}
+
+// OwnerOf returns the team which owns an error, or the empty string if no owner has been recorded.
+func OwnerOf(exception error) string {
+	team, _ := Annotation[owner](exception)
+	return string(team)
+}
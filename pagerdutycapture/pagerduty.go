@@ -0,0 +1,228 @@
+// Package pagerdutycapture provides a capture handler which triggers PagerDuty alerts, using the Events API (v2).
+//
+//	errors.RegisterCapture("pagerduty", pagerdutycapture.New(pagerdutycapture.Config{
+//	  RoutingKey: os.Getenv("PAGERDUTY_ROUTING_KEY"),
+//	}))
+//
+// The fingerprint of an error (see errors.Fingerprint) is the dedup_key of the alert, so PagerDuty groups
+// recurring errors into one incident. The severity of the alert is derived from errors.SeverityOf, or the kind of
+// error when no severity is specified, and the kind of error (errors.KindOf) is the class of the alert. The owner
+// of the error (errors.OwnerOf) is the group of the alert, and its runbook (errors.RunbookOf) is linked from the
+// alert.
+package pagerdutycapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/memsql/errors"
+)
+
+// DefaultURL is the endpoint of the PagerDuty Events API (v2).
+const DefaultURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxSummary is the longest summary accepted by PagerDuty.
+const maxSummary = 1024
+
+// Config determines how alerts are sent to PagerDuty.
+type Config struct {
+	// RoutingKey is the integration key of a PagerDuty service.
+	RoutingKey string
+
+	// URL of the Events API. If empty, DefaultURL is used.
+	URL string
+
+	// Source is the host where errors occur. If empty, the hostname is used.
+	Source string
+
+	// Severity maps an error to a PagerDuty severity, one of "critical", "error", "warning" or "info". If nil,
+	// Severity is used.
+	Severity func(err error) string
+
+	// Client makes requests to PagerDuty. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Severity maps the severity of an error to the equivalent PagerDuty severity. When no severity is specified, it
+// is derived from the kind of error (errors.KindOf): internal errors are critical, and errors which are the fault
+// of the caller, i.e. invalid or not found, are warnings.
+func Severity(err error) string {
+	severity, ok := errors.Annotation[errors.Severity](err)
+	if !ok {
+		return kindSeverity(errors.KindOf(err))
+	}
+	switch severity {
+	case errors.SeverityCritical:
+		return "critical"
+	case errors.SeverityWarning:
+		return "warning"
+	case errors.SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// kindSeverity maps the kind of an error, of unspecified severity, to a PagerDuty severity.
+func kindSeverity(kind errors.Kind) string {
+	switch kind {
+	case errors.KindInternal:
+		return "critical"
+	case errors.KindInvalid, errors.KindNotFound, errors.KindAlreadyExists, errors.KindPermission,
+		errors.KindUnauthenticated:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// event is the body of a request to the Events API.
+type event struct {
+	RoutingKey  string  `json:"routing_key"`
+	EventAction string  `json:"event_action"`
+	DedupKey    string  `json:"dedup_key,omitempty"`
+	Payload     payload `json:"payload"`
+	Links       []link  `json:"links,omitempty"`
+}
+
+// link is shown with an alert, i.e. the runbook of an error.
+type link struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+type payload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// New produces a capture handler which triggers PagerDuty alerts.
+func New(config Config) errors.CaptureFunc {
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Source == "" {
+		config.Source, _ = os.Hostname()
+	}
+	if config.Severity == nil {
+		config.Severity = Severity
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	return func(exception error, arg ...any) errors.CaptureID {
+		key, err := config.trigger(exception, arg)
+		if err != nil {
+			log.Printf("pagerduty capture failed: %+v", err)
+			return ""
+		}
+		return errors.CaptureID("pagerduty " + key)
+	}
+}
+
+// trigger sends an alert, returning the dedup_key of the alert.
+func (config Config) trigger(exception error, arg []any) (string, error) {
+	summary := exception.Error()
+	if len(summary) > maxSummary {
+		// do not split a character
+		n := maxSummary
+		for n > 0 && !utf8.RuneStart(summary[n]) {
+			n--
+		}
+		summary = summary[:n]
+	}
+
+	details := map[string]any{
+		"error": fmt.Sprintf("%+v", exception),
+	}
+	if code := errors.CodeOf(exception); code != "" {
+		details["code"] = code
+	}
+	if tags := errors.TagsOf(exception); len(tags) > 0 {
+		details["tags"] = tags
+	}
+	if field, ok := errors.FieldPathOf(exception); ok {
+		details["field"] = field.String()
+	}
+	if annotations := errors.Annotations(exception); len(annotations) > 0 {
+		fields := make(map[string]string, len(annotations))
+		for key, value := range annotations {
+			fields[key] = fmt.Sprint(value) // not all values can be encoded as JSON
+		}
+		details["fields"] = fields
+	}
+	if layers := errors.ArgLayers(exception); len(layers) > 0 {
+		args := make([]string, len(layers))
+		for i := range layers {
+			args[i] = layers[i].String() // shows which layer of the error supplied each arg
+		}
+		details["args"] = args
+	} else if len(arg) > 0 {
+		args := make([]string, len(arg))
+		for i := range arg {
+			args[i] = fmt.Sprint(arg[i]) // not all args can be encoded as JSON
+		}
+		details["args"] = args
+	}
+
+	var links []link
+	if runbook := errors.RunbookOf(exception); runbook != "" {
+		links = append(links, link{Href: string(runbook), Text: "Runbook"})
+	}
+
+	body, err := json.Marshal(event{
+		RoutingKey:  config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    errors.Fingerprint(exception),
+		Payload: payload{
+			Summary:       summary,
+			Source:        config.Source,
+			Severity:      config.Severity(exception),
+			Timestamp:     time.Now().Format(time.RFC3339),
+			Group:         errors.OwnerOf(exception),
+			Class:         string(errors.KindOf(exception)),
+			CustomDetails: details,
+		},
+		Links: links,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode event")
+	}
+
+	res, err := config.Client.Post(config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to send event")
+	}
+	defer res.Body.Close()
+
+	response, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read response")
+	}
+	if res.StatusCode != http.StatusAccepted {
+		return "", errors.Errorf("pagerduty responded with status (%d) and body (%q)", res.StatusCode, response)
+	}
+
+	var result struct {
+		Status   string `json:"status"`
+		Message  string `json:"message"`
+		DedupKey string `json:"dedup_key"`
+	}
+	if err := json.Unmarshal(response, &result); err != nil {
+		return "", errors.Wrapf(err, "failed to decode response (%q)", response)
+	}
+	return result.DedupKey, nil
+}
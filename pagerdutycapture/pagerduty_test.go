@@ -0,0 +1,134 @@
+package pagerdutycapture_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/pagerdutycapture"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigger(t *testing.T) {
+	var received []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		received = append(received, event)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, `{"status": "success", "message": "Event processed", "dedup_key": %q}`, event["dedup_key"])
+	}))
+	defer server.Close()
+
+	capture := pagerdutycapture.New(pagerdutycapture.Config{
+		RoutingKey: "routing key",
+		URL:        server.URL,
+		Source:     "test",
+	})
+
+	fail := func(id int) error {
+		err := errors.WithSeverity(errors.Errorf("disk (%d) full", id), errors.SeverityCritical)
+		return errors.WithRunbook(errors.WithTags(errors.WithKind(err, errors.KindUnavailable), "storage"), "https://runbooks.example.com/disk")
+	}
+
+	first := capture(fail(1), 1)
+	second := capture(fail(2), 2)
+	assert.Equal(t, first, second, "same fingerprint should have same dedup key")
+	assert.Equal(t, errors.CaptureID("pagerduty "+errors.Fingerprint(fail(3))), first)
+
+	if assert.Len(t, received, 2) {
+		payload := received[0]["payload"].(map[string]any)
+		assert.Equal(t, "routing key", received[0]["routing_key"])
+		assert.Equal(t, "trigger", received[0]["event_action"])
+		assert.Equal(t, "disk (1) full", payload["summary"])
+		assert.Equal(t, "critical", payload["severity"])
+		assert.Equal(t, "unavailable", payload["class"])
+		assert.Equal(t, "test", payload["source"])
+		assert.Equal(t, []any{"storage"}, payload["custom_details"].(map[string]any)["tags"])
+		assert.Equal(t, []any{map[string]any{"href": "https://runbooks.example.com/disk", "text": "Runbook"}}, received[0]["links"])
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for severity, want := range map[errors.Severity]string{
+		errors.SeverityInfo:     "info",
+		errors.SeverityWarning:  "warning",
+		errors.SeverityError:    "error",
+		errors.SeverityCritical: "critical",
+	} {
+		assert.Equal(t, want, pagerdutycapture.Severity(errors.WithSeverity(errors.New("TestSeverity"), severity)))
+	}
+
+	for kind, want := range map[errors.Kind]string{
+		"":                         "error",
+		errors.KindInternal:        "critical",
+		errors.KindUnavailable:     "error",
+		errors.KindNotFound:        "warning",
+		errors.KindUnauthenticated: "warning",
+	} {
+		assert.Equal(t, want, pagerdutycapture.Severity(errors.WithKind(errors.New("TestSeverity"), kind)), kind)
+	}
+	err := errors.WithSeverity(errors.WithKind(errors.New("TestSeverity"), errors.KindInternal), errors.SeverityInfo)
+	assert.Equal(t, "info", pagerdutycapture.Severity(err), "severity should take precedence over kind")
+}
+
+func TestSummary(t *testing.T) {
+	var summary string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Payload struct {
+				Summary string `json:"summary"`
+			} `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		summary = event.Payload.Summary
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status": "success"}`))
+	}))
+	defer server.Close()
+
+	// the last character which fits is split by the limit
+	capture := pagerdutycapture.New(pagerdutycapture.Config{URL: server.URL})
+	capture(errors.New(strings.Repeat("a", 1023) + "é and more"))
+	assert.Equal(t, strings.Repeat("a", 1023), summary)
+}
+
+func TestFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": "invalid event"}`))
+	}))
+	defer server.Close()
+
+	capture := pagerdutycapture.New(pagerdutycapture.Config{URL: server.URL})
+	assert.Equal(t, errors.CaptureID(""), capture(errors.New("TestFailure")))
+}
+
+func TestArgs(t *testing.T) {
+	var details map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Payload struct {
+				CustomDetails map[string]any `json:"custom_details"`
+			} `json:"payload"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		details = event.Payload.CustomDetails
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status": "success", "dedup_key": "key"}`))
+	}))
+	defer server.Close()
+
+	capture := pagerdutycapture.New(pagerdutycapture.Config{URL: server.URL})
+	err := errors.Wrapf(errors.Errorf("user (%d) not found", 42), "handler (%s) failed", "GET")
+	assert.Equal(t, errors.CaptureID("pagerduty key"), capture(err, "GET", 42))
+	assert.Equal(t, []any{`GET (from "handler (GET) failed")`, `42 (from "user (42) not found")`}, details["args"])
+}
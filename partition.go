@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Partition splits a join of errors (see Join) into the errors which match pred, and the rest. So, for example, a
+// batch job may retry the errors which are retryable, and alert the others:
+//
+//	retry, rest := errors.Partition(err, errors.IsRetryable)
+//
+// Joins within the join are partitioned too. Errors which wrap a join, i.e. with Wrap() or Annotate(), are
+// partitioned by partitioning the join, then wrapping each part as the original was wrapped; so each part keeps
+// the message prefixes, arguments and annotations of the original. pred is called with each joined error wrapped
+// in this way, so annotations outside the join are seen by pred.
+//
+// An error which is not a join is either matching or the rest, as a whole. An error which is entirely matching, or
+// entirely the rest, is returned unchanged.
+func Partition(exception error, pred func(error) bool) (matching, rest error) {
+	return partition(exception, pred, func(e error) error { return e })
+}
+
+// partition partitions exception, which is wrapped by outer.
+func partition(exception error, pred func(error) bool, outer func(error) error) (matching, rest error) {
+	if exception == nil {
+		return nil, nil
+	}
+
+	if branches, ok := joinedErrors(exception); ok {
+		var m, r []error
+		for _, branch := range branches {
+			bm, br := partition(branch, pred, outer)
+			if bm != nil {
+				m = append(m, bm)
+			}
+			if br != nil {
+				r = append(r, br)
+			}
+		}
+		switch {
+		case len(r) == 0:
+			return exception, nil
+		case len(m) == 0:
+			return nil, exception
+		}
+		return Join(m...), Join(r...)
+	}
+
+	if inner, rewrap, ok := partitionLayer(exception); ok && wrapsJoin(inner) {
+		m, r := partition(inner, pred, func(e error) error { return outer(rewrap(e)) })
+		switch {
+		case r == nil:
+			return exception, nil
+		case m == nil:
+			return nil, exception
+		}
+		return rewrap(m), rewrap(r)
+	}
+
+	if pred(outer(exception)) {
+		return exception, nil
+	}
+	return nil, exception
+}
+
+// joinedErrors returns the errors joined by Join(), or by Join() in the standard library. Other errors which wrap
+// several errors, i.e. fmt.Errorf("%w; %w", a, b), are not joins, as their messages are not the joined messages.
+func joinedErrors(exception error) ([]error, bool) {
+	joined, ok := exception.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, false
+	}
+	var branches []error
+	var text []string
+	for _, branch := range joined.Unwrap() {
+		if branch != nil {
+			branches = append(branches, branch)
+			text = append(text, branch.Error())
+		}
+	}
+	return branches, strings.Join(text, "\n") == exception.Error()
+}
+
+// wrapsJoin returns whether an error is a join, or wraps one with layers that partitionLayer() can reproduce.
+func wrapsJoin(exception error) bool {
+	for exception != nil {
+		if _, ok := joinedErrors(exception); ok {
+			return true
+		}
+		inner, _, ok := partitionLayer(exception)
+		if !ok {
+			return false
+		}
+		exception = inner
+	}
+	return false
+}
+
+// partitionLayer returns the error wrapped by an outermost layer, and a function which wraps another error in
+// the same way. It returns false for layers it cannot reproduce.
+func partitionLayer(exception error) (inner error, rewrap func(error) error, ok bool) {
+	switch e := exception.(type) {
+	case *annotated:
+		return e.error, func(x error) error { return &annotated{error: x, value: e.value} }, true
+	case *Captured:
+		return e.error, func(x error) error { return &Captured{error: x, id: e.id, result: e.result} }, true
+	case *Error:
+		// the message of the layer is a prefix of the message of the error it wraps, i.e. as made by Wrap()
+		if !strings.HasSuffix(e.format, "%w") {
+			return nil, nil, false
+		}
+		message := e.Error()
+		for inner = Unwrap(e.error); inner != nil && inner.Error() == message; inner = Unwrap(inner) {
+			// skip layers which do not change the message, i.e. the stack of the layer
+		}
+		if inner == nil || !strings.HasSuffix(message, inner.Error()) {
+			return nil, nil, false
+		}
+		prefix := strings.TrimSuffix(message, inner.Error())
+		return inner, func(x error) error {
+			return &Error{error: WithStack(fmt.Errorf("%s%w", prefix, x)), arg: e.arg, format: e.format, created: e.created}
+		}, true
+	}
+	return nil, nil, false
+}
@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartition(t *testing.T) {
+	a := errors.MarkRetryable(errors.Errorf("row (%d) locked", 1))
+	b := errors.Errorf("row (%d) invalid", 2)
+	c := errors.MarkRetryable(errors.Errorf("row (%d) locked", 3))
+
+	batch := errors.Annotate(errors.Wrapf(errors.Join(a, b, c), "batch (%d) failed", 7), userID(42))
+	retry, rest := errors.Partition(batch, errors.IsRetryable)
+	assert.Equal(t, "batch (7) failed: row (1) locked\nrow (3) locked", retry.Error())
+	assert.Equal(t, "batch (7) failed: row (2) invalid", rest.Error())
+	assert.ErrorIs(t, retry, a)
+	assert.ErrorIs(t, retry, c)
+	assert.NotErrorIs(t, retry, b)
+	assert.ErrorIs(t, rest, b)
+	for _, part := range []error{retry, rest} {
+		id, _ := errors.Annotation[userID](part)
+		assert.Equal(t, userID(42), id, "annotations are kept")
+		var e *errors.Error
+		if assert.True(t, errors.As(part, &e)) {
+			assert.Equal(t, "batch (%d) failed: %w", e.Template(), "templates are kept")
+		}
+	}
+
+	// annotations outside the join are seen by pred
+	all, none := errors.Partition(errors.MarkRetryable(errors.Join(b, errors.New("timeout"))), errors.IsRetryable)
+	assert.Nil(t, none)
+	assert.Equal(t, "row (2) invalid\ntimeout", all.Error())
+
+	// errors which are not joins are partitioned as a whole
+	retry, rest = errors.Partition(a, errors.IsRetryable)
+	assert.Equal(t, a, retry)
+	assert.Nil(t, rest)
+	retry, rest = errors.Partition(b, errors.IsRetryable)
+	assert.Nil(t, retry)
+	assert.Equal(t, b, rest)
+
+	retry, rest = errors.Partition(nil, errors.IsRetryable)
+	assert.Nil(t, retry)
+	assert.Nil(t, rest)
+}
@@ -0,0 +1,153 @@
+package errors
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// Policy adjusts how errors are alerted, based on rules that match errors. Policies allow alerting behavior to be
+// configured in one place, rather than at each call site.
+type Policy struct {
+	// Name identifies the policy.
+	Name string
+
+	// Code, if not empty, limits the policy to errors with the code (see CodeOf).
+	Code Code
+
+	// Tag, if not empty, limits the policy to errors with the tag (see HasTag).
+	Tag string
+
+	// Package, if not empty, limits the policy to errors which originated in the package, or a package below it.
+	// The origin is the first function outside this package, in the innermost stack trace of the error.
+	Package string
+
+	// MinAge, if not zero, limits the policy to errors produced at least MinAge before they are alerted (see Age).
+	// Such errors are stale, having likely sat in a queue, and a policy may decorate them to say so.
+	MinAge time.Duration
+
+	// Match, if not nil, limits the policy to errors for which it returns true.
+	Match func(err error) bool
+
+	// Flag, if not empty, names a feature flag. Errors matching the policy are captured only when the flag is on,
+	// as determined by the evaluator passed to SetFlagEvaluator(). This allows new alerts to be rolled out
+	// gradually. When no evaluator has been set, flags are off.
+	Flag string
+
+	// Owner, Runbook and Severity, if not empty, decorate matching errors. So the team responsible for an error,
+	// and how to respond to it, may be configured in one place. An owner, runbook or severity specified by the
+	// error itself, or by a policy registered earlier, has priority.
+	Owner    string
+	Runbook  Runbook
+	Severity Severity
+}
+
+// matches returns whether a policy applies to an analyzed error.
+func (p Policy) matches(a *Analysis) bool {
+	exception := a.Err()
+	if p.Code != "" && a.Code() != p.Code {
+		return false
+	}
+	if p.Tag != "" && !a.HasTag(p.Tag) {
+		return false
+	}
+	if p.Package != "" {
+		pkg := symbol.Package(origin(exception))
+		if pkg != p.Package && !strings.HasPrefix(pkg, p.Package+"/") {
+			return false
+		}
+	}
+	if p.MinAge != 0 {
+		if created, ok := a.CreatedAt(); !ok || time.Since(created) < p.MinAge {
+			return false
+		}
+	}
+	if p.Match != nil && !p.Match(exception) {
+		return false
+	}
+	return true
+}
+
+// FlagEvaluator returns whether a feature flag is on, for a given error. The error is passed so that the
+// evaluator may consider annotations, for example to evaluate a flag for the tenant affected by the error.
+type FlagEvaluator func(flag string, err error) bool
+
+var (
+	policyMu  sync.RWMutex
+	policies  []Policy // in order of registration
+	evaluator FlagEvaluator
+)
+
+// RegisterPolicy adds a policy that will be applied each time an error is alerted. Policies are applied in the
+// order they are registered.
+func RegisterPolicy(policy Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	for i := range policies {
+		if policies[i].Name == policy.Name {
+			log.Panicf("policy (%q) already registered", policy.Name)
+		}
+	}
+	policies = append(policies, policy)
+}
+
+func UnregisterPolicy(name string) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	for i := range policies {
+		if policies[i].Name == name {
+			policies = append(policies[:i:i], policies[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetFlagEvaluator determines how feature flags referenced by policies are evaluated. Pass nil to turn all flags
+// off.
+func SetFlagEvaluator(f FlagEvaluator) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	evaluator = f
+}
+
+// applyPolicies applies matching policies to an error. It returns nil if a policy prevents the error from being
+// captured, otherwise the error, decorated by the policies.
+func applyPolicies(exception error) error {
+	policyMu.RLock()
+	current, flag := policies, evaluator
+	policyMu.RUnlock()
+	if len(current) == 0 {
+		return exception
+	}
+
+	a := Analyze(exception)
+	_, hasOwner := IndexedAnnotation[owner](a.Indexed)
+	_, hasRunbook := IndexedAnnotation[Runbook](a.Indexed)
+	_, hasSeverity := IndexedAnnotation[Severity](a.Indexed)
+	var decoration []any
+	for _, p := range current {
+		if !p.matches(a) {
+			continue
+		}
+		if p.Flag != "" && (flag == nil || !flag(p.Flag, exception)) {
+			log.Printf("alert not captured, flag (%q) of policy (%q) is off", p.Flag, p.Name)
+			return nil
+		}
+		if p.Owner != "" && !hasOwner {
+			decoration = append(decoration, owner(p.Owner))
+			hasOwner = true
+		}
+		if p.Runbook != "" && !hasRunbook {
+			decoration = append(decoration, p.Runbook)
+			hasRunbook = true
+		}
+		if p.Severity != 0 && !hasSeverity {
+			decoration = append(decoration, p.Severity)
+			hasSeverity = true
+		}
+	}
+	return Annotate(exception, decoration...)
+}
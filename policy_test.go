@@ -0,0 +1,95 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenant string
+
+func TestPolicyFlag(t *testing.T) {
+	var captured []string
+	errors.RegisterCapture("TestPolicyFlag", func(err error, _ ...any) errors.CaptureID {
+		captured = append(captured, err.Error())
+		return "TestPolicyFlag"
+	})
+	defer errors.UnregisterCapture("TestPolicyFlag")
+
+	errors.RegisterPolicy(errors.Policy{
+		Name: "new alert",
+		Code: "NEW-1",
+		Flag: "alert-new-1",
+	})
+	defer errors.UnregisterPolicy("new alert")
+	assert.Panics(t, func() { errors.RegisterPolicy(errors.Policy{Name: "new alert"}) })
+
+	fail := func(who tenant) error {
+		return errors.Annotate(errors.WithCode(errors.Errorf("failed for tenant (%s)", who), "NEW-1"), who)
+	}
+
+	// no evaluator, flag is off
+	_ = errors.Alert(fail("a"))
+	_ = errors.Alert(errors.New("not gated"))
+	assert.Equal(t, []string{"not gated"}, captured)
+
+	errors.SetFlagEvaluator(func(flag string, err error) bool {
+		who, _ := errors.Annotation[tenant](err)
+		return flag == "alert-new-1" && who == "b"
+	})
+	defer errors.SetFlagEvaluator(nil)
+
+	_ = errors.Alert(fail("a"))
+	_ = errors.Alert(fail("b"))
+	assert.Equal(t, []string{"not gated", "failed for tenant (b)"}, captured)
+}
+
+func TestPolicyDecoration(t *testing.T) {
+	var captured []errors.Event
+	errors.RegisterCapture("TestPolicyDecoration", func(err error, arg ...any) errors.CaptureID {
+		captured = append(captured, errors.NewEvent(err, arg...))
+		return "TestPolicyDecoration"
+	})
+	defer errors.UnregisterCapture("TestPolicyDecoration")
+
+	errors.RegisterPolicy(errors.Policy{
+		Name:     "storage",
+		Code:     "DISK-1",
+		Owner:    "team-storage",
+		Runbook:  "https://runbooks.example.com/disk",
+		Severity: errors.SeverityCritical,
+	})
+	defer errors.UnregisterPolicy("storage")
+	errors.RegisterPolicy(errors.Policy{
+		Name:    "errors_test",
+		Package: "github.com/memsql/errors_test",
+		Owner:   "team-errors",
+		Runbook: "https://runbooks.example.com/errors",
+	})
+	defer errors.UnregisterPolicy("errors_test")
+	errors.RegisterPolicy(errors.Policy{
+		Name:    "elsewhere",
+		Package: "github.com/memsql/errors/elsewhere",
+		Owner:   "team-elsewhere",
+	})
+	defer errors.UnregisterPolicy("elsewhere")
+
+	_ = errors.Alert(errors.WithCode(errors.New("disk full"), "DISK-1"))
+	_ = errors.Alert(errors.New("TestPolicyDecoration"))
+	_ = errors.Alert(errors.WithSeverity(errors.WithOwner(errors.WithCode(errors.New("disk slow"), "DISK-1"), "team-disk"), errors.SeverityWarning))
+
+	if assert.Len(t, captured, 3) {
+		assert.Equal(t, "team-storage", captured[0].Owner, "earlier policy has priority")
+		assert.Equal(t, errors.Runbook("https://runbooks.example.com/disk"), captured[0].Runbook)
+		assert.Equal(t, errors.SeverityCritical, captured[0].Severity)
+
+		assert.Equal(t, "team-errors", captured[1].Owner, "policy should match origin package")
+		assert.Equal(t, errors.Runbook("https://runbooks.example.com/errors"), captured[1].Runbook)
+		assert.Equal(t, errors.SeverityError, captured[1].Severity)
+
+		assert.Equal(t, "team-disk", captured[2].Owner, "error has priority over policy")
+		assert.Equal(t, errors.Runbook("https://runbooks.example.com/disk"), captured[2].Runbook)
+		assert.Equal(t, errors.SeverityWarning, captured[2].Severity)
+	}
+}
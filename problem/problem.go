@@ -0,0 +1,86 @@
+// Package problem renders errors as Problem Details for HTTP APIs (RFC 9457), that is, as the body of an
+// "application/problem+json" response.
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	  if err := serve(w, r); err != nil {
+//	    problem.Write(w, r, errors.Alert(err))
+//	  }
+//	}
+//
+// The error is redacted (see errors.Redact), so that the response is safe to send to an unprivileged client. The
+// code of the error, and any capture IDs, are reported as extension members, so that support can find the
+// internal details of an error reported by a user.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/memsql/errors"
+)
+
+// ContentType is the media type of a Problem.
+const ContentType = "application/problem+json"
+
+// TypeBase, if not empty, is the prefix of the type of problems with a code. The type is TypeBase followed by the
+// code, i.e. "https://docs.example.com/errors/" produces "https://docs.example.com/errors/W-1". Problems without a
+// code, or all problems when TypeBase is empty, have no type, which RFC 9457 treats as "about:blank".
+var TypeBase string
+
+// Problem is the body of a problem details response.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Code is the code of the error, see errors.CodeOf.
+	Code errors.Code `json:"code,omitempty"`
+
+	// CaptureID identifies where the error was captured, if it was alerted.
+	CaptureID string `json:"capture_id,omitempty"`
+}
+
+// New describes an error as a problem. The status of the problem is errors.HTTPStatus(err).
+func New(err error) Problem {
+	status := errors.HTTPStatus(err)
+	public := errors.Redact(err)
+	p := Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: public.Summary(),
+		Code:   errors.CodeOf(err),
+	}
+	if TypeBase != "" && p.Code != "" {
+		p.Type = TypeBase + string(p.Code)
+	}
+
+	var captured *errors.Captured
+	if errors.As(err, &captured) {
+		var id []string
+		for _, i := range captured.IDs() {
+			if i != "" {
+				id = append(id, string(i))
+			}
+		}
+		sort.Strings(id)
+		p.CaptureID = strings.Join(id, ", ")
+	}
+	return p
+}
+
+// Write responds to a request with the problem describing an error. The instance of the problem is the path of the
+// request.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := New(err)
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p) // if this fails, the client has gone away
+}
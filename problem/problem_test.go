@@ -0,0 +1,52 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	errors.RegisterCapture("TestWrite", func(error, ...any) errors.CaptureID { return "TestWrite 1" })
+	defer errors.UnregisterCapture("TestWrite")
+
+	problem.TypeBase = "https://docs.example.com/errors/"
+	defer func() { problem.TypeBase = "" }()
+
+	err := errors.WithKind(errors.WithCode(errors.Errorf("widget (%s) not found: %w", "secret", errors.New("no rows")), "W-1"), errors.KindNotFound)
+	recorder := httptest.NewRecorder()
+	problem.Write(recorder, httptest.NewRequest(http.MethodGet, "/widgets/secret", nil), errors.Alert(err))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{
+		"type":       "https://docs.example.com/errors/W-1",
+		"title":      "Not Found",
+		"status":     float64(404),
+		"detail":     "widget not found",
+		"instance":   "/widgets/secret",
+		"code":       "W-1",
+		"capture_id": "TestWrite 1",
+	}, body)
+}
+
+func TestNew(t *testing.T) {
+	p := problem.New(errors.New("something (secret) failed"))
+	assert.Equal(t, problem.Problem{
+		Title:  "Internal Server Error",
+		Status: http.StatusInternalServerError,
+		Detail: "something failed",
+	}, p)
+
+	p = problem.New(errors.WithHTTPStatus(errors.New("slow down"), http.StatusTooManyRequests))
+	assert.Equal(t, http.StatusTooManyRequests, p.Status)
+	assert.Equal(t, "Too Many Requests", p.Title)
+}
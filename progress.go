@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Progress describes how far a long operation got before it failed, i.e. how many tables a backup had copied.
+type Progress struct {
+	Completed int64
+	Total     int64 // zero if not known
+
+	// LastItem identifies the last item completed, i.e. the name of a table.
+	LastItem string
+}
+
+// String describes progress, i.e. "12/40 (30%), last item "orders"".
+func (p Progress) String() string {
+	s := fmt.Sprintf("%d", p.Completed)
+	if p.Total > 0 {
+		s += fmt.Sprintf("/%d (%d%%)", p.Total, p.Completed*100/p.Total)
+	}
+	if p.LastItem != "" {
+		s += fmt.Sprintf(", last item %q", p.LastItem)
+	}
+	return s
+}
+
+// WithProgress returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and records how far an operation got. It is intended for errors of long operations, i.e. when they
+// time out or are canceled, so that an alert shows how much was done without searching logs:
+//
+//	for i, table := range tables {
+//	  if err := copyTable(ctx, table); err != nil {
+//	    return errors.WithProgress(errors.Wrap(err, "backup failed"), errors.Progress{
+//	      Completed: int64(i), Total: int64(len(tables)), LastItem: last,
+//	    })
+//	  }
+//	  last = table
+//	}
+//
+// Progress appears in verbose output ("%+v").
+func WithProgress(exception error, progress Progress) error {
+	return Annotate(exception, progress)
+}
+
+// ProgressOf returns the progress recorded by WithProgress(). The outermost progress has priority.
+func ProgressOf(exception error) (Progress, bool) {
+	return Annotation[Progress](exception)
+}
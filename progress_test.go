@@ -0,0 +1,28 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProgress(t *testing.T) {
+	assert.Nil(t, errors.WithProgress(nil, errors.Progress{}))
+
+	err := errors.WithProgress(errors.Wrap(context.DeadlineExceeded, "backup failed"), errors.Progress{
+		Completed: 12, Total: 40, LastItem: "orders",
+	})
+	assert.Equal(t, "backup failed: context deadline exceeded", err.Error())
+	progress, ok := errors.ProgressOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), progress.Completed)
+	assert.Equal(t, `12/40 (30%), last item "orders"`, progress.String())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "\n--- progress: 12/40 (30%), last item \"orders\"")
+
+	assert.Equal(t, "3", errors.Progress{Completed: 3}.String())
+	_, ok = errors.ProgressOf(errors.New("no progress"))
+	assert.False(t, ok)
+}
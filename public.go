@@ -3,20 +3,30 @@ package errors
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
-)
 
-// parenReg matches parentheticals and preceding space.
-var parenReg = regexp.MustCompile(` \([^()]*\)`)
+	"github.com/memsql/errors/internal/redact"
+)
 
 type Public struct {
 	msg string
 	error
+
+	// summary is msg, without the code and capture IDs
+	summary string
 }
 
 func (e Public) Error() string { return e.msg }
 
+// Summary returns the redacted message and hint, without the code and capture IDs that Error() includes. It is
+// intended for responses which report the code and IDs separately, i.e. as fields of a JSON object.
+func (e Public) Summary() string {
+	if e.summary == "" {
+		return e.msg
+	}
+	return e.summary
+}
+
 func (e Public) Unwrap() error { return e.error }
 
 // Redact removes potential sensitive details from an error, making the message safe to display to an
@@ -25,20 +35,50 @@ func (e Public) Unwrap() error { return e.error }
 // Redact removes content in parenthesis.  That is, it expects only errors that follow the convention that
 // potentially sensitive information appears in parentheses. Also that errors are relatively simple,
 // i.e. without nested parentheses.
+//
+// Identifiers recorded by WithCluster, WithWorkspace and WithDatabase are replaced wherever they appear, i.e.
+// "workspace <workspace> is suspended".
+//
+// The hint of the error, if any (see WithHint), follows the redacted message. The code of the error (see CodeOf),
+// its correlation ID if it was alerted (see CorrelationIDOf), and any capture IDs are appended to the redacted
+// message, i.e. "widget failed [code W42] [ref 3f2a...] [...]". Note that errors with a code or correlation ID
+// used to be redacted without the "[code ...]" and "[ref ...]" suffixes; the suffixes now appear in
+// Redact(err).Error(), and so in the messages of errors produced by Expunge(). Use Public.Summary() for the
+// message without them.
 func Redact(err error) Public {
 	p, ok := err.(Public)
 	if ok {
 		return p
 	}
+	Handled(err)
 
 	long := err.Error()
 
 	// remove the parts in parens
-	long = parenReg.ReplaceAllString(long, "")
+	long = redact.Parens(long)
+
+	// replace identifiers of clusters, workspaces and databases, which may appear outside parens
+	long = scrubResources(err, long)
 
 	// truncate at the first colon (shows the top error an not lower-level detail)
 	split := strings.SplitN(long, ":", 2)
-	short := split[0] // part preceding first ":"
+	summary := split[0] // part preceding first ":"
+
+	// keep the hint, which is meant for users, see WithHint()
+	if advice := Hint(err); advice != "" {
+		summary = fmt.Sprintf("%s; %s", summary, scrubResources(err, redact.Parens(advice)))
+	}
+	short := summary
+
+	// append the code, so that support can map a user's report to the internal error
+	if code := CodeOf(err); code != "" {
+		short = fmt.Sprintf("%s [code %s]", short, code)
+	}
+
+	// append the correlation ID, a reference even when no capture handler captured the error
+	if id := CorrelationIDOf(err); id != "" {
+		short = fmt.Sprintf("%s [ref %s]", short, id)
+	}
 
 	// append any capture IDs
 	captured := &Captured{}
@@ -46,5 +86,40 @@ func Redact(err error) Public {
 		short = fmt.Sprintf("%s [%s]", short, captured.allID())
 	}
 
-	return Public{short, err} // public error is stripped of all dynamic detail
+	return Public{short, err, summary} // public error is stripped of all dynamic detail
+}
+
+// Internal renders an error for engineers: the verbose message (see "%+v"), with stack traces, followed by the
+// arguments of each layer of the error. It is not safe to show to users, see PublicView() and SupportView().
+//
+//	log.Print(errors.Internal(err))
+//	http.Error(w, errors.PublicView(err), errors.HTTPStatus(err))
+func Internal(err error) string {
+	if err == nil {
+		return ""
+	}
+	b := &strings.Builder{}
+	_, _ = fmt.Fprintf(b, "%+v", err)
+	for _, layer := range ArgLayers(err) {
+		_, _ = fmt.Fprintf(b, "\n--- args: %s", layer)
+	}
+	return b.String()
+}
+
+// PublicView renders an error for the users of a program: the redacted message and hint, see Redact() and
+// Public.Summary().
+func PublicView(err error) string {
+	if err == nil {
+		return ""
+	}
+	return Redact(err).Summary()
+}
+
+// SupportView renders an error for support staff: the redacted message and hint, followed by the code, the
+// correlation ID and capture IDs which find the error among those captured, see Redact().
+func SupportView(err error) string {
+	if err == nil {
+		return ""
+	}
+	return Redact(err).Error()
 }
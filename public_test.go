@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/memsql/errors"
@@ -24,3 +25,61 @@ func TestRedact(t *testing.T) {
 		}
 	}
 }
+
+func TestRedactSummary(t *testing.T) {
+	redacted := errors.Redact(errors.WithCode(errors.Errorf("widget (%d) failed: %w", 42, errors.New("no rows")), "W-1"))
+	if redacted.Error() != "widget failed [code W-1]" {
+		t.Errorf("errors.Redact() produced %q", redacted)
+	}
+	if redacted.Summary() != "widget failed" {
+		t.Errorf("Summary() returned %q, wanted message without code", redacted.Summary())
+	}
+}
+
+func TestRedactHint(t *testing.T) {
+	err := errors.WithCode(errors.Errorf("batch (%d rows) too large: %w", 5000, errors.New("limit exceeded")), "B-1")
+	err = errors.WithHint(err, "try reducing the batch size (to 1000)")
+	if hint := errors.Hint(err); hint != "try reducing the batch size (to 1000)" {
+		t.Errorf("Hint() returned %q", hint)
+	}
+	redacted := errors.Redact(err)
+	if redacted.Error() != "batch too large; try reducing the batch size [code B-1]" {
+		t.Errorf("errors.Redact() produced %q", redacted)
+	}
+	if redacted.Summary() != "batch too large; try reducing the batch size" {
+		t.Errorf("Summary() returned %q, wanted message and hint", redacted.Summary())
+	}
+
+	expunged := func() (err error) {
+		defer errors.Expunge(&err, "import (%s) failed", "orders.csv")
+		return errors.WithHint(errors.New("bad row"), "check the file is CSV")
+	}()
+	if redacted := errors.Redact(expunged); redacted.Error() != "import failed; check the file is CSV" {
+		t.Errorf("errors.Redact() of expunged error produced %q", redacted)
+	}
+	if hint := errors.Hint(errors.Decode(errors.Encode(err))); hint != "try reducing the batch size (to 1000)" {
+		t.Errorf("hint did not survive encoding, got %q", hint)
+	}
+}
+
+func TestViews(t *testing.T) {
+	errors.RegisterCapture("TestViews", func(error, ...any) errors.CaptureID { return "view-id" })
+	defer errors.UnregisterCapture("TestViews")
+
+	err := errors.Alert(errors.WithCode(errors.Errorf("widget (%d) failed: %w", 42, errors.New("no rows")), "W-1"))
+	if view := errors.PublicView(err); view != "widget failed" {
+		t.Errorf("PublicView() returned %q", view)
+	}
+	ref := errors.CorrelationIDOf(err)
+	if view := errors.SupportView(err); view != "widget failed [code W-1] [ref "+string(ref)+"] [view-id]" {
+		t.Errorf("SupportView() returned %q", view)
+	}
+	view := errors.Internal(err)
+	if !strings.HasPrefix(view, "widget (42) failed: no rows [view-id]\n") || !strings.Contains(view, "TestViews") ||
+		!strings.HasSuffix(view, "\n--- args: 42 (from \"widget (42) failed\")") {
+		t.Errorf("Internal() returned %q", view)
+	}
+	if errors.Internal(nil) != "" || errors.PublicView(nil) != "" || errors.SupportView(nil) != "" {
+		t.Error("views of nil should be empty")
+	}
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Capture handlers are invoked by a pool of workers, which take them from a bounded queue. So however many errors
+// are alerted, at most captureWorkers handlers are started at once. A worker waits for a handler at most
+// CaptureTimeout, then leaves it to finish on its own goroutine, so that a deadlocked or slow handler does not
+// starve the others. When the queue is full, handlers are not invoked, and capture is saturated, see
+// CaptureLimit.Saturated.
+const (
+	captureWorkers   = 64
+	captureQueueSize = 1024
+)
+
+var (
+	workersOnce sync.Once
+	workers     atomic.Pointer[captureQueue]
+)
+
+// captureQueue holds capture handlers waiting for a worker.
+type captureQueue struct {
+	mu     sync.RWMutex
+	jobs   chan func()
+	closed bool
+}
+
+func newCaptureQueue(workers, size int) *captureQueue {
+	q := &captureQueue{jobs: make(chan func(), size)}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// captureWork returns the queue of capture handlers, starting its workers when first called.
+func captureWork() *captureQueue {
+	workersOnce.Do(func() {
+		workers.CompareAndSwap(nil, newCaptureQueue(captureWorkers, captureQueueSize))
+	})
+	return workers.Load()
+}
+
+// submit queues a capture handler, to be invoked by a worker. It returns false, and does not queue the handler,
+// when the queue is full.
+func (q *captureQueue) submit(job func()) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// close lets the workers exit, once they have started the handlers queued.
+func (q *captureQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+}
+
+func (q *captureQueue) work() {
+	for job := range q.jobs {
+		done := make(chan struct{})
+		go func(job func()) {
+			defer close(done)
+			job()
+		}(job)
+
+		timer := time.NewTimer(CaptureTimeout)
+		select {
+		case <-done:
+		case <-timer.C: // the handler continues without us
+		}
+		timer.Stop()
+	}
+}
+
+// stopCaptureWork stops the workers, once they have started the handlers queued, see Close().
+func stopCaptureWork() {
+	if q := workers.Load(); q != nil {
+		q.close()
+	}
+}
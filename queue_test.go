@@ -0,0 +1,79 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureQueue(t *testing.T) {
+	started, unblock := make(chan struct{}), make(chan struct{})
+	errors.RegisterCapture("TestCaptureQueue", func(err error, _ ...any) errors.CaptureID {
+		if err.Error() == "blocked" {
+			started <- struct{}{}
+			<-unblock
+		}
+		return "TestCaptureQueue"
+	})
+	defer errors.UnregisterCapture("TestCaptureQueue")
+
+	var saturations []errors.Saturation
+	errors.SetCaptureLimit(errors.CaptureLimit{
+		Saturated: func(s errors.Saturation) { saturations = append(saturations, s) },
+	})
+	defer errors.SetCaptureLimit(errors.CaptureLimit{})
+
+	// one worker, and room in the queue for one handler
+	defer errors.SetCaptureQueue(1, 1)()
+
+	go errors.Alert(errors.String("blocked")) //nolint:errcheck
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("handler not invoked")
+	}
+
+	// the worker is blocked, so the next handler waits in the queue, and the one after is not invoked
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	errors.AlertContext(ctx, errors.New("queued")) //nolint:errcheck
+
+	var c *errors.Captured
+	err := errors.Alert(errors.New("TestCaptureQueue"))
+	if assert.True(t, errors.As(err, &c)) {
+		result := c.Result("TestCaptureQueue")
+		assert.Equal(t, errors.CaptureFailed, result.Status)
+		assert.ErrorIs(t, result.Err, errors.ErrCaptureSaturated)
+	}
+	if assert.Len(t, saturations, 1) {
+		assert.Equal(t, "TestCaptureQueue", saturations[0].Dropped.Error())
+	}
+
+	close(unblock)
+	assert.NoError(t, errors.Flush(context.Background()))
+}
+
+func TestCaptureQueueDeadlock(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	var captured []string
+	errors.RegisterCapture("TestCaptureQueueDeadlock", func(err error, _ ...any) errors.CaptureID {
+		if err.Error() == "deadlocked" {
+			<-unblock
+			return ""
+		}
+		captured = append(captured, err.Error())
+		return "TestCaptureQueueDeadlock"
+	})
+	defer errors.UnregisterCapture("TestCaptureQueueDeadlock")
+
+	// the only worker stops waiting for the deadlocked handler, after CaptureTimeout
+	defer errors.SetCaptureQueue(1, 1)()
+	errors.Alert(errors.String("deadlocked"))            //nolint:errcheck
+	errors.Alert(errors.New("TestCaptureQueueDeadlock")) //nolint:errcheck
+	assert.Equal(t, []string{"TestCaptureQueueDeadlock"}, captured)
+}
@@ -0,0 +1,122 @@
+// Package regression detects errors which are new in the current release of a program.
+//
+// A Detector is given a baseline, the fingerprints (see errors.Fingerprint) of errors seen in previous releases.
+// When registered as an alert hook, it marks alerted errors whose fingerprint is not in the baseline, and
+// escalates their severity. This answers the first question usually asked after a deploy: is this error new?
+//
+//	detector := regression.New(baseline...)
+//	errors.RegisterAlertHook("regression", detector.Hook)
+//
+// The fingerprints seen while the program runs are available from Detector.Seen, so they may be saved and
+// become part of the baseline for the next release.
+package regression
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/memsql/errors"
+)
+
+// FirstSeen annotates an error which is new in a release.
+type FirstSeen struct {
+	Release string
+}
+
+// IsNew returns whether an error has been marked as new in the current release.
+func IsNew(err error) bool {
+	_, ok := errors.Annotation[FirstSeen](err)
+	return ok
+}
+
+// Detector marks errors not seen in previous releases.
+type Detector struct {
+	// Escalate is the severity of errors new in this release. If zero, the severity of a new error is raised by
+	// one level (up to errors.SeverityCritical).
+	Escalate errors.Severity
+
+	baseline map[string]bool
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// New produces a Detector, given the fingerprints of errors seen in previous releases.
+func New(baseline ...string) *Detector {
+	d := &Detector{
+		baseline: make(map[string]bool, len(baseline)),
+		seen:     map[string]bool{},
+	}
+	for _, fingerprint := range baseline {
+		d.baseline[fingerprint] = true
+	}
+	return d
+}
+
+// ReadBaseline reads fingerprints, one per line, as written by WriteSeen. Blank lines are ignored.
+func ReadBaseline(r io.Reader) ([]string, error) {
+	var baseline []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			baseline = append(baseline, line)
+		}
+	}
+	return baseline, errors.Wrap(scanner.Err(), "failed to read baseline")
+}
+
+// Hook is an errors.AlertHook, which marks and escalates errors not in the baseline.
+func (d *Detector) Hook(exception error) error {
+	fingerprint := errors.Fingerprint(exception)
+
+	d.mu.Lock()
+	d.seen[fingerprint] = true
+	d.mu.Unlock()
+
+	if d.baseline[fingerprint] {
+		return exception
+	}
+
+	severity := d.Escalate
+	if severity == 0 {
+		severity = errors.SeverityOf(exception) + 1
+		if severity > errors.SeverityCritical {
+			severity = errors.SeverityCritical
+		}
+	}
+	return errors.Annotate(exception, FirstSeen{Release: errors.Release}, severity)
+}
+
+// Seen returns the fingerprints of errors alerted since the Detector was created, in sorted order.
+func (d *Detector) Seen() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := make([]string, 0, len(d.seen))
+	for fingerprint := range d.seen {
+		seen = append(seen, fingerprint)
+	}
+	sort.Strings(seen)
+	return seen
+}
+
+// WriteSeen writes the fingerprints of errors seen, one per line, combined with the baseline. The result may be
+// the baseline of the next release.
+func (d *Detector) WriteSeen(w io.Writer) error {
+	d.mu.Lock()
+	all := make([]string, 0, len(d.seen)+len(d.baseline))
+	for fingerprint := range d.seen {
+		all = append(all, fingerprint)
+	}
+	for fingerprint := range d.baseline {
+		if !d.seen[fingerprint] {
+			all = append(all, fingerprint)
+		}
+	}
+	d.mu.Unlock()
+	sort.Strings(all)
+	_, err := io.WriteString(w, strings.Join(all, "\n")+"\n")
+	return errors.Wrap(err, "failed to write fingerprints")
+}
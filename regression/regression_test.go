@@ -0,0 +1,54 @@
+package regression_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/regression"
+	"github.com/stretchr/testify/assert"
+)
+
+func oldFailure() error { return errors.New("old failure") }
+
+func newFailure() error { return errors.New("new failure") }
+
+func TestDetector(t *testing.T) {
+	baseline := errors.Fingerprint(oldFailure())
+	detector := regression.New(baseline)
+	errors.RegisterAlertHook("TestDetector", detector.Hook)
+	defer errors.UnregisterAlertHook("TestDetector")
+
+	var severity []errors.Severity
+	var isNew []bool
+	errors.RegisterCapture("TestDetector", func(err error, _ ...any) errors.CaptureID {
+		severity = append(severity, errors.SeverityOf(err))
+		isNew = append(isNew, regression.IsNew(err))
+		return ""
+	})
+	defer errors.UnregisterCapture("TestDetector")
+
+	_ = errors.Alert(oldFailure())
+	_ = errors.Alert(newFailure())
+	_ = errors.Alert(errors.WithSeverity(newFailure(), errors.SeverityCritical))
+
+	assert.Equal(t, []bool{false, true, true}, isNew)
+	assert.Equal(t, []errors.Severity{errors.SeverityError, errors.SeverityCritical, errors.SeverityCritical}, severity)
+	assert.Len(t, detector.Seen(), 2)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, detector.WriteSeen(buf))
+	next, err := regression.ReadBaseline(buf)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, detector.Seen(), next)
+}
+
+func TestEscalate(t *testing.T) {
+	detector := regression.New()
+	detector.Escalate = errors.SeverityWarning
+	err := detector.Hook(errors.WithSeverity(newFailure(), errors.SeverityInfo))
+	assert.True(t, regression.IsNew(err))
+	assert.Equal(t, errors.SeverityWarning, errors.SeverityOf(err))
+	first, _ := errors.Annotation[regression.FirstSeen](err)
+	assert.Equal(t, errors.Release, first.Release)
+}
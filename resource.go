@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"github.com/memsql/errors/internal/redact"
+)
+
+// ClusterID identifies the SingleStore cluster an error concerns.
+type ClusterID string
+
+// WorkspaceID identifies the SingleStore workspace an error concerns.
+type WorkspaceID string
+
+// DatabaseName is the name of the database an error concerns.
+type DatabaseName string
+
+// Keys of the identifiers returned by ResourceTags().
+const (
+	ClusterTag   = "cluster_id"
+	WorkspaceTag = "workspace_id"
+	DatabaseTag  = "database"
+)
+
+// WithCluster returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and records the cluster it concerns.
+//
+// The identifiers recorded by WithCluster, WithWorkspace and WithDatabase are scrubbed from the message of
+// Redact(), wherever they appear, and are forwarded to capture providers as tags (see ResourceTags).
+func WithCluster(exception error, id ClusterID) error {
+	return Annotate(exception, id)
+}
+
+// WithWorkspace returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and records the workspace it concerns. See WithCluster().
+func WithWorkspace(exception error, id WorkspaceID) error {
+	return Annotate(exception, id)
+}
+
+// WithDatabase returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and records the database it concerns. See WithCluster().
+func WithDatabase(exception error, name DatabaseName) error {
+	return Annotate(exception, name)
+}
+
+// ClusterOf returns the cluster an error concerns, or the empty string if none has been recorded.
+func ClusterOf(exception error) ClusterID {
+	id, _ := Annotation[ClusterID](exception)
+	return id
+}
+
+// WorkspaceOf returns the workspace an error concerns, or the empty string if none has been recorded.
+func WorkspaceOf(exception error) WorkspaceID {
+	id, _ := Annotation[WorkspaceID](exception)
+	return id
+}
+
+// DatabaseOf returns the database an error concerns, or the empty string if none has been recorded.
+func DatabaseOf(exception error) DatabaseName {
+	name, _ := Annotation[DatabaseName](exception)
+	return name
+}
+
+// ResourceTags returns the identifiers an error concerns, keyed by ClusterTag, WorkspaceTag and DatabaseTag, or
+// nil if none have been recorded. Capture handlers may forward them as tags, so that providers can search errors
+// by cluster, workspace or database.
+func ResourceTags(exception error) map[string]string {
+	return resourceTags(Index(exception))
+}
+
+func resourceTags(ix *Indexed) map[string]string {
+	cluster, _ := IndexedAnnotation[ClusterID](ix)
+	workspace, _ := IndexedAnnotation[WorkspaceID](ix)
+	database, _ := IndexedAnnotation[DatabaseName](ix)
+	var result map[string]string
+	for key, value := range map[string]string{
+		ClusterTag:   string(cluster),
+		WorkspaceTag: string(workspace),
+		DatabaseTag:  string(database),
+	} {
+		if value == "" {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// scrubResources replaces the identifiers an error concerns, wherever they appear in a message, with the kind of
+// identifier, i.e. "<workspace>".
+func scrubResources(exception error, msg string) string {
+	for _, r := range []struct{ value, placeholder string }{
+		{string(ClusterOf(exception)), "<cluster>"},
+		{string(WorkspaceOf(exception)), "<workspace>"},
+		{string(DatabaseOf(exception)), "<database>"},
+	} {
+		if r.value != "" {
+			msg = redact.Word(msg, r.value, r.placeholder)
+		}
+	}
+	return msg
+}
@@ -0,0 +1,30 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResources(t *testing.T) {
+	var err error = errors.Errorf("cannot attach database db to workspace ws-7f3a: dbs of ws-7f3a-old are busy")
+	err = errors.WithDatabase(errors.WithWorkspace(errors.WithCluster(err, "c-1"), "ws-7f3a"), "db")
+
+	assert.Equal(t, errors.ClusterID("c-1"), errors.ClusterOf(err))
+	assert.Equal(t, errors.WorkspaceID("ws-7f3a"), errors.WorkspaceOf(err))
+	assert.Equal(t, errors.DatabaseName("db"), errors.DatabaseOf(err))
+	assert.Equal(t, map[string]string{"cluster_id": "c-1", "workspace_id": "ws-7f3a", "database": "db"},
+		errors.ResourceTags(err))
+	assert.Equal(t, errors.ResourceTags(err), errors.NewEvent(err).Resources)
+	assert.Nil(t, errors.ResourceTags(errors.New("TestResources")))
+
+	assert.Equal(t, "cannot attach database <database> to workspace <workspace>", errors.Redact(err).Error())
+
+	// only whole identifiers are replaced
+	other := errors.WithDatabase(errors.Errorf("database db is busy, and dbs of ws-7f3a-old are idle"), "db")
+	assert.Equal(t, "database <database> is busy, and dbs of ws-7f3a-old are idle", errors.Redact(other).Error())
+
+	decoded := errors.Decode(errors.Encode(err))
+	assert.Equal(t, errors.ResourceTags(err), errors.ResourceTags(decoded))
+}
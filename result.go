@@ -0,0 +1,141 @@
+package errors
+
+import (
+	"context"
+)
+
+// CaptureStatus is the outcome of a capture handler, when an error is alerted.
+type CaptureStatus int
+
+const (
+	// CaptureOK means the handler recorded the error, and returned an ID.
+	CaptureOK CaptureStatus = iota + 1
+
+	// CaptureTimedOut means the handler did not finish before the alert stopped waiting for it. It may still
+	// record the error later, but its ID is not known.
+	CaptureTimedOut
+
+	// CapturePanicked means the handler panicked.
+	CapturePanicked
+
+	// CaptureSkipped means the handler finished without an ID, i.e. because it chose not to record the error.
+	CaptureSkipped
+
+	// CaptureSampled means the handler was not invoked, as the provider samples identical alerts, see
+	// SetCaptureSampling().
+	CaptureSampled
+
+	// CaptureFailed means the handler returned an error, see RegisterCaptureV2(), or was not invoked, as capture
+	// was saturated or closed.
+	CaptureFailed
+)
+
+func (s CaptureStatus) String() string {
+	switch s {
+	case CaptureOK:
+		return "ok"
+	case CaptureTimedOut:
+		return "timed out"
+	case CapturePanicked:
+		return "panicked"
+	case CaptureSkipped:
+		return "skipped"
+	case CaptureSampled:
+		return "sampled"
+	case CaptureFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// CaptureResult is the outcome of a capture handler, see Captured.Result().
+type CaptureResult struct {
+	Status CaptureStatus
+
+	// ID is returned by the handler, when Status is CaptureOK.
+	ID CaptureID
+
+	// Err describes why the handler failed, when Status is CaptureTimedOut, CapturePanicked or CaptureFailed. When
+	// the handler was not invoked, as capture was saturated or closed, it wraps ErrCaptureSaturated or
+	// ErrCaptureClosed.
+	Err error
+}
+
+// Failed returns whether the handler timed out, panicked, or returned an error.
+func (r CaptureResult) Failed() bool {
+	return r.Status == CaptureTimedOut || r.Status == CapturePanicked || r.Status == CaptureFailed
+}
+
+// Result returns the outcome of the capture handler registered as provider. The zero value, with a Status of
+// "unknown", is returned if the provider was not registered when the error was alerted.
+func (e *Captured) Result(provider CaptureProvider) CaptureResult {
+	return e.result[provider]
+}
+
+// Results returns the outcome of each capture handler invoked to capture the error.
+func (e *Captured) Results() map[CaptureProvider]CaptureResult {
+	result := make(map[CaptureProvider]CaptureResult, len(e.result))
+	for provider := range e.result {
+		result[provider] = e.result[provider]
+	}
+	return result
+}
+
+// AlertResults are the outcomes of the capture handlers invoked by an alert, by provider, see AlertResult().
+type AlertResults map[CaptureProvider]CaptureResult
+
+// Captured returns whether at least one handler recorded the error.
+func (r AlertResults) Captured() bool {
+	for _, result := range r {
+		if result.Status == CaptureOK {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed returns the providers whose handlers timed out, panicked, or returned an error, with why.
+func (r AlertResults) Failed() map[CaptureProvider]error {
+	var failed map[CaptureProvider]error
+	for provider, result := range r {
+		if result.Failed() {
+			if failed == nil {
+				failed = map[CaptureProvider]error{}
+			}
+			failed[provider] = result.Err
+		}
+	}
+	return failed
+}
+
+// AlertResult is like Alert(), and also returns the outcome of each capture handler, so that a caller can tell
+// whether the alert was recorded anywhere. The results are empty when no handler was meant to be invoked, i.e.
+// because none is registered, or because the alert was muted or deduplicated. When handlers were not invoked, as
+// capture was saturated or closed, each has a result of CaptureFailed.
+//
+//	captured, results := errors.AlertResult(err)
+//	if !results.Captured() {
+//	  fmt.Fprintf(os.Stderr, "alert not captured: %+v\n", captured)
+//	}
+func AlertResult(err error) (captured error, results AlertResults) {
+	if isNil(err, "AlertResult") {
+		return nil, nil
+	}
+
+	if alerted, ok := uncaptured(err); ok {
+		return alerted, AlertResults{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CaptureTimeout)
+	defer cancel()
+	captured, failed := alertContext(ctx, err)
+	if c, ok := captured.(*Captured); ok {
+		return captured, c.Results()
+	}
+	results = AlertResults{}
+	for provider, err := range failed {
+		results[provider] = CaptureResult{Status: CaptureFailed, Err: err}
+	}
+	return captured, results
+}
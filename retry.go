@@ -0,0 +1,49 @@
+package errors
+
+import "time"
+
+// retryable is the annotation type recording whether an operation which failed may be retried.
+type retryable bool
+
+// retryAfter is the annotation type recording how long to wait before retrying.
+type retryAfter time.Duration
+
+// MarkRetryable returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and indicates that the operation which failed may succeed if retried.
+//
+//	if resp.StatusCode == http.StatusServiceUnavailable {
+//	  return errors.MarkRetryable(errors.Errorf("upstream (%s) unavailable", host))
+//	}
+func MarkRetryable(exception error) error {
+	return Annotate(exception, retryable(true))
+}
+
+// MarkPermanent returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and indicates that the operation which failed should not be retried, even if a wrapped error is
+// retryable.
+func MarkPermanent(exception error) error {
+	return Annotate(exception, retryable(false))
+}
+
+// WithRetryAfter is like MarkRetryable(), and also records how long to wait before retrying. Use RetryAfter() to
+// find the delay.
+func WithRetryAfter(exception error, delay time.Duration) error {
+	return Annotate(exception, retryable(true), retryAfter(delay))
+}
+
+// IsRetryable returns whether the operation which produced an error may be retried. The outermost marker, see
+// MarkRetryable() and MarkPermanent(), decides. Errors without a marker are not retryable.
+func IsRetryable(exception error) bool {
+	r, _ := Annotation[retryable](exception)
+	return bool(r)
+}
+
+// RetryAfter returns how long to wait before retrying, if a delay was recorded by WithRetryAfter() and the error
+// is retryable.
+func RetryAfter(exception error) (time.Duration, bool) {
+	if !IsRetryable(exception) {
+		return 0, false
+	}
+	delay, ok := Annotation[retryAfter](exception)
+	return time.Duration(delay), ok
+}
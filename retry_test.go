@@ -0,0 +1,33 @@
+package errors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	err := errors.New("TestRetryable")
+	assert.False(t, errors.IsRetryable(err), "errors are not retryable by default")
+	assert.Nil(t, errors.MarkRetryable(nil))
+
+	err = errors.MarkRetryable(err)
+	assert.True(t, errors.IsRetryable(errors.Wrap(err, "wrapped")))
+	_, ok := errors.RetryAfter(err)
+	assert.False(t, ok, "no delay recorded")
+
+	permanent := errors.MarkPermanent(errors.Wrap(err, "gave up"))
+	assert.False(t, errors.IsRetryable(permanent), "outermost marker decides")
+	assert.Equal(t, "gave up: TestRetryable", permanent.Error())
+
+	delayed := errors.WithRetryAfter(errors.New("TestRetryable throttled"), 3*time.Second)
+	assert.True(t, errors.IsRetryable(delayed))
+	delay, ok := errors.RetryAfter(errors.Wrap(delayed, "wrapped"))
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	_, ok = errors.RetryAfter(errors.MarkPermanent(delayed))
+	assert.False(t, ok, "permanent errors have no delay")
+}
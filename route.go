@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"sync"
+)
+
+// Route decides whether an alert is passed to the handler of a provider, see RouteCapture().
+type Route func(event Event) bool
+
+// routes are set by RouteCapture().
+var routes struct {
+	mu    sync.RWMutex
+	route map[CaptureProvider]Route
+}
+
+// RouteCapture limits the alerts passed to the handler of a provider to those for which route returns true. So
+// a provider which pages someone may be passed only critical alerts, while a provider which logs is passed every
+// alert. Routes are decided after hooks and SetBeforeCapture(), so the event is what the handler would be passed.
+// An alert not routed to a provider is not captured by it; it has no result for that provider (see
+// Captured.Results), and is not counted in Stats() for it. Pass a nil route to pass every alert, which is the
+// default.
+//
+//	errors.RouteCapture("pagerduty", errors.SeverityAtLeast(errors.SeverityCritical))
+func RouteCapture(provider CaptureProvider, route Route) {
+	routes.mu.Lock()
+	defer routes.mu.Unlock()
+	if route == nil {
+		delete(routes.route, provider)
+		return
+	}
+	if routes.route == nil {
+		routes.route = map[CaptureProvider]Route{}
+	}
+	routes.route[provider] = route
+}
+
+// SeverityAtLeast routes alerts with a severity at least as urgent as the one passed in, see SeverityOf().
+func SeverityAtLeast(severity Severity) Route {
+	return func(event Event) bool { return event.Severity >= severity }
+}
+
+// Tagged routes alerts which have any of the tags passed in, see WithTags().
+func Tagged(tags ...string) Route {
+	return func(event Event) bool {
+		for _, have := range event.Tags {
+			for _, want := range tags {
+				if have == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// OfKind routes alerts which have any of the kinds passed in, see KindOf().
+func OfKind(kinds ...Kind) Route {
+	return func(event Event) bool {
+		for _, kind := range kinds {
+			if event.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OwnedBy routes alerts owned by any of the teams passed in, see WithOwner(). Pass "" to route alerts which no team
+// owns. So each team may be paged by a provider of its own, and a catch-all provider is passed the rest:
+//
+//	errors.RegisterCapture("pagerduty storage", pagerdutycapture.New(pagerdutycapture.Config{RoutingKey: storageKey}))
+//	errors.RouteCapture("pagerduty storage", errors.OwnedBy("team-storage"))
+//	errors.RegisterCapture("pagerduty", pagerdutycapture.New(pagerdutycapture.Config{RoutingKey: defaultKey}))
+//	errors.RouteCapture("pagerduty", errors.OwnedBy(""))
+func OwnedBy(teams ...string) Route {
+	return func(event Event) bool {
+		for _, team := range teams {
+			if event.Owner == team {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// routeOut removes from handlers the providers to which an alert is not routed. The event, if not nil, has been
+// returned by the function set by SetBeforeCapture().
+func routeOut(handlers map[CaptureProvider]CaptureFunc, exception error, arg []any, event *Event) {
+	routes.mu.RLock()
+	defer routes.mu.RUnlock()
+	for provider := range handlers {
+		route, ok := routes.route[provider]
+		if !ok {
+			continue
+		}
+		if event == nil {
+			e := NewEvent(exception, arg...)
+			event = &e
+		}
+		if !route(*event) {
+			delete(handlers, provider)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteCapture(t *testing.T) {
+	var paged, logged []string
+	errors.RegisterCapture("TestRouteCapture pager", func(err error, _ ...any) errors.CaptureID {
+		paged = append(paged, err.Error())
+		return "paged"
+	})
+	defer errors.UnregisterCapture("TestRouteCapture pager")
+	errors.RegisterCapture("TestRouteCapture log", func(err error, _ ...any) errors.CaptureID {
+		logged = append(logged, err.Error())
+		return "logged"
+	})
+	defer errors.UnregisterCapture("TestRouteCapture log")
+
+	errors.RouteCapture("TestRouteCapture pager", func(e errors.Event) bool {
+		return errors.SeverityAtLeast(errors.SeverityCritical)(e) || errors.Tagged("billing")(e)
+	})
+	defer errors.RouteCapture("TestRouteCapture pager", nil)
+
+	alerted, failed := errors.AlertSync(context.Background(), errors.New("minor"))
+	assert.Empty(t, failed)
+	var captured *errors.Captured
+	if assert.True(t, errors.As(alerted, &captured)) {
+		assert.Equal(t, errors.CaptureOK, captured.Result("TestRouteCapture log").Status)
+		_, routed := captured.Results()["TestRouteCapture pager"]
+		assert.False(t, routed)
+	}
+
+	_ = errors.Alert(errors.Criticalf("major"))
+	_ = errors.Alert(errors.WithTags(errors.New("invoice"), "billing"))
+	assert.Equal(t, []string{"major", "invoice"}, paged)
+	assert.Equal(t, []string{"minor", "major", "invoice"}, logged)
+
+	errors.RouteCapture("TestRouteCapture pager", errors.OfKind(errors.KindNotFound))
+	_ = errors.Alert(errors.Criticalf("critical, but not found"))
+	_ = errors.Alert(errors.WithKind(errors.New("not found"), errors.KindNotFound))
+	assert.Equal(t, []string{"major", "invoice", "not found"}, paged)
+}
+
+func TestOwnedBy(t *testing.T) {
+	paged := map[errors.CaptureProvider][]string{}
+	for _, provider := range []errors.CaptureProvider{"TestOwnedBy storage", "TestOwnedBy default"} {
+		provider := provider
+		errors.RegisterCapture(provider, func(err error, _ ...any) errors.CaptureID {
+			paged[provider] = append(paged[provider], err.Error())
+			return "paged"
+		})
+		defer errors.UnregisterCapture(provider)
+	}
+	errors.RouteCapture("TestOwnedBy storage", errors.OwnedBy("team-storage", "team-disk"))
+	defer errors.RouteCapture("TestOwnedBy storage", nil)
+	errors.RouteCapture("TestOwnedBy default", errors.OwnedBy(""))
+	defer errors.RouteCapture("TestOwnedBy default", nil)
+
+	_ = errors.Alert(errors.WithOwner(errors.New("disk full"), "team-storage"))
+	_ = errors.Alert(errors.WithOwner(errors.New("bad sector"), "team-disk"))
+	_ = errors.Alert(errors.WithOwner(errors.New("port closed"), "team-network"))
+	_ = errors.Alert(errors.New("unowned"))
+	assert.Equal(t, map[errors.CaptureProvider][]string{
+		"TestOwnedBy storage": {"disk full", "bad sector"},
+		"TestOwnedBy default": {"unowned"},
+	}, paged)
+}
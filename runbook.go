@@ -0,0 +1,18 @@
+package errors
+
+// Runbook is the URL of instructions for responding to an error. Capture handlers may include it in alerts, so the
+// remediation link reaches the person on call; see Event.Runbook. Verbose formatting ("%+v") shows it on the line
+// after the message, ahead of stack traces.
+type Runbook string
+
+// WithRunbook returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and has the runbook passed in.
+func WithRunbook(exception error, url string) error {
+	return Annotate(exception, Runbook(url))
+}
+
+// RunbookOf returns the runbook of an error, or the empty string if no runbook has been specified.
+func RunbookOf(exception error) Runbook {
+	runbook, _ := Annotation[Runbook](exception)
+	return runbook
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"math"
+	"sync"
+)
+
+// sampling is configured by SetCaptureSampling().
+var sampling struct {
+	mu   sync.Mutex
+	rate map[CaptureProvider]float64
+	seen map[CaptureProvider]map[string]int // alerts by fingerprint, by provider
+}
+
+// SetCaptureSampling passes only a fraction of identical alerts, that is alerts with the same fingerprint (see
+// Fingerprint()), to the handler of a provider. The first alert of a fingerprint is always passed, then a rate of
+// those which follow, i.e. with a rate of 0.1, the first alert and every tenth after it. Handlers of other
+// providers, i.e. LogCapture, still see every alert. Use it for high-volume providers which bill, or rate limit,
+// by event. Unlike a Throttle, which limits the alerts of a call site for all providers, sampling applies to
+// every alert, for one provider.
+//
+// An alert not passed to a handler has the result CaptureSampled. A rate of one or more removes sampling; a rate
+// of zero or less passes only the first alert of each fingerprint.
+//
+//	errors.SetCaptureSampling("sentry", 0.1)
+func SetCaptureSampling(provider CaptureProvider, rate float64) {
+	sampling.mu.Lock()
+	defer sampling.mu.Unlock()
+	if rate >= 1 {
+		delete(sampling.rate, provider)
+		delete(sampling.seen, provider)
+		return
+	}
+	if sampling.rate == nil {
+		sampling.rate = map[CaptureProvider]float64{}
+		sampling.seen = map[CaptureProvider]map[string]int{}
+	}
+	sampling.rate[provider] = math.Max(rate, 0)
+	sampling.seen[provider] = map[string]int{}
+}
+
+// sampledOut returns the providers which are not passed an alert, because they sample alerts.
+func sampledOut(exception error, handlers map[CaptureProvider]CaptureFunc) map[CaptureProvider]bool {
+	sampling.mu.Lock()
+	defer sampling.mu.Unlock()
+	if len(sampling.rate) == 0 {
+		return nil // avoid computing fingerprint
+	}
+
+	var (
+		result      map[CaptureProvider]bool
+		fingerprint string
+	)
+	for provider := range handlers {
+		rate, ok := sampling.rate[provider]
+		if !ok {
+			continue
+		}
+		if fingerprint == "" {
+			fingerprint = Fingerprint(exception)
+		}
+		seen := sampling.seen[provider]
+		if len(seen) >= maxSeen {
+			seen = map[string]int{} // start counting over, rather than grow without bound
+			sampling.seen[provider] = seen
+		}
+		seen[fingerprint]++
+		n := float64(seen[fingerprint] - 1) // alerts which followed the first
+		if n > 0 && math.Floor(n*rate) == math.Floor((n-1)*rate) {
+			if result == nil {
+				result = map[CaptureProvider]bool{}
+			}
+			result[provider] = true
+		}
+	}
+	return result
+}
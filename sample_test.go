@@ -0,0 +1,57 @@
+package errors_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleFailure(id int) error {
+	return errors.Errorf("widget (%d) failed", id)
+}
+
+func TestSetCaptureSampling(t *testing.T) {
+	// handlers run concurrently
+	var mu sync.Mutex
+	count := map[errors.CaptureProvider]int{}
+	counted := func(provider errors.CaptureProvider) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return count[provider]
+	}
+	for _, provider := range []errors.CaptureProvider{"TestSetCaptureSampling sampled", "TestSetCaptureSampling all"} {
+		provider := provider
+		errors.RegisterCapture(provider, func(error, ...any) errors.CaptureID {
+			mu.Lock()
+			defer mu.Unlock()
+			count[provider]++
+			return errors.CaptureID(provider)
+		})
+		defer errors.UnregisterCapture(provider)
+	}
+	errors.SetCaptureSampling("TestSetCaptureSampling sampled", 0.25)
+	defer errors.SetCaptureSampling("TestSetCaptureSampling sampled", 1)
+
+	var c *errors.Captured
+	for id := 0; id < 9; id++ {
+		err := errors.Alert(sampleFailure(id))
+		if id == 1 && assert.True(t, errors.As(err, &c)) {
+			assert.Equal(t, errors.CaptureSampled, c.Result("TestSetCaptureSampling sampled").Status)
+			assert.Equal(t, errors.CaptureOK, c.Result("TestSetCaptureSampling all").Status)
+		}
+	}
+	assert.Equal(t, 3, counted("TestSetCaptureSampling sampled"), "first, fourth and eighth after it")
+	assert.Equal(t, 9, counted("TestSetCaptureSampling all"))
+
+	// another fingerprint is sampled separately
+	errors.Alert(errors.New("something else"))
+	assert.Equal(t, 4, counted("TestSetCaptureSampling sampled"))
+
+	errors.SetCaptureSampling("TestSetCaptureSampling sampled", 1)
+	errors.Alert(sampleFailure(9))
+	errors.Alert(sampleFailure(10))
+	assert.Equal(t, 6, counted("TestSetCaptureSampling sampled"))
+	assert.Equal(t, "sampled", errors.CaptureSampled.String())
+}
@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// Sentinel describes a String registered with RegisterSentinel().
+type Sentinel struct {
+	String String
+
+	// Package is the import path of the package which registered the sentinel.
+	Package string
+
+	// Location is the file and line where the sentinel was registered.
+	Location string
+}
+
+var (
+	sentinelMu sync.Mutex
+	sentinels  = map[String]Sentinel{}
+)
+
+// RegisterSentinel records String errors, typically in an init() function of the package that defines them.
+//
+//	const ErrNoDroids = errors.String("these are not the droids you're looking for")
+//
+//	func init() {
+//	  errors.RegisterSentinel(ErrNoDroids)
+//	}
+//
+// Two String errors with the same text satisfy errors.Is() of each other, even when defined by unrelated
+// packages. RegisterSentinel panics when the same text is registered more than once, so the conflict is found
+// when the program starts.
+func RegisterSentinel(s ...String) {
+	where := Sentinel{}
+	if pc, file, line, ok := runtime.Caller(1); ok {
+		where.Location = fmt.Sprintf("%s:%d", file, line)
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			where.Package = symbol.Package(fn.Name())
+		}
+	}
+
+	sentinelMu.Lock()
+	defer sentinelMu.Unlock()
+	for _, text := range s {
+		if existing, ok := sentinels[text]; ok {
+			log.Panicf("sentinel (%q) registered at %s is already registered at %s", text, where.Location, existing.Location)
+		}
+		where.String = text
+		sentinels[text] = where
+	}
+}
+
+// Sentinels lists the registered String errors, sorted by package and text. It is intended for tools, for
+// example to document the errors a program may return.
+func Sentinels() []Sentinel {
+	sentinelMu.Lock()
+	result := make([]Sentinel, 0, len(sentinels))
+	for _, s := range sentinels {
+		result = append(result, s)
+	}
+	sentinelMu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Package != result[j].Package {
+			return result[i].Package < result[j].Package
+		}
+		return result[i].String < result[j].String
+	})
+	return result
+}
+
+// bindings maps sentinels to their annotations. It is replaced, never modified, so it may be read without a lock.
+var bindings atomic.Pointer[map[String][]any]
+
+// BindSentinel associates a String error with a code and kind. Any error which wraps the sentinel has that code
+// and kind (see CodeOf() and KindOf()), unless a code or kind is specified by a layer wrapping the sentinel. So
+// callers need not annotate errors at each call site.
+//
+//	const ErrNotFound = errors.String("not found")
+//
+//	func init() {
+//	  errors.BindSentinel(ErrNotFound, "NF-001", errors.KindNotFound)
+//	}
+//
+// A sentinel may be bound only once. BindSentinel panics if the sentinel is already bound.
+func BindSentinel(s String, code Code, kind Kind) {
+	sentinelMu.Lock()
+	defer sentinelMu.Unlock()
+
+	current := map[String][]any{}
+	if p := bindings.Load(); p != nil {
+		current = *p
+	}
+	if _, ok := current[s]; ok {
+		log.Panicf("sentinel (%q) is already bound", s)
+	}
+
+	next := make(map[String][]any, len(current)+1)
+	for k, v := range current {
+		next[k] = v
+	}
+	var value []any
+	if code != "" {
+		value = append(value, code)
+	}
+	if kind != "" {
+		value = append(value, kind)
+	}
+	next[s] = value
+	bindings.Store(&next)
+}
+
+// boundValues returns the annotations bound to an error, if it is a sentinel.
+func boundValues(exception error) []any {
+	p := bindings.Load()
+	if p == nil {
+		return nil
+	}
+	switch e := exception.(type) {
+	case String:
+		return (*p)[e]
+	case errorString:
+		return (*p)[e.s]
+	}
+	return nil
+}
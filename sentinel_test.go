@@ -0,0 +1,55 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	errSentinelOne errors.String = "TestRegisterSentinel one"
+	errSentinelTwo errors.String = "TestRegisterSentinel two"
+)
+
+const errBoundNotFound errors.String = "TestBindSentinel not found"
+
+func init() {
+	errors.RegisterSentinel(errSentinelOne, errSentinelTwo)
+	errors.BindSentinel(errBoundNotFound, "NF-001", errors.KindNotFound)
+}
+
+func TestRegisterSentinel(t *testing.T) {
+	var found []errors.Sentinel
+	for _, s := range errors.Sentinels() {
+		if s.String == errSentinelOne || s.String == errSentinelTwo {
+			found = append(found, s)
+		}
+	}
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, errSentinelOne, found[0].String)
+		assert.Equal(t, "github.com/memsql/errors_test", found[0].Package)
+		assert.Contains(t, found[0].Location, "sentinel_test.go")
+	}
+
+	// same text, different constant
+	const duplicate errors.String = "TestRegisterSentinel one"
+	assert.Panics(t, func() { errors.RegisterSentinel(duplicate) })
+}
+
+func TestBindSentinel(t *testing.T) {
+	errNotFound := errBoundNotFound
+	assert.Panics(t, func() { errors.BindSentinel(errNotFound, "NF-002", errors.KindNotFound) })
+
+	err := errors.Wrap(errNotFound, "failed to find widget")
+	assert.Equal(t, errors.Code("NF-001"), errors.CodeOf(err))
+	assert.Equal(t, errors.KindNotFound, errors.KindOf(err))
+
+	err = errNotFound.Errorf("widget (%d) not found", 42)
+	assert.Equal(t, errors.Code("NF-001"), errors.CodeOf(err))
+
+	// explicit annotation has priority
+	err = errors.WithCode(errors.Wrap(errNotFound, "failed"), "OTHER")
+	assert.Equal(t, errors.Code("OTHER"), errors.CodeOf(err))
+	assert.Equal(t, errors.KindNotFound, errors.KindOf(err))
+}
@@ -0,0 +1,18 @@
+module github.com/memsql/errors/sentrycapture
+
+go 1.20
+
+require (
+	github.com/getsentry/sentry-go v0.27.0
+	github.com/memsql/errors v0.0.0-20261016134454-a87a81350d90
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,158 @@
+// Package sentrycapture provides a capture handler which sends errors to Sentry.
+//
+//	err := sentry.Init(sentry.ClientOptions{Dsn: os.Getenv("SENTRY_DSN")})
+//	...
+//	errors.RegisterCapture("sentry", sentrycapture.New(sentrycapture.Config{}))
+//
+// The message of an error is the value of the Sentry exception, and its template (see errors.Event) is the type, so
+// Sentry titles issues by what went wrong rather than by the values involved. The stack of the error becomes the
+// stack trace of the exception, with frames of the app (see errors.ClassifyFrame) marked in_app. The fingerprint of
+// the error (see errors.Fingerprint) groups events into issues. The arguments and named values of an error are sent
+// as extras; its kind, code, severity, owner and correlation ID, the cluster, workspace and database it concerns
+// (see errors.ResourceTags), and its tags with values (see errors.Tag), as tags.
+//
+// The capture ID is "sentry " followed by the ID of the Sentry event.
+//
+// This package is a separate module, so that programs which do not use Sentry do not depend on its SDK.
+package sentrycapture
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/memsql/errors"
+)
+
+// Config determines how errors are sent to Sentry.
+type Config struct {
+	// Hub sends events. If nil, the current hub of the Sentry SDK is used, see sentry.Init.
+	Hub *sentry.Hub
+
+	// Level maps an error to a Sentry level. If nil, Level is used.
+	Level func(err error) sentry.Level
+}
+
+// Level maps the severity of an error to the equivalent Sentry level.
+func Level(err error) sentry.Level {
+	switch errors.SeverityOf(err) {
+	case errors.SeverityCritical:
+		return sentry.LevelFatal
+	case errors.SeverityWarning:
+		return sentry.LevelWarning
+	case errors.SeverityInfo:
+		return sentry.LevelInfo
+	default:
+		return sentry.LevelError
+	}
+}
+
+// New produces a capture handler which sends errors to Sentry.
+func New(config Config) errors.CaptureFunc {
+	if config.Level == nil {
+		config.Level = Level
+	}
+
+	return func(exception error, arg ...any) errors.CaptureID {
+		hub := config.Hub
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+		event := NewEvent(exception, arg...)
+		event.Level = config.Level(exception)
+		id := hub.CaptureEvent(event)
+		if id == nil {
+			return "" // not sent, i.e. Sentry was not initialized or the event was sampled out
+		}
+		return errors.CaptureID("sentry " + string(*id))
+	}
+}
+
+// NewEvent converts an error, and the arguments passed to a capture handler, to a Sentry event.
+func NewEvent(exception error, arg ...any) *sentry.Event {
+	e := errors.NewEvent(exception, arg...)
+
+	event := sentry.NewEvent()
+	event.Timestamp = e.Time
+	event.Release = e.Release
+	event.Message = e.Message
+	event.Fingerprint = []string{e.Fingerprint}
+
+	typ := e.Template
+	if typ == "" {
+		typ = fmt.Sprintf("%T", exception)
+	}
+	event.Exception = []sentry.Exception{{
+		Type:       typ,
+		Value:      e.Message,
+		Stacktrace: stacktrace(exception),
+	}}
+
+	for key, value := range map[string]string{
+		"kind":           string(e.Kind),
+		"code":           string(e.Code),
+		"owner":          e.Owner,
+		"severity":       e.Severity.String(),
+		"correlation_id": string(e.CorrelationID),
+	} {
+		if value != "" {
+			event.Tags[key] = value
+		}
+	}
+
+	for key, value := range e.Resources {
+		event.Tags[key] = value
+	}
+	for key, value := range e.TagValues {
+		event.Tags[key] = value
+	}
+
+	if len(e.Tags) > 0 {
+		event.Extra["tags"] = e.Tags
+	}
+	if e.Runbook != "" {
+		event.Extra["runbook"] = string(e.Runbook)
+	}
+	for key, value := range errors.Annotations(exception) {
+		event.Extra[key] = fmt.Sprint(value) // not all values can be encoded as JSON
+	}
+	if len(e.Layers) > 0 {
+		args := make([]string, len(e.Layers))
+		for i := range e.Layers {
+			args[i] = e.Layers[i].String() // shows which layer of the error supplied each arg
+		}
+		event.Extra["args"] = args
+	} else if len(arg) > 0 {
+		args := make([]string, len(arg))
+		for i := range arg {
+			args[i] = fmt.Sprint(arg[i])
+		}
+		event.Extra["args"] = args
+	}
+	return event
+}
+
+// stacktrace converts the deepest stack of an error to a Sentry stack trace, or returns nil if the error has no
+// stack.
+func stacktrace(exception error) *sentry.Stacktrace {
+	var stack errors.StackTrace
+	errors.Walk(exception, func(ex error) bool {
+		if tracer, ok := ex.(errors.StackTracer); ok && len(tracer.StackTrace()) > 0 {
+			stack = tracer.StackTrace() // keep walking, inner stacks are closer to the origin
+		}
+		return true
+	})
+	if len(stack) == 0 {
+		return nil
+	}
+
+	// Sentry expects the outermost frame first, the reverse of a Go stack.
+	frames := make([]sentry.Frame, 0, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		f, _ := runtime.CallersFrames([]uintptr{uintptr(stack[i])}).Next()
+		frame := sentry.NewFrame(f)
+		frame.InApp = errors.ClassifyFrame(stack[i]) == errors.FrameApp
+		frames = append(frames, frame)
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
@@ -0,0 +1,68 @@
+package sentrycapture_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/sentrycapture"
+	"github.com/stretchr/testify/assert"
+)
+
+type transport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transport) Configure(sentry.ClientOptions) {}
+func (t *transport) Flush(time.Duration) bool       { return true }
+func (t *transport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func TestCapture(t *testing.T) {
+	tr := &transport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: tr})
+	if !assert.NoError(t, err) {
+		return
+	}
+	errors.RegisterCapture("TestCapture", sentrycapture.New(sentrycapture.Config{Hub: sentry.NewHub(client, sentry.NewScope())}))
+	defer errors.UnregisterCapture("TestCapture")
+
+	exception := errors.WithKind(errors.Wrapf(errors.New("disk full"), "widget (%d) failed", 42), errors.KindUnavailable)
+	exception = errors.WithSeverity(errors.AnnotateKV(exception, "tenant", "acme"), errors.SeverityCritical)
+	exception = errors.WithTagValue(errors.WithWorkspace(exception, "ws-1"), "region", "us-east-1")
+	captured := errors.Alert(exception)
+
+	if !assert.Len(t, tr.events, 1) {
+		return
+	}
+	event := tr.events[0]
+	var c *errors.Captured
+	if assert.True(t, errors.As(captured, &c)) {
+		assert.Equal(t, errors.CaptureID("sentry "+string(event.EventID)), c.ID("TestCapture"))
+	}
+	assert.Equal(t, sentry.LevelFatal, event.Level)
+	assert.Equal(t, []string{errors.Fingerprint(exception)}, event.Fingerprint)
+	assert.Equal(t, "unavailable", event.Tags["kind"])
+	assert.Equal(t, "ws-1", event.Tags["workspace_id"])
+	assert.Equal(t, "us-east-1", event.Tags["region"])
+	assert.Equal(t, "acme", event.Extra["tenant"])
+	assert.Equal(t, []string{`42 (from "widget (42) failed")`}, event.Extra["args"])
+	if assert.Len(t, event.Exception, 1) {
+		assert.Equal(t, "widget (%d) failed: %w", event.Exception[0].Type)
+		assert.Equal(t, "widget (42) failed: disk full", event.Exception[0].Value)
+		frames := event.Exception[0].Stacktrace.Frames
+		var inApp []string
+		for _, frame := range frames {
+			if frame.InApp {
+				inApp = append(inApp, frame.Function)
+			}
+		}
+		assert.Equal(t, []string{"TestCapture"}, inApp)
+	}
+}
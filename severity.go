@@ -0,0 +1,53 @@
+package errors
+
+// Severity indicates how urgently an error requires attention.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// WithSeverity returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and has the severity passed in.
+func WithSeverity(exception error, severity Severity) error {
+	return Annotate(exception, severity)
+}
+
+// SeverityOf returns the severity of an error. Errors have SeverityError, unless another severity has been
+// specified.
+func SeverityOf(exception error) Severity {
+	if severity, ok := Annotation[Severity](exception); ok {
+		return severity
+	}
+	return SeverityError
+}
+
+// Warnf produces an error, like Errorf(), with SeverityWarning. Use it for errors that deserve attention, but not
+// urgently.
+func Warnf(format string, a ...any) error {
+	return WithSeverity(Errorf(format, a...), SeverityWarning)
+}
+
+// Criticalf produces an error, like Errorf(), with SeverityCritical. Use it for errors that require immediate
+// attention, i.e. to page someone when alerted.
+func Criticalf(format string, a ...any) error {
+	return WithSeverity(Errorf(format, a...), SeverityCritical)
+}
@@ -0,0 +1,43 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverity(t *testing.T) {
+	err := errors.New("TestSeverity")
+	assert.Equal(t, errors.SeverityError, errors.SeverityOf(err), "default severity")
+
+	err = errors.WithSeverity(err, errors.SeverityWarning)
+	assert.Equal(t, errors.SeverityWarning, errors.SeverityOf(err))
+	assert.Equal(t, "warning", errors.SeverityOf(err).String())
+
+	err = errors.WithSeverity(errors.Wrap(err, "escalated"), errors.SeverityCritical)
+	assert.Equal(t, errors.SeverityCritical, errors.SeverityOf(err))
+}
+
+func TestSeverityConstructors(t *testing.T) {
+	warning := errors.Warnf("disk (%d%%) full", 80)
+	assert.Equal(t, "disk (80%) full", warning.Error())
+	assert.Equal(t, errors.SeverityWarning, errors.SeverityOf(warning))
+	assert.Equal(t, errors.SeverityCritical, errors.SeverityOf(errors.Criticalf("disk (%d%%) full", 99)))
+
+	var captured errors.Severity
+	errors.RegisterCapture("TestSeverityConstructors", func(err error, _ ...any) errors.CaptureID {
+		captured = errors.SeverityOf(err)
+		return "TestSeverityConstructors"
+	})
+	defer errors.UnregisterCapture("TestSeverityConstructors")
+
+	_ = errors.Alert(errors.Wrap(warning, "failed to write"))
+	assert.Equal(t, errors.SeverityWarning, captured, "severity should pass through to capture handlers")
+}
+
+func TestKind(t *testing.T) {
+	assert.Equal(t, errors.Kind(""), errors.KindOf(errors.New("TestKind")))
+	err := errors.WithKind(errors.New("TestKind"), errors.KindNotFound)
+	assert.Equal(t, errors.KindNotFound, errors.KindOf(errors.Wrap(err, "wrapped")))
+}
@@ -0,0 +1,302 @@
+// Package slackcapture provides a capture handler which posts a summary of each alert to Slack.
+//
+//	errors.RegisterCapture("slack", slackcapture.New(slackcapture.Config{
+//	  URL:     "https://slack.com/api/chat.postMessage",
+//	  Token:   os.Getenv("SLACK_TOKEN"),
+//	  Channel: "#alerts",
+//	}))
+//
+// Alerts with the same fingerprint (see errors.Fingerprint) are posted as replies in one thread, so that a
+// recurring error does not flood a channel. Posts to each channel are rate limited.
+package slackcapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/memsql/errors"
+)
+
+// DefaultTemplate renders a Summary as the text of a Slack message.
+var DefaultTemplate = template.Must(template.New("slack").Parse(
+	`:rotating_light: *{{.Message}}*
+{{- with .Code}}
+code: ` + "`{{.}}`" + `{{end}}
+{{- with .Owner}}
+owner: {{.}}{{end}}
+{{- with .Runbook}}
+runbook: {{.}}{{end}}
+{{- with .Tags}}
+tags:{{range .}} ` + "`{{.}}`" + `{{end}}{{end}}
+fingerprint: ` + "`{{.Fingerprint}}`" + `
+{{- range .Links}}
+• {{.}}{{end}}
+{{- with .Suppressed}}
+_{{.}} more alert(s) not posted to this channel, due to rate limit_{{end}}`))
+
+// ThreadTTL limits how long alerts with the same fingerprint are grouped into one thread. After this time, a
+// new thread is started.
+var ThreadTTL = 24 * time.Hour
+
+// Config determines where and how often alerts are posted.
+type Config struct {
+	// URL is either a Slack incoming webhook, or the chat.postMessage method of the Slack Web API. Only the Web
+	// API responds with the timestamp of a message, which is needed to group alerts into threads.
+	URL string
+
+	// Token authorizes requests to the Web API. Leave it empty when URL is an incoming webhook.
+	Token string
+
+	// Channel receives alerts, unless Route is specified.
+	Channel string
+
+	// Route optionally chooses the channel for each alert, i.e. based on errors.OwnerOf(err). When Route
+	// returns the empty string, Channel is used.
+	Route func(err error) string
+
+	// Template renders a Summary as message text. If nil, DefaultTemplate is used.
+	Template *template.Template
+
+	// Burst is how many alerts may be posted to a channel at once. After that, at most one alert per Interval
+	// is posted. Alerts exceeding the rate are counted, and the count is included in the next post. If
+	// Interval is zero, posts are not rate limited.
+	Burst    int
+	Interval time.Duration
+
+	// Client makes requests to Slack. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Summary is the data passed to the message template.
+type Summary struct {
+	Message     string
+	Code        errors.Code
+	Owner       string
+	Runbook     errors.Runbook
+	Tags        []string
+	Fingerprint string
+
+	// Links are the IDs of earlier captures of the error, by other providers.
+	Links []errors.CaptureID
+
+	// Suppressed counts alerts not posted to the channel, because of rate limit, since the previous post.
+	Suppressed int
+}
+
+// handler posts alerts to slack, keeping track of threads and rate limits per channel.
+type handler struct {
+	Config
+
+	mu      sync.Mutex
+	limit   map[string]*bucket // by channel
+	threads map[string]*thread // by channel and fingerprint
+}
+
+// bucket is a token bucket, limiting the rate of posts to a channel.
+type bucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// thread records the timestamp of the first message in a thread.
+type thread struct {
+	ts      string
+	started time.Time
+}
+
+// New produces a capture handler which posts to Slack.
+func New(config Config) errors.CaptureFunc {
+	if config.Template == nil {
+		config.Template = DefaultTemplate
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	h := &handler{
+		Config:  config,
+		limit:   map[string]*bucket{},
+		threads: map[string]*thread{},
+	}
+	return h.capture
+}
+
+func (h *handler) capture(exception error, _ ...any) errors.CaptureID {
+	channel := h.Channel
+	if h.Route != nil {
+		if routed := h.Route(exception); routed != "" {
+			channel = routed
+		}
+	}
+
+	summary := Summary{
+		Message:     exception.Error(),
+		Code:        errors.CodeOf(exception),
+		Owner:       errors.OwnerOf(exception),
+		Runbook:     errors.RunbookOf(exception),
+		Tags:        errors.TagsOf(exception),
+		Fingerprint: errors.Fingerprint(exception),
+		Links:       links(exception),
+	}
+	key := channel + " " + summary.Fingerprint
+
+	h.mu.Lock()
+	suppressed, ok := h.allow(channel, time.Now())
+	threadTS := ""
+	if t := h.threads[key]; t != nil && time.Since(t.started) < ThreadTTL {
+		threadTS = t.ts
+	}
+	h.mu.Unlock()
+
+	if !ok {
+		if threadTS != "" {
+			return id(channel, threadTS)
+		}
+		return ""
+	}
+	summary.Suppressed = suppressed
+
+	text := &strings.Builder{}
+	if err := h.Template.Execute(text, summary); err != nil {
+		log.Printf("slack capture failed to render template: %+v", err)
+		return ""
+	}
+
+	ts, err := h.post(channel, text.String(), threadTS)
+	if err != nil {
+		log.Printf("slack capture failed to post to channel (%q): %+v", channel, err)
+		return ""
+	}
+	if ts == "" {
+		// incoming webhooks do not respond with a timestamp
+		return id(channel, threadTS)
+	}
+
+	if threadTS == "" {
+		h.mu.Lock()
+		h.prune(time.Now())
+		h.threads[key] = &thread{ts: ts, started: time.Now()}
+		h.mu.Unlock()
+		threadTS = ts
+	}
+	return id(channel, threadTS)
+}
+
+// allow returns whether a post to the channel is within the rate limit, and how many posts have been suppressed
+// since the last allowed post. Caller must hold the lock.
+func (h *handler) allow(channel string, now time.Time) (int, bool) {
+	if h.Interval <= 0 {
+		return 0, true
+	}
+	burst := float64(h.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	b := h.limit[channel]
+	if b == nil {
+		b = &bucket{tokens: burst, last: now}
+		h.limit[channel] = b
+	}
+	b.tokens += float64(now.Sub(b.last)) / float64(h.Interval)
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return 0, false
+	}
+	b.tokens--
+	suppressed := b.suppressed
+	b.suppressed = 0
+	return suppressed, true
+}
+
+// prune forgets threads older than ThreadTTL. Caller must hold the lock.
+func (h *handler) prune(now time.Time) {
+	for key, t := range h.threads {
+		if now.Sub(t.started) >= ThreadTTL {
+			delete(h.threads, key)
+		}
+	}
+}
+
+// post sends a message, returning its timestamp if Slack provides one.
+func (h *handler) post(channel, text, threadTS string) (string, error) {
+	body, err := json.Marshal(struct {
+		Channel  string `json:"channel,omitempty"`
+		Text     string `json:"text"`
+		ThreadTS string `json:"thread_ts,omitempty"`
+	}{channel, text, threadTS})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode message")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	res, err := h.Client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to post message")
+	}
+	defer res.Body.Close()
+
+	response, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read response")
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", errors.Errorf("slack responded with status (%d) and body (%q)", res.StatusCode, response)
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if json.Unmarshal(response, &result) != nil {
+		return "", nil // not the Web API, i.e. an incoming webhook responds with plain text
+	}
+	if !result.OK {
+		return "", errors.Errorf("slack responded with error (%q)", result.Error)
+	}
+	return result.TS, nil
+}
+
+// links returns the IDs of earlier captures found in the error chain.
+func links(exception error) []errors.CaptureID {
+	var result []errors.CaptureID
+	errors.Walk(exception, func(ex error) bool {
+		if captured, ok := ex.(*errors.Captured); ok {
+			for _, id := range captured.IDs() {
+				if id != "" {
+					result = append(result, id)
+				}
+			}
+		}
+		return true
+	})
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// id identifies the message (or thread) in which an alert was posted.
+func id(channel, ts string) errors.CaptureID {
+	return errors.CaptureID(strings.Join(strings.Fields("slack "+channel+" "+ts), " "))
+}
@@ -0,0 +1,121 @@
+package slackcapture_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/slackcapture"
+	"github.com/stretchr/testify/assert"
+)
+
+type message struct {
+	Channel  string `json:"channel"`
+	Text     string `json:"text"`
+	ThreadTS string `json:"thread_ts"`
+}
+
+// slack emulates the chat.postMessage method of the Slack Web API.
+func slack(t *testing.T) (*httptest.Server, func() []message) {
+	var mu sync.Mutex
+	var posted []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var m message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Errorf("failed to decode message: %v", err)
+		}
+		mu.Lock()
+		posted = append(posted, m)
+		ts := fmt.Sprintf("%d.000100", len(posted))
+		mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"ok": true, "channel": %q, "ts": %q}`, m.Channel, ts)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []message {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]message(nil), posted...)
+	}
+}
+
+func TestThread(t *testing.T) {
+	server, posted := slack(t)
+	capture := slackcapture.New(slackcapture.Config{
+		URL:     server.URL,
+		Token:   "token",
+		Channel: "#alerts",
+	})
+
+	fail := func(i int) error {
+		err := errors.WithOwner(errors.WithCode(errors.Errorf("widget (%d) failed", i), "W-1"), "team-widget")
+		return errors.WithRunbook(errors.WithTags(err, "widgets"), "https://runbooks.example.com/widget")
+	}
+
+	first := capture(fail(1))
+	second := capture(fail(2))
+	other := capture(errors.New("something else"))
+
+	messages := posted()
+	if assert.Len(t, messages, 3) {
+		assert.Contains(t, messages[0].Text, "widget (1) failed")
+		assert.Contains(t, messages[0].Text, "W-1")
+		assert.Contains(t, messages[0].Text, "team-widget")
+		assert.Contains(t, messages[0].Text, "tags: `widgets`")
+		assert.Contains(t, messages[0].Text, "runbook: https://runbooks.example.com/widget")
+		assert.Equal(t, "", messages[0].ThreadTS)
+		assert.Equal(t, "1.000100", messages[1].ThreadTS, "same fingerprint should reply in thread")
+		assert.Equal(t, "", messages[2].ThreadTS, "different fingerprint should start a thread")
+	}
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func TestRateLimit(t *testing.T) {
+	server, posted := slack(t)
+	capture := slackcapture.New(slackcapture.Config{
+		URL:   server.URL,
+		Token: "token",
+		Route: func(err error) string {
+			return "#" + errors.OwnerOf(err)
+		},
+		Burst:    2,
+		Interval: time.Hour,
+	})
+
+	for i := 0; i < 5; i++ {
+		capture(errors.WithOwner(errors.Errorf("failure (%d)", i), "storage"))
+	}
+	capture(errors.WithOwner(errors.New("failure"), "network"))
+
+	messages := posted()
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, "#storage", messages[0].Channel)
+		assert.Equal(t, "#storage", messages[1].Channel)
+		assert.Equal(t, "#network", messages[2].Channel)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m message
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		text = m.Text
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	errors.RegisterCapture("TestWebhook", slackcapture.New(slackcapture.Config{URL: server.URL}))
+	defer errors.UnregisterCapture("TestWebhook")
+
+	captured := errors.Alert(errors.New("first"))
+	_ = errors.Alert(errors.Wrap(captured, "second"))
+	assert.True(t, strings.Contains(text, "slack"), "message should link to earlier capture: %s", text)
+}
@@ -0,0 +1,202 @@
+//go:build go1.21
+
+package errors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// SlogNames names the groups of attributes produced by SlogAttrs(). Changing a name changes the schema of
+// structured logs. An empty name omits a group; except Group, which when empty leaves attributes ungrouped.
+type SlogNames struct {
+	Group       string // contains all attributes describing an error
+	Annotations string // annotations and fields of the error
+	Stack       string // stack trace where the error originated
+	Capture     string // capture IDs, by provider
+}
+
+// SlogSchema determines the names used by SlogAttrs().
+var SlogSchema = SlogNames{
+	Group:       "error",
+	Annotations: "annotations",
+	Stack:       "stack",
+	Capture:     "capture",
+}
+
+// SlogAttrs describes an error as structured logging attributes, for use with "log/slog".
+//
+//	logger.LogAttrs(ctx, slog.LevelError, "request failed", errors.SlogAttrs(err)...)
+//
+// With the default SlogSchema, the attributes of the error are grouped under "error". They include the message,
+// code, kind, owner, runbook, hint, tags, field path, resource identifiers, severity and fingerprint of the error;
+// nested groups for annotations and capture IDs; and the stack trace where the error originated.
+func SlogAttrs(exception error) []slog.Attr {
+	if exception == nil {
+		return nil
+	}
+	attr := slogAttrs(exception)
+	if SlogSchema.Group == "" {
+		return attr
+	}
+	return []slog.Attr{slogGroup(SlogSchema.Group, attr)}
+}
+
+// LogValue implements slog.LogValuer, so that an error logged as an attribute is a group of the attributes
+// produced by SlogAttrs().
+//
+//	logger.Error("request failed", "error", err)
+func (e *Error) LogValue() slog.Value { return slog.GroupValue(slogAttrs(e)...) }
+
+// LogValue implements slog.LogValuer, see (*Error).LogValue().
+func (e *Captured) LogValue() slog.Value { return slog.GroupValue(slogAttrs(e)...) }
+
+// LogValue implements slog.LogValuer. Logs are not shown to users, so the value describes the error which was
+// redacted, as (*Error).LogValue() does, and adds the redacted message as "public".
+func (e Public) LogValue() slog.Value {
+	if e.error == nil {
+		return slog.GroupValue(slog.String("msg", e.msg))
+	}
+	return slog.GroupValue(append(slogAttrs(e.error), slog.String("public", e.Summary()))...)
+}
+
+// SlogCapture produces a capture handler which logs alerts to logger, with the attributes of SlogAttrs(). The
+// level of the record is derived from the severity of the error.
+//
+//	errors.RegisterCapture("slog", errors.SlogCapture(slog.Default()))
+func SlogCapture(logger *slog.Logger) CaptureFunc {
+	return func(exception error, _ ...any) CaptureID {
+		level := slog.LevelError
+		switch SeverityOf(exception) {
+		case SeverityWarning:
+			level = slog.LevelWarn
+		case SeverityInfo:
+			level = slog.LevelInfo
+		}
+		now := time.Now()
+		logger.LogAttrs(context.Background(), level, "alert", SlogAttrs(exception)...)
+		return CaptureID(now.Format("2006/01/02 15:04:05")) // as LogCapture, to help find the record in the log
+	}
+}
+
+// slogAttrs produces the attributes of SlogAttrs(), without grouping them.
+func slogAttrs(exception error) []slog.Attr {
+	Handled(exception)
+
+	attr := []slog.Attr{slog.String("msg", exception.Error())}
+	if code := CodeOf(exception); code != "" {
+		attr = append(attr, slog.String("code", string(code)))
+	}
+	if kind := KindOf(exception); kind != "" {
+		attr = append(attr, slog.String("kind", string(kind)))
+	}
+	if team := OwnerOf(exception); team != "" {
+		attr = append(attr, slog.String("owner", team))
+	}
+	if runbook := RunbookOf(exception); runbook != "" {
+		attr = append(attr, slog.String("runbook", string(runbook)))
+	}
+	if advice := Hint(exception); advice != "" {
+		attr = append(attr, slog.String("hint", advice))
+	}
+	if tag := TagsOf(exception); len(tag) > 0 {
+		attr = append(attr, slog.Any("tags", tag))
+	}
+	if field, ok := FieldPathOf(exception); ok {
+		attr = append(attr, slog.String("field", field.String()))
+	}
+	resources := ResourceTags(exception)
+	for _, key := range []string{ClusterTag, WorkspaceTag, DatabaseTag} {
+		if value, ok := resources[key]; ok {
+			attr = append(attr, slog.String(key, value))
+		}
+	}
+	if id := CorrelationIDOf(exception); id != "" {
+		attr = append(attr, slog.String("correlation_id", string(id)))
+	}
+	attr = append(attr,
+		slog.String("severity", SeverityOf(exception).String()),
+		slog.String("fingerprint", Fingerprint(exception)),
+	)
+
+	if SlogSchema.Annotations != "" {
+		if annotation := slogAnnotations(exception); len(annotation) > 0 {
+			attr = append(attr, slogGroup(SlogSchema.Annotations, annotation))
+		}
+	}
+
+	if SlogSchema.Capture != "" {
+		var capture []slog.Attr
+		for provider, id := range NewEvent(exception).ID {
+			capture = append(capture, slog.String(string(provider), string(id)))
+		}
+		if len(capture) > 0 {
+			sort.Slice(capture, func(i, j int) bool { return capture[i].Key < capture[j].Key })
+			attr = append(attr, slogGroup(SlogSchema.Capture, capture))
+		}
+	}
+
+	if SlogSchema.Stack != "" {
+		if stack := originStack(exception); len(stack) > 0 {
+			frames := make([]string, len(stack))
+			for i := range stack {
+				frames[i] = fmt.Sprintf("%+v", stack[i])
+			}
+			attr = append(attr, slog.Any(SlogSchema.Stack, frames))
+		}
+	}
+	return attr
+}
+
+// slogAnnotations describes the annotations of an error, other than those which have dedicated attributes. Each
+// is keyed by the name of its type, while named values (see AnnotateKV and Errorkv) are keyed by name.
+func slogAnnotations(exception error) []slog.Attr {
+	var attr []slog.Attr
+	seen := map[string]bool{}
+	add := func(key string, value any) {
+		if !seen[key] { // outermost has priority
+			seen[key] = true
+			attr = append(attr, slog.Any(key, value))
+		}
+	}
+
+	Walk(exception, func(ex error) bool {
+		switch e := ex.(type) {
+		case *annotated:
+			for _, v := range e.value {
+				switch v := v.(type) {
+				case Code, Kind, owner, Runbook, hint, Severity, tags, fieldSegment, fieldType, ClusterID, WorkspaceID,
+					DatabaseName, CorrelationID:
+					// these have dedicated attributes
+				case Fields:
+					for key, value := range v {
+						add(key, value)
+					}
+				case keyTag:
+					add(v.key, v.value)
+				default:
+					add(fmt.Sprintf("%T", v), v)
+				}
+			}
+		case *Error:
+			for _, a := range e.arg {
+				if fields, ok := a.(Fields); ok {
+					for key, value := range fields {
+						add(key, value)
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	sort.Slice(attr, func(i, j int) bool { return attr[i].Key < attr[j].Key })
+	return attr
+}
+
+func slogGroup(name string, attr []slog.Attr) slog.Attr {
+	return slog.Attr{Key: name, Value: slog.GroupValue(attr...)}
+}
@@ -0,0 +1,107 @@
+//go:build go1.21
+
+package errors_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogAttrs(t *testing.T) {
+	assert.Nil(t, errors.SlogAttrs(nil))
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	err := errors.Annotate(errors.WithCode(errors.Errorkv("user not found", "user_id", 42), "U-1"), userID(7))
+	logger.LogAttrs(context.Background(), slog.LevelError, "TestSlogAttrs", errors.SlogAttrs(err)...)
+
+	var record struct {
+		Error struct {
+			Msg         string         `json:"msg"`
+			Code        string         `json:"code"`
+			Severity    string         `json:"severity"`
+			Fingerprint string         `json:"fingerprint"`
+			Annotations map[string]any `json:"annotations"`
+			Stack       []string       `json:"stack"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "user not found (user_id=42)", record.Error.Msg)
+	assert.Equal(t, "U-1", record.Error.Code)
+	assert.Equal(t, "error", record.Error.Severity)
+	assert.Equal(t, errors.Fingerprint(err), record.Error.Fingerprint)
+	assert.Equal(t, map[string]any{"user_id": 42.0, "errors_test.userID": 7.0}, record.Error.Annotations)
+	assert.NotEmpty(t, record.Error.Stack)
+}
+
+func TestSlogSchema(t *testing.T) {
+	defer func(schema errors.SlogNames) { errors.SlogSchema = schema }(errors.SlogSchema)
+	errors.SlogSchema.Group = ""
+	errors.SlogSchema.Stack = ""
+
+	attr := errors.SlogAttrs(errors.New("TestSlogSchema"))
+	keys := make([]string, len(attr))
+	for i := range attr {
+		keys[i] = attr[i].Key
+	}
+	assert.Equal(t, []string{"msg", "severity", "fingerprint"}, keys)
+}
+
+func TestSlogLogValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	errors.RegisterCapture("TestSlogLogValue", func(error, ...any) errors.CaptureID { return "TestSlogLogValue 1" })
+	captured := errors.Alert(errors.WithCode(errors.Errorkv("user not found", "user_id", 42), "U-2"))
+	errors.UnregisterCapture("TestSlogLogValue")
+
+	for _, err := range []error{errors.Errorkv("user not found", "user_id", 42), captured, errors.Redact(captured)} {
+		buf.Reset()
+		logger.Error("TestSlogLogValue", "err", err)
+
+		var record struct {
+			Err struct {
+				Msg         string            `json:"msg"`
+				Public      string            `json:"public"`
+				Annotations map[string]any    `json:"annotations"`
+				Capture     map[string]string `json:"capture"`
+			} `json:"err"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record), buf.String())
+		assert.Contains(t, record.Err.Msg, "user not found (user_id=42)")
+		assert.Equal(t, map[string]any{"user_id": 42.0}, record.Err.Annotations)
+		if err != captured && errors.Is(err, captured) {
+			assert.Equal(t, "user not found", record.Err.Public)
+		}
+		if errors.Is(err, captured) {
+			assert.Equal(t, map[string]string{"TestSlogLogValue": "TestSlogLogValue 1"}, record.Err.Capture)
+		}
+	}
+}
+
+func TestSlogCapture(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errors.RegisterCapture("TestSlogCapture", errors.SlogCapture(slog.New(slog.NewJSONHandler(buf, nil))))
+	defer errors.UnregisterCapture("TestSlogCapture")
+
+	_ = errors.Alert(errors.Warnf("disk (%d) almost full", 3))
+
+	var record struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+		Error struct {
+			Msg string `json:"msg"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record), buf.String())
+	assert.Equal(t, "WARN", record.Level)
+	assert.Equal(t, "alert", record.Msg)
+	assert.Equal(t, "disk (3) almost full", record.Error.Msg)
+}
@@ -0,0 +1,217 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Spooled annotates an error passed to a capture handler by ReplaySpool(), describing when and why the handler
+// failed to capture it originally.
+type Spooled struct {
+	Time  time.Time // when the error was alerted
+	Cause string    // why the capture handler failed
+}
+
+// spoolRecord is one line of a spool file.
+type spoolRecord struct {
+	Provider CaptureProvider `json:"provider"`
+	Time     time.Time       `json:"time"`
+	Cause    string          `json:"cause,omitempty"`
+	Error    json.RawMessage `json:"error"`
+	Arg      []any           `json:"arg,omitempty"`
+}
+
+var spool struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	replay sync.Mutex // see ReplaySpool()
+}
+
+// SetCaptureSpool names a file to which alerts are appended, when a capture handler fails to capture them: when
+// it returns an error, panics, or does not finish in time (see CaptureTimeout). An outage of an error tracker,
+// or of the network, tends to coincide with the incidents whose alerts matter most; spooled alerts may be
+// submitted again once it is over, see ReplaySpool().
+//
+// The file is created if it does not exist. Pass "" to stop spooling. Alerts are spooled as encoded by Encode(),
+// so what survives is described by FromJSON(); and as passed to handlers, that is after SetBeforeCapture().
+func SetCaptureSpool(path string) error {
+	spool.mu.Lock()
+	defer spool.mu.Unlock()
+	if spool.file != nil {
+		_ = spool.file.Close()
+		spool.file, spool.path = nil, ""
+	}
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return Errorf("failed to open capture spool: %w", err)
+	}
+	spool.file, spool.path = file, path
+	return nil
+}
+
+// spoolFailed appends an alert to the spool for each provider which failed to capture it.
+func spoolFailed(exception error, arg []any, failed map[CaptureProvider]error) {
+	if len(failed) == 0 {
+		return
+	}
+	spool.mu.Lock()
+	defer spool.mu.Unlock()
+	if spool.file == nil {
+		return
+	}
+
+	encoded := Encode(exception)
+	spooledArg := make([]any, len(arg))
+	for i := range arg {
+		spooledArg[i] = jsonArg(arg[i])
+	}
+	now := time.Now()
+	for provider, err := range failed {
+		record := spoolRecord{Provider: provider, Time: now, Error: encoded, Arg: spooledArg}
+		if err != nil {
+			record.Cause = err.Error()
+		}
+		line, err := json.Marshal(record)
+		if err == nil {
+			_, err = spool.file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			log.Printf("failed to spool alert (%q): %v: %+v", provider, err, exception)
+		}
+	}
+}
+
+// ReplaySpool submits the alerts spooled for a provider (see SetCaptureSpool) to the handler registered as that
+// provider, in the order they were spooled. Alerts which the handler captures, or skips, are removed from the
+// spool; others are kept, to be replayed again later. The errors passed to the handler are decoded, and
+// annotated with Spooled.
+//
+// Alerts spooled because a handler did not finish in time may have been captured after all, in which case they
+// are captured twice.
+//
+// ReplaySpool returns how many alerts were captured. It stops early, keeping the alerts not yet replayed, when
+// ctx is done. A handler registered by RegisterCaptureV2() is passed ctx.
+func ReplaySpool(ctx context.Context, provider CaptureProvider) (int, error) {
+	captureMu.RLock()
+	handler, v2 := capture[provider], captureV2[provider]
+	captureMu.RUnlock()
+	if handler == nil {
+		return 0, Errorf("cannot replay spool, capture handler (%q) not registered", provider)
+	}
+
+	// alerts may be spooled while handlers are replaying others, but replays take turns
+	spool.replay.Lock()
+	defer spool.replay.Unlock()
+	data, err := readSpool(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var kept []byte
+	replayed := 0
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		var record spoolRecord
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			log.Printf("dropped corrupt line of capture spool: %v", err)
+			continue
+		}
+		if record.Provider != provider || ctx.Err() != nil {
+			kept = append(kept, line...)
+			continue
+		}
+		exception := Annotate(Decode(record.Error), Spooled{Time: record.Time, Cause: record.Cause})
+		result := replay(ctx, provider, handler, v2, exception, record.Arg)
+		switch {
+		case result.Failed():
+			log.Printf("failed to replay spooled alert (%q): %v", provider, result.Err)
+			kept = append(kept, line...)
+		case result.Status == CaptureOK:
+			replayed++
+		}
+	}
+
+	if _, err := readSpool(func(current []byte) []byte {
+		if !bytes.HasPrefix(current, data) {
+			return current // the spool was replaced meanwhile, see SetCaptureSpool()
+		}
+		return append(kept, current[len(data):]...)
+	}); err != nil {
+		return replayed, err
+	}
+	if ctx.Err() != nil {
+		return replayed, Errorf("replay of capture spool (%q) interrupted: %w", provider, context.Cause(ctx))
+	}
+	return replayed, nil
+}
+
+// replay invokes a handler for a spooled alert, recovering if it panics.
+func replay(ctx context.Context, provider CaptureProvider, handler CaptureFunc, v2 CaptureHandler,
+	exception error, arg []any) (result CaptureResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = CaptureResult{
+				Status: CapturePanicked,
+				Err:    Errorf("capture handler (%q) panicked: %v", provider, r),
+			}
+		}
+	}()
+	return invokeCapture(ctx, provider, handler, v2, exception, arg, nil)
+}
+
+// readSpool returns the contents of the spool. If rewrite is not nil, the contents are replaced by what it
+// returns, before any more alerts are spooled.
+func readSpool(rewrite func(data []byte) []byte) ([]byte, error) {
+	spool.mu.Lock()
+	defer spool.mu.Unlock()
+	if spool.file == nil {
+		return nil, Errorf("cannot replay spool, capture spool not set")
+	}
+	data, err := os.ReadFile(spool.path)
+	if err != nil {
+		return nil, Errorf("failed to read capture spool: %w", err)
+	}
+	if rewrite == nil {
+		return data, nil
+	}
+
+	// write a new file, and rename it, so that the spool is not lost if we are interrupted
+	temp, err := os.CreateTemp(filepath.Dir(spool.path), filepath.Base(spool.path)+".*")
+	if err != nil {
+		return nil, Errorf("failed to rewrite capture spool: %w", err)
+	}
+	defer os.Remove(temp.Name()) //nolint:errcheck // fails after the rename, as intended
+	_, err = temp.Write(rewrite(data))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, Errorf("failed to rewrite capture spool: %w", err)
+	}
+
+	// Windows does not rename over an open file, so close the spool first, and append later alerts to the new
+	// file, or to the old one if the rename failed
+	_ = spool.file.Close()
+	renameErr := os.Rename(temp.Name(), spool.path)
+	spool.file, err = os.OpenFile(spool.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		spool.file, spool.path = nil, ""
+		return nil, Errorf("failed to reopen capture spool: %w", err)
+	}
+	if renameErr != nil {
+		return nil, Errorf("failed to rewrite capture spool: %w", renameErr)
+	}
+	return data, nil
+}
@@ -0,0 +1,65 @@
+package errors_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.spool")
+	assert.NoError(t, errors.SetCaptureSpool(path))
+	defer errors.SetCaptureSpool("") //nolint:errcheck
+
+	online := false
+	var captured []error
+	errors.RegisterCaptureV2("TestSpool", func(_ context.Context, e errors.Event) (errors.CaptureID, error) {
+		if !online {
+			return "", errors.New("network unreachable")
+		}
+		captured = append(captured, e.Error)
+		return "TestSpool", nil
+	})
+	defer errors.UnregisterCapture("TestSpool")
+	errors.RegisterCapture("TestSpool ok", func(error, ...any) errors.CaptureID { return "ok" })
+	defer errors.UnregisterCapture("TestSpool ok")
+
+	_, failed := errors.AlertSync(context.Background(), errors.WithCode(errors.Errorf("disk (%s) full", "sda"), "DISK-1"))
+	assert.Len(t, failed, 1)
+	_, failed = errors.AlertSync(context.Background(), errors.New("second"))
+	assert.Len(t, failed, 1)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"), "only the failed provider should be spooled")
+	assert.Contains(t, string(data), "network unreachable")
+
+	// still failing, so nothing is replayed
+	n, err := errors.ReplaySpool(context.Background(), "TestSpool")
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	online = true
+	n, err = errors.ReplaySpool(context.Background(), "TestSpool")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	if assert.Len(t, captured, 2) {
+		assert.Equal(t, "disk (sda) full", captured[0].Error())
+		assert.Equal(t, errors.Code("DISK-1"), errors.CodeOf(captured[0]))
+		spooled, ok := errors.Annotation[errors.Spooled](captured[0])
+		assert.True(t, ok)
+		assert.Contains(t, spooled.Cause, "network unreachable")
+	}
+
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = errors.ReplaySpool(context.Background(), "TestSpool missing")
+	assert.Error(t, err)
+}
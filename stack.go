@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// maxFuncWidth limits the alignment of columns of a rendered stack, so that one long function name does not push
+// every file far to the right.
+const maxFuncWidth = 72
+
+// renderStack formats a stack trace, one frame per line, with the function and file in aligned columns. Files are
+// shown relative to their module, rather than by absolute paths which vary from one build machine to another.
+// Frames of the app are marked with "*", and frames of frameworks are omitted (see SetFrameClassifier).
+//
+//	--- "sql: no rows in result set"
+//	  * example.com/app/store.(*DB).Get  store/db.go:42
+//	    database/sql.(*DB).QueryContext  database/sql/sql.go:1726
+func renderStack(stack StackTrace) string {
+	if len(stack) == 0 {
+		return ""
+	}
+
+	name := make([]string, len(stack))
+	where := make([]string, len(stack))
+	class := make([]FrameClass, len(stack))
+	width := 0
+	for i, frame := range stack {
+		class[i] = ClassifyFrame(frame)
+		if class[i] == FrameFramework {
+			continue
+		}
+		name[i] = symbol.FuncName(frame)
+		if name[i] == "" {
+			name[i] = "unknown"
+		}
+		where[i] = symbol.Location(frame, name[i])
+		if len(name[i]) > width && len(name[i]) <= maxFuncWidth {
+			width = len(name[i])
+		}
+	}
+
+	b := &strings.Builder{}
+	omitted := 0
+	for i := range stack {
+		if class[i] == FrameFramework {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			fmt.Fprintf(b, "\n    (%d framework frames omitted)", omitted)
+			omitted = 0
+		}
+		b.WriteString("\n  ")
+		if class[i] == FrameApp {
+			b.WriteString("* ")
+		} else {
+			b.WriteString("  ")
+		}
+		b.WriteString(name[i])
+		for pad := len(name[i]); pad < width; pad++ {
+			b.WriteByte(' ')
+		}
+		b.WriteString("  ")
+		b.WriteString(where[i])
+	}
+	if omitted > 0 {
+		fmt.Fprintf(b, "\n    (%d framework frames omitted)", omitted)
+	}
+	return b.String()
+}
+
+// WalkStacks visits each stack trace in a tree of errors, along with the error which recorded it, outermost first;
+// so across joins, and not only the first stack found by As(). The innermost stack of a branch is where the error
+// originated, while outer stacks are where it was wrapped, handed off to another goroutine, or alerted. Capture
+// handlers may use it to report every stack of an error, rather than one. The walk continues while f returns true.
+//
+//	errors.WalkStacks(err, func(stack errors.StackTrace, owner error) bool {
+//	  event.Threads = append(event.Threads, thread(stack))
+//	  return true
+//	})
+func WalkStacks(exception error, f func(stack StackTrace, owner error) bool) {
+	Walk(exception, func(ex error) bool {
+		tracer, ok := ex.(StackTracer)
+		if !ok {
+			return true
+		}
+		if stack := tracer.StackTrace(); len(stack) > 0 {
+			return f(stack, ex)
+		}
+		return true
+	})
+}
@@ -0,0 +1,44 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func stackOrigin(name string) error { return errors.New(name) }
+
+func TestWalkStacks(t *testing.T) {
+	errors.RegisterCapture("TestWalkStacks", func(error, ...any) errors.CaptureID { return "TestWalkStacks" })
+	defer errors.UnregisterCapture("TestWalkStacks")
+
+	err := errors.Join(
+		errors.Wrap(stackOrigin("one"), "first"),
+		errors.NoStack(errors.String("no stack")),
+		stackOrigin("two"),
+	)
+	err = errors.Alert(err)
+
+	var owners []string
+	errors.WalkStacks(err, func(stack errors.StackTrace, owner error) bool {
+		assert.NotEmpty(t, stack)
+		owners = append(owners, owner.Error())
+		return true
+	})
+	// where the error was alerted, then where each branch of the join originated
+	assert.Equal(t, []string{err.Error(), "one", "two"}, owners)
+
+	count := 0
+	errors.WalkStacks(err, func(errors.StackTrace, error) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count, "walk should stop")
+
+	errors.WalkStacks(context.Canceled, func(errors.StackTrace, error) bool {
+		t.Error("no stack expected")
+		return true
+	})
+}
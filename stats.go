@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AlertStats counts alerts, and the outcomes of capturing them, since the program started. It describes the health
+// of alerting, i.e. a growing count of failures shows that a capture provider is unreachable.
+type AlertStats struct {
+	// Alerts counts calls to Alert() and its variants, including alerts which were suppressed.
+	Alerts int64
+
+	// Suppressed counts alerts which were not sent to capture handlers, because they were throttled (see Throttle),
+	// muted (see Mute), demoted (see SetKnownIssues), collapsed (see SetDedupWindow), dropped by a hook or by
+	// SetBeforeCapture, or dropped because capture was saturated or closed. Throttles with Shards count suppressed
+	// alerts in batches, so their count lags by up to a batch per shard.
+	Suppressed int64
+
+	// CaptureTimeouts counts capture handlers which did not finish in time.
+	CaptureTimeouts int64
+
+	// Failures counts capture handlers which failed, because they timed out, panicked, or returned an error, by
+	// provider.
+	Failures map[CaptureProvider]int64
+
+	// Providers counts the outcomes of capture handlers, by provider.
+	Providers map[CaptureProvider]ProviderStats
+
+	// Throttled counts alerts suppressed by throttles, by the scope of the throttle.
+	Throttled map[string]int64
+
+	// KnownIssues counts alerts demoted because they match a known issue (see SetKnownIssues), by the fingerprint
+	// of the issue, or for issues without a fingerprint, by "code " followed by the code of the issue.
+	KnownIssues map[string]int64
+}
+
+// ProviderStats counts the outcomes of a capture handler, see CaptureStatus.
+type ProviderStats struct {
+	Captured int64
+	Skipped  int64
+	Sampled  int64
+	Failed   int64
+	TimedOut int64
+	Panicked int64
+}
+
+// alertStats is read by Stats().
+var alertStats struct {
+	alerts     atomic.Int64
+	suppressed atomic.Int64
+	timeouts   atomic.Int64
+
+	mu        sync.Mutex
+	providers map[CaptureProvider]*ProviderStats
+	throttled map[string]int64
+	known     map[string]int64
+}
+
+// Stats returns counts of alerts, see AlertStats.
+func Stats() AlertStats {
+	stats := AlertStats{
+		Alerts:          alertStats.alerts.Load(),
+		Suppressed:      alertStats.suppressed.Load(),
+		CaptureTimeouts: alertStats.timeouts.Load(),
+		Failures:        map[CaptureProvider]int64{},
+		Providers:       map[CaptureProvider]ProviderStats{},
+		Throttled:       map[string]int64{},
+		KnownIssues:     map[string]int64{},
+	}
+
+	alertStats.mu.Lock()
+	defer alertStats.mu.Unlock()
+	for provider, p := range alertStats.providers {
+		stats.Providers[provider] = *p
+		if failed := p.TimedOut + p.Panicked + p.Failed; failed > 0 {
+			stats.Failures[provider] = failed
+		}
+	}
+	for scope, n := range alertStats.throttled {
+		stats.Throttled[scope] = n
+	}
+	for key, n := range alertStats.known {
+		stats.KnownIssues[key] = n
+	}
+	return stats
+}
+
+// countResults counts the outcomes of the capture handlers of an alert.
+func countResults(result map[CaptureProvider]CaptureResult) {
+	alertStats.mu.Lock()
+	defer alertStats.mu.Unlock()
+	if alertStats.providers == nil {
+		alertStats.providers = map[CaptureProvider]*ProviderStats{}
+	}
+	for provider, r := range result {
+		p := alertStats.providers[provider]
+		if p == nil {
+			p = &ProviderStats{}
+			alertStats.providers[provider] = p
+		}
+		switch r.Status {
+		case CaptureOK:
+			p.Captured++
+		case CaptureSkipped:
+			p.Skipped++
+		case CaptureSampled:
+			p.Sampled++
+		case CaptureFailed:
+			p.Failed++
+		case CaptureTimedOut:
+			p.TimedOut++
+			alertStats.timeouts.Add(1)
+		case CapturePanicked:
+			p.Panicked++
+		}
+	}
+}
+
+// countThrottled counts alerts suppressed by a throttle.
+func countThrottled(scope string, n int64) {
+	alertStats.alerts.Add(n)
+	alertStats.suppressed.Add(n)
+
+	alertStats.mu.Lock()
+	defer alertStats.mu.Unlock()
+	if alertStats.throttled == nil {
+		alertStats.throttled = map[string]int64{}
+	}
+	alertStats.throttled[scope] += n
+}
+
+// countKnown counts an alert demoted because it matches a known issue.
+func countKnown(key string) {
+	alertStats.suppressed.Add(1)
+
+	alertStats.mu.Lock()
+	defer alertStats.mu.Unlock()
+	if alertStats.known == nil {
+		alertStats.known = map[string]int64{}
+	}
+	alertStats.known[key]++
+}
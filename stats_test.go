@@ -0,0 +1,53 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	errors.RegisterCapture("TestStats panics", func(error, ...any) errors.CaptureID { panic("TestStats") })
+	defer errors.UnregisterCapture("TestStats panics")
+	errors.RegisterCapture("TestStats blocks", func(error, ...any) errors.CaptureID {
+		time.Sleep(100 * time.Millisecond)
+		return "TestStats 1"
+	})
+	defer errors.UnregisterCapture("TestStats blocks")
+
+	before := errors.Stats()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _ = errors.AlertSync(ctx, errors.New("TestStats failing"))
+
+	muted := errors.New("TestStats muted")
+	errors.Mute(errors.Fingerprint(muted), time.Now().Add(time.Minute), "TestStats")
+	defer errors.Unmute(errors.Fingerprint(muted))
+	_ = errors.Alert(muted)
+
+	after := errors.Stats()
+	assert.Equal(t, int64(2), after.Alerts-before.Alerts)
+	assert.Equal(t, int64(1), after.Suppressed-before.Suppressed)
+	assert.Equal(t, int64(1), after.CaptureTimeouts-before.CaptureTimeouts)
+	assert.Equal(t, int64(1), after.Failures["TestStats panics"]-before.Failures["TestStats panics"])
+	assert.Equal(t, int64(1), after.Failures["TestStats blocks"]-before.Failures["TestStats blocks"])
+}
+
+func TestStatsThrottled(t *testing.T) {
+	errors.RegisterCapture("TestStatsThrottled", func(error, ...any) errors.CaptureID { return "TestStatsThrottled 1" })
+	defer errors.UnregisterCapture("TestStatsThrottled")
+
+	before := errors.Stats()
+	throttle := errors.Throttle{Scope: "TestStatsThrottled", Threshold: 1}
+	_ = throttle.Alertf("first")
+	_ = throttle.Alertf("second")
+
+	after := errors.Stats()
+	assert.Equal(t, int64(2), after.Alerts-before.Alerts)
+	assert.Equal(t, int64(1), after.Throttled["TestStatsThrottled"]-before.Throttled["TestStatsThrottled"])
+	assert.Equal(t, int64(1), after.Providers["TestStatsThrottled"].Captured-before.Providers["TestStatsThrottled"].Captured)
+}
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"log"
+	"sync"
+)
+
+// httpStatus is the annotation type recording the HTTP status of an error.
+type httpStatus int
+
+var (
+	statusMu sync.RWMutex
+
+	// sentinelStatus maps sentinels to HTTP statuses, see RegisterHTTPStatus().
+	sentinelStatus = map[String]int{}
+
+	// kindStatus maps kinds to HTTP statuses, see RegisterKindHTTPStatus().
+	kindStatus = map[Kind]int{
+		KindInvalid:         400, // Bad Request
+		KindUnauthenticated: 401, // Unauthorized
+		KindPermission:      403, // Forbidden
+		KindNotFound:        404, // Not Found
+		KindAlreadyExists:   409, // Conflict
+		KindInternal:        500, // Internal Server Error
+		KindUnavailable:     503, // Service Unavailable
+		KindTimeout:         504, // Gateway Timeout
+	}
+)
+
+// WithHTTPStatus returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and has the HTTP status passed in, i.e. http.StatusTooManyRequests.
+func WithHTTPStatus(exception error, status int) error {
+	return Annotate(exception, httpStatus(status))
+}
+
+// RegisterHTTPStatus maps a sentinel to an HTTP status, typically in an init() function of the package that
+// defines the sentinel. Errors which wrap the sentinel have the status (see HTTPStatus()).
+//
+//	const ErrQuotaExceeded = errors.String("quota exceeded")
+//
+//	func init() {
+//	  errors.RegisterHTTPStatus(ErrQuotaExceeded, http.StatusTooManyRequests)
+//	}
+//
+// RegisterHTTPStatus panics when a sentinel is registered more than once.
+func RegisterHTTPStatus(s String, status int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if existing, ok := sentinelStatus[s]; ok {
+		log.Panicf("sentinel (%q) already has HTTP status (%d)", s, existing)
+	}
+	sentinelStatus[s] = status
+}
+
+// RegisterKindHTTPStatus replaces the HTTP status of errors of a kind. By default, each kind defined by this
+// package has the conventional status, i.e. KindNotFound has http.StatusNotFound.
+func RegisterKindHTTPStatus(kind Kind, status int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	kindStatus[kind] = status
+}
+
+// HTTPStatus returns the HTTP status to respond with, given an error. So handlers need not switch on errors:
+//
+//	w.WriteHeader(errors.HTTPStatus(err))
+//
+// The status is, in order of priority: the status passed to WithHTTPStatus(); the status of a sentinel the error
+// wraps (see RegisterHTTPStatus); the status of the kind of the error (see KindOf and RegisterKindHTTPStatus); or
+// http.StatusInternalServerError. HTTPStatus returns http.StatusOK when the error is nil.
+func HTTPStatus(exception error) int {
+	if exception == nil {
+		return 200 // OK
+	}
+	if status, ok := Annotation[httpStatus](exception); ok {
+		return int(status)
+	}
+
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	var (
+		status int
+		found  bool
+	)
+	Walk(exception, func(ex error) bool {
+		switch e := ex.(type) {
+		case String:
+			status, found = sentinelStatus[e]
+		case errorString:
+			status, found = sentinelStatus[e.s]
+		}
+		return !found
+	})
+	if found {
+		return status
+	}
+	if status, ok := kindStatus[KindOf(exception)]; ok {
+		return status
+	}
+	return 500 // Internal Server Error
+}
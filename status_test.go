@@ -0,0 +1,31 @@
+package errors_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const errStatusQuota errors.String = "TestHTTPStatus quota exceeded"
+
+func init() {
+	errors.RegisterHTTPStatus(errStatusQuota, http.StatusTooManyRequests)
+}
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusOK, errors.HTTPStatus(nil))
+	assert.Equal(t, http.StatusInternalServerError, errors.HTTPStatus(errors.New("TestHTTPStatus")))
+	assert.Equal(t, http.StatusNotFound, errors.HTTPStatus(errors.WithKind(errors.New("TestHTTPStatus"), errors.KindNotFound)))
+
+	quota := errors.WithKind(errors.Wrap(errStatusQuota, "cannot create table"), errors.KindUnavailable)
+	assert.Equal(t, http.StatusTooManyRequests, errors.HTTPStatus(quota), "sentinel has priority over kind")
+	assert.Equal(t, http.StatusTooManyRequests, errors.HTTPStatus(errStatusQuota.Errorf("tenant (%s) over limit", "acme")))
+	assert.Equal(t, http.StatusPaymentRequired, errors.HTTPStatus(errors.WithHTTPStatus(quota, http.StatusPaymentRequired)))
+	assert.Panics(t, func() { errors.RegisterHTTPStatus(errStatusQuota, http.StatusTooManyRequests) })
+
+	errors.RegisterKindHTTPStatus(errors.KindUnavailable, http.StatusBadGateway)
+	defer errors.RegisterKindHTTPStatus(errors.KindUnavailable, http.StatusServiceUnavailable)
+	assert.Equal(t, http.StatusBadGateway, errors.HTTPStatus(errors.WithKind(errors.New("TestHTTPStatus"), errors.KindUnavailable)))
+}
@@ -0,0 +1,204 @@
+// Package stream writes captured errors as a stream of events, for a sidecar to consume and forward. Application
+// binaries then need no SDK of an error tracking vendor; only the sidecar does.
+//
+//	errors.RegisterCapture("stream", stream.NewWriter(os.Stdout).Capture)
+//
+// or, to a sidecar listening on a Unix socket:
+//
+//	errors.RegisterCapture("stream", stream.Dial("unix", "/run/errors.sock").Capture)
+//
+// Each event is a frame: its length, as a 4 byte big-endian integer, followed by that many bytes of JSON (see
+// Frame). The error itself is encoded by errors.Encode, so the sidecar can decode it with errors.Decode, and use
+// Is, KindOf and so on much as the application would have. ReadFrame reads the frames of a stream.
+package stream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/memsql/errors"
+)
+
+// MaxFrame is the longest frame written or read. Longer events are not written, as they are most likely the
+// result of a bug.
+const MaxFrame = 1 << 20
+
+// Frame is the JSON encoding of an event.
+type Frame struct {
+	Seq         uint64                                      `json:"seq"`
+	Time        time.Time                                   `json:"time"`
+	Release     string                                      `json:"release,omitempty"`
+	Message     string                                      `json:"message"`
+	Template    string                                      `json:"template,omitempty"`
+	Fingerprint string                                      `json:"fingerprint"`
+	Severity    string                                      `json:"severity"`
+	Kind        errors.Kind                                 `json:"kind,omitempty"`
+	Code        errors.Code                                 `json:"code,omitempty"`
+	Owner       string                                      `json:"owner,omitempty"`
+	Runbook     errors.Runbook                              `json:"runbook,omitempty"`
+	Tags        []string                                    `json:"tags,omitempty"`
+	Arg         []string                                    `json:"arg,omitempty"`
+	ID          map[errors.CaptureProvider]errors.CaptureID `json:"id,omitempty"`
+
+	// Error is the error, as encoded by errors.Encode.
+	Error json.RawMessage `json:"error"`
+
+	// Stack is the error formatted with %+v.
+	Stack string `json:"stack,omitempty"`
+}
+
+// NewFrame encodes an event.
+func NewFrame(event errors.Event) Frame {
+	arg := make([]string, len(event.Arg))
+	for i := range event.Arg {
+		arg[i] = fmt.Sprint(event.Arg[i]) // not all args can be encoded as JSON
+	}
+	return Frame{
+		Time:        event.Time.UTC(),
+		Release:     event.Release,
+		Message:     event.Message,
+		Template:    event.Template,
+		Fingerprint: event.Fingerprint,
+		Severity:    event.Severity.String(),
+		Kind:        event.Kind,
+		Code:        event.Code,
+		Owner:       event.Owner,
+		Runbook:     event.Runbook,
+		Tags:        event.Tags,
+		Arg:         arg,
+		ID:          event.ID,
+		Error:       errors.Encode(event.Error),
+		Stack:       fmt.Sprintf("%+v", event.Error),
+	}
+}
+
+// Writer writes frames to a stream. It is safe for concurrent use.
+type Writer struct {
+	mu   sync.Mutex
+	seq  uint64
+	w    io.Writer
+	dial func() (io.Writer, error)
+}
+
+// NewWriter produces a Writer which writes to w, i.e. os.Stdout.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Dial produces a Writer which writes to a connection, i.e. to a Unix socket. The connection is made when the first
+// event is written, and made again after it fails, so the sidecar may start after, or restart independently of,
+// the application.
+func Dial(network, address string) *Writer {
+	return &Writer{dial: func() (io.Writer, error) {
+		return net.DialTimeout(network, address, time.Second)
+	}}
+}
+
+// Capture is a capture handler, which writes an event to the stream. The capture ID is the sequence number of the
+// frame, so the forwarded event can be found.
+func (s *Writer) Capture(exception error, arg ...any) errors.CaptureID {
+	frame := NewFrame(errors.NewEvent(exception, arg...))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	frame.Seq = s.seq
+	if err := s.write(frame); err != nil {
+		log.Printf("stream capture failed: %+v", err)
+		return ""
+	}
+	return errors.CaptureID("stream " + strconv.FormatUint(frame.Seq, 10))
+}
+
+// WriteFrame writes a frame to the stream.
+func (s *Writer) WriteFrame(frame Frame) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(frame)
+}
+
+func (s *Writer) write(frame Frame) error {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode frame")
+	}
+	if len(data) > MaxFrame {
+		return errors.Errorf("frame (%d) longer than allowed (%d)", len(data), MaxFrame)
+	}
+
+	if s.w == nil {
+		if s.w, err = s.dial(); err != nil {
+			s.w = nil
+			return errors.Wrap(err, "failed to connect to stream")
+		}
+	}
+
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	if _, err := s.w.Write(buf); err != nil {
+		if s.dial != nil {
+			if closer, ok := s.w.(io.Closer); ok {
+				_ = closer.Close()
+			}
+			s.w = nil // connect again for the next frame
+		}
+		return errors.Wrap(err, "failed to write frame")
+	}
+	return nil
+}
+
+// Close closes the connection of a Writer made by Dial. Writers made by NewWriter do not close their writer.
+func (s *Writer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dial == nil || s.w == nil {
+		return nil
+	}
+	err := s.w.(io.Closer).Close()
+	s.w = nil
+	return errors.Wrap(err, "failed to close stream")
+}
+
+// Reader reads frames from a stream.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader produces a Reader which reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// ReadFrame reads the next frame. It returns io.EOF at the end of the stream.
+func (r *Reader) ReadFrame() (Frame, error) {
+	var frame Frame
+	var size [4]byte
+	if _, err := io.ReadFull(r.r, size[:]); err != nil {
+		if err == io.EOF {
+			return frame, io.EOF
+		}
+		return frame, errors.Wrap(err, "failed to read frame length")
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MaxFrame {
+		return frame, errors.Errorf("frame (%d) longer than allowed (%d)", n, MaxFrame)
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return frame, errors.Wrap(err, "failed to read frame")
+	}
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return frame, errors.Wrap(err, "failed to decode frame")
+	}
+	return frame, nil
+}
@@ -0,0 +1,76 @@
+package stream_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/memsql/errors/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+const errWidget = errors.String("widget failed")
+
+func TestStream(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errors.RegisterCapture("TestStream", stream.NewWriter(buf).Capture)
+	defer errors.UnregisterCapture("TestStream")
+
+	captured := errors.Alert(errors.WithKind(errors.Wrapf(errWidget, "widget (%d)", 42), errors.KindUnavailable))
+	_ = errors.Alertf("second")
+
+	var c *errors.Captured
+	if assert.True(t, errors.As(captured, &c)) {
+		assert.Equal(t, errors.CaptureID("stream 1"), c.ID("TestStream"))
+	}
+
+	r := stream.NewReader(buf)
+	frame, err := r.ReadFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), frame.Seq)
+	assert.Equal(t, "widget (42): widget failed", frame.Message)
+	assert.Equal(t, errors.KindUnavailable, frame.Kind)
+	assert.Equal(t, errors.Fingerprint(captured), frame.Fingerprint)
+	assert.Contains(t, frame.Stack, "stream_test.go")
+	decoded := errors.Decode(frame.Error)
+	assert.True(t, errors.Is(decoded, errWidget))
+	assert.Equal(t, errors.KindUnavailable, errors.KindOf(decoded))
+
+	frame, err = r.ReadFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), frame.Seq)
+	assert.Equal(t, "second", frame.Message)
+
+	_, err = r.ReadFrame()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.sock")
+	w := stream.Dial("unix", path)
+	defer w.Close()
+
+	// the sidecar is not yet listening
+	assert.Error(t, w.WriteFrame(stream.Frame{Seq: 1}))
+
+	listener, err := net.Listen("unix", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	assert.NoError(t, w.WriteFrame(stream.Frame{Seq: 2, Message: "after listen"}))
+	conn, err := listener.Accept()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	frame, err := stream.NewReader(conn).ReadFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), frame.Seq)
+	assert.Equal(t, "after listen", frame.Message)
+}
@@ -0,0 +1,104 @@
+package errors
+
+// tags is the annotation type recording labels of an error.
+type tags []string
+
+// WithTags returns nil when the exception passed in is nil; otherwise, it returns an error which wraps exception
+// and is labeled with the tags passed in. Tags are simple labels, i.e. "billing", which do not require defining a
+// type, as Annotate() does. Policies may match errors by tag, and capture handlers may forward tags to providers.
+//
+//	return errors.WithTags(err, "billing", "reconciliation")
+func WithTags(exception error, tag ...string) error {
+	if len(tag) == 0 {
+		return Safe(exception)
+	}
+	return Annotate(exception, tags(tag))
+}
+
+// HasTag returns whether an error, or any error it wraps, is labeled with a tag.
+func HasTag(exception error, tag string) bool {
+	for _, t := range TagsOf(exception) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagsOf returns the tags of an error, including tags of the errors it wraps. Each tag appears once, in the order
+// found, outermost first.
+func TagsOf(exception error) []string {
+	var (
+		result []string
+		seen   map[string]bool
+	)
+	Walk(exception, func(ex error) bool {
+		a, ok := ex.(*annotated)
+		if !ok {
+			return true
+		}
+		for _, v := range a.value {
+			t, ok := v.(tags)
+			if !ok {
+				continue
+			}
+			for _, tag := range t {
+				if seen == nil {
+					seen = map[string]bool{}
+				}
+				if !seen[tag] {
+					seen[tag] = true
+					result = append(result, tag)
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// keyTag is the annotation type recording a tag with a value, see WithTagValue().
+type keyTag struct {
+	key, value string
+}
+
+// WithTagValue returns nil when the exception passed in is nil; otherwise, it returns an error which wraps
+// exception and is tagged with key and value. Unlike labels (see WithTags) and named values (see Fields), which
+// capture handlers pass to providers as extra details, tags with values are passed as tags, which providers such as
+// sentry index, so that errors may be searched by them. Keep their values few, and short. It is not named Tag(),
+// which, beside WithTags(), would suggest a label rather than a tag with a value.
+//
+//	return errors.WithTagValue(err, "tenant", tenant.ID)
+func WithTagValue(exception error, key, value string) error {
+	return Annotate(exception, keyTag{key: key, value: value})
+}
+
+// TagValuesOf returns the tags with values of an error, including those of the errors it wraps, or nil if there
+// are none. Where a key is tagged more than once, the outermost value is returned.
+func TagValuesOf(exception error) map[string]string {
+	var result map[string]string
+	Walk(exception, func(ex error) bool {
+		if a, ok := ex.(*annotated); ok {
+			result = addKeyTags(result, a.value)
+		}
+		return true
+	})
+	return result
+}
+
+// addKeyTags adds the tags with values found among the values of an annotation, unless their keys are present.
+func addKeyTags(result map[string]string, value []any) map[string]string {
+	for _, v := range value {
+		t, ok := v.(keyTag)
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		if _, ok := result[t.key]; !ok {
+			result[t.key] = t.value
+		}
+	}
+	return result
+}
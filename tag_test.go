@@ -0,0 +1,53 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/memsql/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	assert.Nil(t, errors.WithTags(nil, "billing"))
+
+	err := errors.WithTags(errors.New("TestTags"), "billing", "reconciliation")
+	err = errors.WithTags(errors.Wrap(err, "outer"), "nightly", "billing")
+
+	assert.Equal(t, []string{"nightly", "billing", "reconciliation"}, errors.TagsOf(err))
+	assert.True(t, errors.HasTag(err, "reconciliation"))
+	assert.False(t, errors.HasTag(err, "shipping"))
+	assert.Empty(t, errors.TagsOf(errors.New("TestTags")))
+	assert.Equal(t, []string{"nightly", "billing", "reconciliation"}, errors.NewEvent(err).Tags)
+}
+
+func TestTagValues(t *testing.T) {
+	assert.Nil(t, errors.WithTagValue(nil, "tenant", "acme"))
+	assert.Nil(t, errors.TagValuesOf(errors.New("TestTagValues")))
+
+	err := errors.WithTagValue(errors.WithTagValue(errors.New("TestTagValues"), "tenant", "acme"), "region", "us-east-1")
+	err = errors.WithTagValue(errors.Wrap(err, "outer"), "tenant", "globex")
+
+	want := map[string]string{"tenant": "globex", "region": "us-east-1"}
+	assert.Equal(t, want, errors.TagValuesOf(err), "outermost value should win")
+	assert.Equal(t, want, errors.NewEvent(err).TagValues)
+	assert.Empty(t, errors.NewEvent(err).Annotations, "tags should be passed apart from named values")
+	assert.Equal(t, want, errors.TagValuesOf(errors.Decode(errors.Encode(err))), "tags should survive encoding")
+}
+
+func TestPolicyTag(t *testing.T) {
+	errors.RegisterPolicy(errors.Policy{Name: "TestPolicyTag", Tag: "experimental", Flag: "experimental alerts"})
+	defer errors.UnregisterPolicy("TestPolicyTag")
+
+	captured := 0
+	errors.RegisterCapture("TestPolicyTag", func(error, ...any) errors.CaptureID {
+		captured++
+		return "TestPolicyTag"
+	})
+	defer errors.UnregisterCapture("TestPolicyTag")
+
+	_ = errors.Alert(errors.WithTags(errors.New("TestPolicyTag"), "experimental"))
+	assert.Equal(t, 0, captured, "flag is off")
+	_ = errors.Alert(errors.WithTags(errors.New("TestPolicyTag"), "stable"))
+	assert.Equal(t, 1, captured)
+}
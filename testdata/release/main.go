@@ -0,0 +1,12 @@
+// Command release prints errors.Release, so that TestRelease can check how it is set at build time.
+package main
+
+import (
+	"fmt"
+
+	"github.com/memsql/errors"
+)
+
+func main() {
+	fmt.Print(errors.Release)
+}
@@ -3,7 +3,14 @@ package errors
 import (
 	"fmt"
 	"log"
+	"math/rand"
+	"runtime"
+	"sync"
 	"sync/atomic"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/memsql/errors/internal/symbol"
 )
 
 // A Throttle will alert, until threshold is reached. After threshold is reached, errors are no longer
@@ -16,12 +23,32 @@ import (
 // The throttle is not persisted across restarts, so errors will be captured for each replica of a service and
 // each time a replica is restarted. So, if you specify a Threshold of one, you might see two captures if the
 // service has two replicas, or four after those replicas have restarted, etc.
+//
+// Under very high contention, i.e. millions of suppressed errors per second, the counter of a throttle becomes a
+// bottleneck. Set Shards to spread counting of suppressed errors across several counters. A sharded throttle
+// alerts exactly as an unsharded one does, but it logs suppressed errors in batches, rather than each one.
 type Throttle struct {
 	Scope     string
 	Threshold int32
 	count     int32
+
+	// Shards, if more than one, is the number of counters of suppressed errors.
+	Shards int
+
+	shardOnce sync.Once
+	shards    []throttleShard
+}
+
+// throttleShard is one of several counters of suppressed errors. It is padded, so that each counter is in its own
+// cache line.
+type throttleShard struct {
+	n int32
+	_ [60]byte
 }
 
+// throttleFlush is how many suppressed errors a shard counts, before adding them to the throttle's count.
+const throttleFlush = 64
+
 func (t *Throttle) Alertf(format string, a ...interface{}) error {
 	// use fmt.Errorf here, to avoid a stack that is redundant with stack produced in ForceAlert
 	return t.Alert(fmt.Errorf(format, a...))
@@ -37,11 +64,16 @@ func (t *Throttle) Alert(exception error) error {
 		return nil
 	}
 
+	if t.Shards > 1 && atomic.LoadInt32(&t.count) >= t.Threshold {
+		return t.suppress(exception)
+	}
+
 	count := atomic.AddInt32(&t.count, 1)
 	if count <= t.Threshold {
 		return Alert(exception)
 	}
 
+	countThrottled(t.Scope, 1)
 	log.Printf("throttled an alert (%q) because threshold (%d) is reached (%d): %+v", t.Scope, t.Threshold, count, exception)
 
 	// reset every once in a while so that capture is not totally silent despite thousands of errors.
@@ -53,3 +85,60 @@ func (t *Throttle) Alert(exception error) error {
 	// return original exception, not alerted
 	return exception
 }
+
+// suppress counts an error which exceeds the threshold, using one of the shards.
+func (t *Throttle) suppress(exception error) error {
+	t.shardOnce.Do(func() { t.shards = make([]throttleShard, t.Shards) })
+
+	shard := &t.shards[shardIndex(len(t.shards))]
+	n := atomic.AddInt32(&shard.n, 1)
+	if n < throttleFlush {
+		return exception
+	}
+	atomic.AddInt32(&shard.n, -n)
+
+	countThrottled(t.Scope, int64(n))
+	count := atomic.AddInt32(&t.count, n)
+	log.Printf("throttled (%d) alerts (%q) because threshold (%d) is reached (%d), latest: %+v", n, t.Scope, t.Threshold, count, exception)
+
+	// reset every once in a while, as the unsharded throttle does
+	if count >= 1_000 && count-n < 1_000 {
+		Alert(fmt.Errorf("throttled excessive errors (%d in scope %q)", count, t.Scope)) //nolint:errcheck
+		atomic.StoreInt32(&t.count, 0)
+	}
+	return exception
+}
+
+// shardIndex chooses a shard at random, so that concurrent callers mostly count on different shards. The random
+// numbers of the math/rand functions are generated per processor, without contention, unless rand.Seed() is called.
+func shardIndex(shards int) int {
+	return int(rand.Uint32() % uint32(shards))
+}
+
+// alertedOnce holds the program counters of the callers of AlertOnce() which have alerted.
+var alertedOnce sync.Map // uintptr → struct{}
+
+// AlertOnce alerts the first time it is called from a line of code, and only logs the errors passed to it from
+// that line afterwards, with the file and line, i.e. "store/db.go:42". Unlike a Throttle, it never alerts again.
+//
+//	for _, row := range rows {
+//	  if err := migrate(row); err != nil {
+//	    errors.AlertOnce(err)
+//	  }
+//	}
+func AlertOnce(err error) error {
+	if isNil(err, "AlertOnce") {
+		return nil
+	}
+
+	pc, _, _, _ := runtime.Caller(1)
+	if _, alerted := alertedOnce.LoadOrStore(pc, struct{}{}); !alerted {
+		return Alert(err)
+	}
+
+	frame := pkgerrors.Frame(pc)
+	scope := symbol.Location(frame, symbol.FuncName(frame))
+	countThrottled(scope, 1)
+	log.Printf("throttled an alert (%q) because it was alerted once: %+v", scope, err)
+	return err
+}
@@ -1,7 +1,12 @@
 package errors_test
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"math/rand"
+	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,3 +41,67 @@ func TestThrottle(t *testing.T) {
 		t.Errorf("throttle did capture (%T): %+v", exception, exception)
 	}
 }
+
+func TestThrottleShards(t *testing.T) {
+	errors.RegisterCapture("throttle_test", errors.LogCapture)
+	defer errors.UnregisterCapture("throttle_test")
+
+	throttle := errors.Throttle{Scope: "TestThrottleShards", Threshold: 3, Shards: 8}
+
+	var captured *errors.Captured
+	for i := int32(1); i <= throttle.Threshold; i++ {
+		if !errors.As(throttle.Alertf("number %d, should not be throttled", i), &captured) {
+			t.Errorf("throttle did not capture number %d", i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if errors.As(throttle.Alertf("should be throttled"), &captured) {
+					t.Error("throttle did capture")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkThrottle(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	exception := errors.String("BenchmarkThrottle")
+
+	for _, shards := range []int{0, 16} {
+		b.Run(fmt.Sprintf("shards %d", shards), func(b *testing.B) {
+			throttle := errors.Throttle{Scope: "BenchmarkThrottle", Shards: shards}
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = throttle.Alert(exception)
+				}
+			})
+		})
+	}
+}
+
+func TestAlertOnce(t *testing.T) {
+	var alerted []string
+	errors.RegisterCapture("TestAlertOnce", func(err error, _ ...any) errors.CaptureID {
+		alerted = append(alerted, err.Error())
+		return "TestAlertOnce"
+	})
+	defer errors.UnregisterCapture("TestAlertOnce")
+	defer errors.ForgetAlertOnce()
+
+	// a throttle alerts again after a thousand errors, AlertOnce does not
+	for i := 0; i < 1_001; i++ {
+		errors.AlertOnce(errors.Errorf("first line (%d)", i))  //nolint:errcheck
+		errors.AlertOnce(errors.Errorf("second line (%d)", i)) //nolint:errcheck
+	}
+	if len(alerted) != 2 || alerted[0] != "first line (0)" || alerted[1] != "second line (0)" {
+		t.Errorf("expected only the first error of each line to be alerted, got %q", alerted)
+	}
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TraceRecord describes the construction of an error, or an alert, as recorded in programs built with
+// "-tags errorsdebug".
+type TraceRecord struct {
+	Time time.Time
+
+	// Op is the function called, i.e. "Wrap".
+	Op string
+
+	// Site is the function, file and line which called it.
+	Site string
+
+	// Shape is the chain of errors produced, i.e.
+	//	*errors.Error("failed to load: %w") > *errors.withStack > *errors.errorString
+	Shape string
+
+	Message string
+}
+
+func (r TraceRecord) String() string {
+	return fmt.Sprintf("%s %s at %s: %q\n\t%s", r.Time.Format(time.RFC3339Nano), r.Op, r.Site, r.Message, r.Shape)
+}
+
+// Trace returns records of recent calls to Errorf(), Wrap(), Wrapf() and Alert(), oldest first. This is intended
+// for tracking down where an error message was decorated, in code too large to search by hand.
+//
+// Records are kept only in programs built with "-tags errorsdebug", as recording is expensive. Otherwise, Trace
+// returns nil. Only the most recent records are kept, see TraceSize.
+func Trace() []TraceRecord {
+	return traceRecords()
+}
+
+// DumpTrace writes the records returned by Trace() to w.
+func DumpTrace(w io.Writer) error {
+	records := Trace()
+	if !traceEnabled {
+		_, err := io.WriteString(w, "errors: tracing is enabled by building with -tags errorsdebug\n")
+		return Wrap(err, "failed to dump trace")
+	}
+	for _, record := range records {
+		if _, err := fmt.Fprintln(w, record); err != nil {
+			return Wrap(err, "failed to dump trace")
+		}
+	}
+	return nil
+}
+
+// shapeOf describes the chain of errors wrapped by an error, see TraceRecord.Shape.
+func shapeOf(exception error) string {
+	name := fmt.Sprintf("%T", exception)
+	if e, ok := exception.(*Error); ok && e.format != "" {
+		name += fmt.Sprintf("(%q)", e.format)
+	}
+	switch x := exception.(type) {
+	case interface{ Unwrap() error }:
+		if inner := x.Unwrap(); inner != nil {
+			return name + " > " + shapeOf(inner)
+		}
+	case interface{ Unwrap() []error }:
+		shapes := make([]string, 0, len(x.Unwrap()))
+		for _, inner := range x.Unwrap() {
+			if inner != nil {
+				shapes = append(shapes, shapeOf(inner))
+			}
+		}
+		return name + " > [" + strings.Join(shapes, " | ") + "]"
+	}
+	return name
+}
@@ -0,0 +1,13 @@
+//go:build !errorsdebug
+
+package errors
+
+// traceEnabled is true in programs built with "-tags errorsdebug".
+const traceEnabled = false
+
+// TraceSize is how many records Trace() keeps. It is zero unless built with "-tags errorsdebug".
+const TraceSize = 0
+
+func trace(string, error, bool) {}
+
+func traceRecords() []TraceRecord { return nil }
@@ -0,0 +1,77 @@
+//go:build errorsdebug
+
+package errors
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceEnabled is true in programs built with "-tags errorsdebug".
+const traceEnabled = true
+
+// TraceSize is how many records Trace() keeps.
+const TraceSize = 4096
+
+// traceRing is a ring buffer of the most recent records.
+var traceRing struct {
+	mu      sync.Mutex
+	records [TraceSize]TraceRecord
+	next    int
+	full    bool
+}
+
+// thisPackage prefixes the names of functions of this package, but not of its tests or subpackages.
+const thisPackage = "github.com/memsql/errors."
+
+// trace records a call to op, which produced exception. If direct, the call is recorded only when op was called
+// from outside this package, so that i.e. Errorf() called by Wrap() is not recorded twice. Otherwise, the site is
+// the first caller outside this package.
+func trace(op string, exception error, direct bool) {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc) // skip runtime.Callers, trace and op
+	frames := runtime.CallersFrames(pc[:n])
+
+	var site string
+	for first := true; ; first = false {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, thisPackage) {
+			site = fmt.Sprintf("%s %s:%d", frame.Function, filepath.Base(frame.File), frame.Line)
+			break
+		}
+		if (direct && first) || !more {
+			return
+		}
+	}
+
+	record := TraceRecord{
+		Time:    time.Now(),
+		Op:      op,
+		Site:    site,
+		Shape:   shapeOf(exception),
+		Message: exception.Error(),
+	}
+
+	traceRing.mu.Lock()
+	defer traceRing.mu.Unlock()
+	traceRing.records[traceRing.next] = record
+	traceRing.next = (traceRing.next + 1) % TraceSize
+	if traceRing.next == 0 {
+		traceRing.full = true
+	}
+}
+
+func traceRecords() []TraceRecord {
+	traceRing.mu.Lock()
+	defer traceRing.mu.Unlock()
+	if !traceRing.full {
+		return append([]TraceRecord(nil), traceRing.records[:traceRing.next]...)
+	}
+	records := make([]TraceRecord, 0, TraceSize)
+	records = append(records, traceRing.records[traceRing.next:]...)
+	return append(records, traceRing.records[:traceRing.next]...)
+}
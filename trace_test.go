@@ -0,0 +1,41 @@
+//go:build errorsdebug
+
+package errors_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	errors.RegisterCapture("TestTrace", func(error, ...any) errors.CaptureID { return "" })
+	defer errors.UnregisterCapture("TestTrace")
+
+	exception := errors.Wrap(errors.Errorf("widget (%d) failed", 42), "TestTrace")
+	_ = errors.Alert(exception)
+
+	var records []errors.TraceRecord
+	for _, record := range errors.Trace() {
+		if strings.Contains(record.Site, "TestTrace") {
+			records = append(records, record)
+		}
+	}
+	if assert.GreaterOrEqual(t, len(records), 3) {
+		records = records[len(records)-3:] // earlier records are of earlier runs of the test
+		assert.Equal(t, "Errorf", records[0].Op)
+		assert.Equal(t, "Wrap", records[1].Op, "Errorf called by Wrap is not recorded")
+		assert.Equal(t, "Alert", records[2].Op)
+		for _, record := range records {
+			assert.Contains(t, record.Site, "TestTrace trace_test.go:")
+		}
+		assert.Equal(t, `*errors.Error("TestTrace: %w") > *fmt.wrapError > *errors.Error("widget (%d) failed") > *errors.withStack > *errors.errorString`, records[1].Shape)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, errors.DumpTrace(buf))
+	assert.Contains(t, buf.String(), "Wrap at github.com/memsql/errors_test.TestTrace")
+}
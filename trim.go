@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// elision marks where TrimForLog() removed part of a message.
+const elision = " ... "
+
+// TrimForLog renders an error for a log which limits the size of each entry. The rendering is at most maxBytes
+// long. Rather than cutting the message off at the limit, which often loses the root cause, TrimForLog keeps the
+// beginning of the message, which is the outermost context, and the message of the innermost error, which is the
+// root cause. The middle of the message is replaced with " ... ".
+//
+// The function where the error originated is appended, i.e. "[at main.connect db.go:42]", when it fits.
+//
+//	log.Print(errors.TrimForLog(err, 1024))
+func TrimForLog(exception error, maxBytes int) string {
+	if exception == nil || maxBytes <= 0 {
+		return ""
+	}
+
+	message := exception.Error()
+	var where string
+	if frame, ok := originFrame(exception); ok {
+		where = fmt.Sprintf(" [at %s %s:%d]", symbol.FuncName(frame), frame, frame)
+	}
+	if len(message)+len(where) <= maxBytes {
+		return message + where
+	}
+
+	// keep the innermost message, and as much of the outermost as fits
+	inner := innermostMessage(exception)
+	if !strings.HasSuffix(message, inner) || len(inner) == len(message) {
+		inner = ""
+	}
+	for _, suffix := range []string{where, ""} {
+		head := maxBytes - len(suffix) - len(elision) - len(inner)
+		if head > 0 {
+			return strings.TrimRight(truncate(message, head), " ") + elision + inner + suffix
+		}
+	}
+
+	// not even the innermost message fits
+	if maxBytes <= len(elision) {
+		return truncate(message, maxBytes)
+	}
+	return strings.TrimRight(truncate(message, maxBytes-len(elision)), " ") + elision
+}
+
+// innermostMessage returns the message of the last error, in the tree of errors, which wraps no other error.
+func innermostMessage(exception error) string {
+	var message string
+	Walk(exception, func(ex error) bool {
+		if isLeaf(ex) {
+			message = ex.Error()
+		}
+		return true
+	})
+	return message
+}
+
+// truncate returns at most n bytes of s, without splitting a multi-byte character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,37 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func trimFailure() error {
+	return errors.New("connection refused")
+}
+
+func TestTrimForLog(t *testing.T) {
+	err := trimFailure()
+	for i := 0; i < 20; i++ {
+		err = errors.Wrapf(err, "layer (%d) failed", i)
+	}
+
+	full := errors.TrimForLog(err, 10_000)
+	assert.True(t, strings.HasPrefix(full, err.Error()+" [at github.com/memsql/errors_test.trimFailure trim_test.go:"), full)
+
+	trimmed := errors.TrimForLog(err, 200)
+	assert.LessOrEqual(t, len(trimmed), 200)
+	assert.True(t, strings.HasPrefix(trimmed, "layer (19) failed: layer (18) failed"), trimmed)
+	assert.Contains(t, trimmed, " ... connection refused [at github.com/memsql/errors_test.trimFailure")
+
+	// origin is dropped before the innermost message
+	trimmed = errors.TrimForLog(err, 40)
+	assert.Equal(t, "layer (19) failed ... connection refused", trimmed)
+
+	assert.Equal(t, "layer (19) fail ... ", errors.TrimForLog(err, 20), "innermost message does not fit")
+	assert.Equal(t, "", errors.TrimForLog(nil, 100))
+
+	assert.Equal(t, "héllo ... ", errors.TrimForLog(errors.New("héllo wörld, a long message"), 12), "multi-byte characters are not split")
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"io/fs"
+	"strings"
+	"syscall"
+
+	"github.com/memsql/errors/internal/redact"
+	"github.com/memsql/errors/internal/symbol"
+)
+
+// upgradeKinds classify errors of the standard library, see Upgrade().
+var upgradeKinds = []struct {
+	target error
+	kind   Kind
+}{
+	{fs.ErrNotExist, KindNotFound},
+	{fs.ErrExist, KindAlreadyExists},
+	{fs.ErrPermission, KindPermission},
+	{syscall.ECONNREFUSED, KindUnavailable},
+	{syscall.ECONNRESET, KindUnavailable},
+}
+
+// Upgrade adapts an error produced by fmt.Errorf() or the standard library, so that code which has not yet been
+// converted to this package benefits from capture and grouping. The error returned wraps exception, with:
+//
+//   - a stack, where Upgrade is called, if exception has none;
+//   - a fingerprint derived from its messages, if no error in the tree has a template: numbers, quoted strings and
+//     identifiers are replaced by verbs, and the text of each wrapping error becomes a template ending in %w, so
+//     that errors formatted alike are grouped alike (see Fingerprint);
+//   - a kind, if it has none and wraps a well-known error, i.e. fs.ErrNotExist is KindNotFound, and timeouts are
+//     KindTimeout.
+//
+// Errors which already have all of these are returned as they are, so Upgrade may be called where legacy errors
+// enter code which alerts:
+//
+//	if err := legacy.Sync(); err != nil {
+//	  return errors.Upgrade(err)
+//	}
+func Upgrade(exception error) error {
+	if isNil(exception, "Upgrade") {
+		return nil
+	}
+
+	upgraded := WithStack(exception)
+	var value []any
+	if _, ok := FingerprintParts(upgraded); !ok && templateOf(upgraded) == "" {
+		parts := messageTemplates(upgraded)
+		for _, frame := range fingerprintFrames(upgraded) {
+			parts = append(parts, symbol.FuncName(frame))
+		}
+		value = append(value, fingerprint(parts))
+	}
+	if KindOf(upgraded) == "" {
+		if kind := upgradeKind(upgraded); kind != "" {
+			value = append(value, kind)
+		}
+	}
+	return Annotate(upgraded, value...)
+}
+
+// messageTemplates guesses the templates which formatted the messages of an error tree, see Upgrade().
+func messageTemplates(exception error) []string {
+	var parts []string
+	Walk(exception, func(ex error) bool {
+		msg := ex.Error()
+		switch e := ex.(type) {
+		case interface{ Unwrap() []error }:
+			return true // its message joins those of the errors it wraps
+		case interface{ Unwrap() error }:
+			if inner := e.Unwrap(); inner != nil {
+				if prefix, ok := strings.CutSuffix(msg, inner.Error()); ok {
+					if prefix != "" {
+						parts = append(parts, redact.Literals(redactedKey(exception, prefix))+"%w")
+					}
+					return true
+				}
+			}
+		}
+		parts = append(parts, redact.Literals(redactedKey(exception, msg)))
+		return true
+	})
+	return parts
+}
+
+// upgradeKind returns the kind of a well-known error wrapped by exception, or the empty string.
+func upgradeKind(exception error) Kind {
+	if IsTimeout(exception) {
+		return KindTimeout
+	}
+	for _, k := range upgradeKinds {
+		if Is(exception, k.target) {
+			return k.kind
+		}
+	}
+	return ""
+}
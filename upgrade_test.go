@@ -0,0 +1,42 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/memsql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func legacyLookup(user string, attempt int) error {
+	return fmt.Errorf("lookup of user %q failed after %d attempts: %w", user, attempt,
+		fmt.Errorf("connection 0x%x (to %s) reset", attempt*16, user+".example.com"))
+}
+
+func TestUpgrade(t *testing.T) {
+	assert.NoError(t, errors.Upgrade(nil))
+
+	var upgraded []error
+	for i, user := range []string{"alice", "bob"} {
+		upgraded = append(upgraded, errors.Upgrade(legacyLookup(user, i+1)))
+	}
+	assert.Equal(t, `lookup of user "alice" failed after 1 attempts: connection 0x10 (to alice.example.com) reset`,
+		upgraded[0].Error(), "message should not change")
+	assert.Equal(t, errors.Fingerprint(upgraded[0]), errors.Fingerprint(upgraded[1]))
+	parts, _ := errors.FingerprintParts(upgraded[0])
+	assert.Equal(t, []string{"lookup of user %q failed after %v attempts: %w", "connection %x reset"}, parts[:2])
+	assert.Contains(t, parts, "github.com/memsql/errors_test.TestUpgrade", "fingerprint should include the origin")
+	assert.Contains(t, fmt.Sprintf("%+v", upgraded[0]), "TestUpgrade", "stack should be added")
+
+	other := errors.Upgrade(fmt.Errorf("lookup of group %q failed: %w", "admins", os.ErrNotExist))
+	assert.NotEqual(t, errors.Fingerprint(upgraded[0]), errors.Fingerprint(other))
+	assert.Equal(t, errors.KindNotFound, errors.KindOf(other))
+	assert.Equal(t, errors.KindTimeout, errors.KindOf(errors.Upgrade(fmt.Errorf("query: %w", context.DeadlineExceeded))))
+	assert.Equal(t, errors.Fingerprint(other), errors.Fingerprint(errors.Upgrade(other)), "upgrade should be idempotent")
+
+	// errors of this package are grouped as they were
+	native := errors.Errorf("lookup of user (%s) failed", "alice")
+	assert.Equal(t, errors.Fingerprint(native), errors.Fingerprint(errors.Upgrade(native)))
+}